@@ -0,0 +1,73 @@
+package chain
+
+import (
+	"context"
+	"fmt"
+	"math/big"
+	"slices"
+
+	"github.com/ethereum/go-ethereum/ethclient"
+
+	"uttc-hack-back-onchain/model"
+)
+
+// ChainGateway はネットワーク全体の状態（ブロック・手数料）の取得を担当
+type ChainGateway interface {
+	// GetRecentBaseFees は直近count個のブロックのベースフィーを古い順に返す
+	GetRecentBaseFees(ctx context.Context, count int) ([]model.BlockFee, error)
+
+	// SuggestGasTipCap はノードが推奨する優先手数料（チップ）を返す
+	SuggestGasTipCap(ctx context.Context) (*big.Int, error)
+
+	// SuggestGasPrice はノードが推奨するガス価格を返す
+	SuggestGasPrice(ctx context.Context) (*big.Int, error)
+}
+
+// EthChainGateway は ethclient を使った ChainGateway の実装
+type EthChainGateway struct {
+	client *ethclient.Client
+}
+
+// NewEthChainGateway は新しいチェーンゲートウェイを作成
+func NewEthChainGateway(client *ethclient.Client) *EthChainGateway {
+	return &EthChainGateway{client: client}
+}
+
+// GetRecentBaseFees は最新ブロックから遡ってヘッダーを取得し、ベースフィーを古い順に返す
+func (g *EthChainGateway) GetRecentBaseFees(ctx context.Context, count int) ([]model.BlockFee, error) {
+	latest, err := g.client.HeaderByNumber(ctx, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get latest block: %w", err)
+	}
+
+	fees := make([]model.BlockFee, 0, count)
+	header := latest
+	for {
+		baseFee := header.BaseFee
+		if baseFee == nil {
+			// EIP-1559以前のブロック
+			baseFee = big.NewInt(0)
+		}
+		fees = append(fees, model.BlockFee{BlockNumber: header.Number.Uint64(), BaseFee: baseFee})
+
+		if len(fees) == count || header.Number.Sign() == 0 {
+			break
+		}
+		header, err = g.client.HeaderByNumber(ctx, new(big.Int).Sub(header.Number, big.NewInt(1)))
+		if err != nil {
+			return nil, fmt.Errorf("failed to get block header: %w", err)
+		}
+	}
+
+	// 新しい順に取得したので古い順に並べ替える
+	slices.Reverse(fees)
+	return fees, nil
+}
+
+func (g *EthChainGateway) SuggestGasTipCap(ctx context.Context) (*big.Int, error) {
+	return g.client.SuggestGasTipCap(ctx)
+}
+
+func (g *EthChainGateway) SuggestGasPrice(ctx context.Context) (*big.Int, error) {
+	return g.client.SuggestGasPrice(ctx)
+}
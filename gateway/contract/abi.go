@@ -64,6 +64,16 @@ const FrimaMarketplaceABI = `[
     "name": "ItemUpdated",
     "type": "event"
   },
+  {
+    "anonymous": false,
+    "inputs": [
+      {"indexed": true, "internalType": "uint256", "name": "itemId", "type": "uint256"},
+      {"indexed": false, "internalType": "uint256", "name": "oldPrice", "type": "uint256"},
+      {"indexed": false, "internalType": "uint256", "name": "newPrice", "type": "uint256"}
+    ],
+    "name": "PriceReduced",
+    "type": "event"
+  },
   {
     "anonymous": false,
     "inputs": [
@@ -0,0 +1,55 @@
+package contract
+
+import (
+	"context"
+	"log"
+	"sync"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+)
+
+const (
+	// blockTimeCacheSize はブロック時刻をキャッシュする最大ブロック数（超えたら全件破棄する）
+	blockTimeCacheSize = 1024
+	// blockTimeLookupTimeout はブロックヘッダー取得のタイムアウト
+	blockTimeLookupTimeout = 5 * time.Second
+)
+
+// blockTimeCache はブロックのタイムスタンプをキャッシュする
+// 同じブロックの複数のログで eth_getBlockByHash を繰り返さないようにするため。
+// リオルグで同じブロック番号のブロックが置き換わるため、キーはブロックハッシュにする
+type blockTimeCache struct {
+	mu    sync.Mutex
+	times map[common.Hash]uint64
+}
+
+func newBlockTimeCache() *blockTimeCache {
+	return &blockTimeCache{times: make(map[common.Hash]uint64)}
+}
+
+// blockTime はブロックのタイムスタンプ（UNIX秒）を返す。取得できない場合は0
+func (g *FrimaContractGateway) blockTime(blockHash common.Hash) uint64 {
+	g.blockTimes.mu.Lock()
+	if t, ok := g.blockTimes.times[blockHash]; ok {
+		g.blockTimes.mu.Unlock()
+		return t
+	}
+	g.blockTimes.mu.Unlock()
+
+	ctx, cancel := context.WithTimeout(context.Background(), blockTimeLookupTimeout)
+	defer cancel()
+	header, err := g.client.HeaderByHash(ctx, blockHash)
+	if err != nil {
+		log.Printf("WARNING: Failed to get block header %s for block time: %v", blockHash.Hex(), err)
+		return 0
+	}
+
+	g.blockTimes.mu.Lock()
+	defer g.blockTimes.mu.Unlock()
+	if len(g.blockTimes.times) >= blockTimeCacheSize {
+		g.blockTimes.times = make(map[common.Hash]uint64)
+	}
+	g.blockTimes.times[blockHash] = header.Time
+	return header.Time
+}
@@ -0,0 +1,69 @@
+package contract
+
+import (
+	"context"
+	"expvar"
+
+	"uttc-hack-back-onchain/logger"
+	"uttc-hack-back-onchain/model"
+)
+
+// DefaultEventBufferSize はイベントチャネルのバッファ数のデフォルト
+const DefaultEventBufferSize = 100
+
+// EventBufferConfig はゲートウェイが返すイベントチャネルの設定
+//
+// バッファが満杯の場合（useCase 側の通知がバックエンドの遅延で詰まっている場合）、
+// デフォルトでは空くまで送信側が待つ。その間は WebSocket・ポーリングのログの読み取りも止まるが、
+// イベントは失われない。DropOldest を有効にすると、リアルタイム受信では待たずに最も古い未処理の
+// イベントを破棄して新しいイベントを入れる（破棄したイベントは再起動時の過去スキャンか
+// /api/v1/contract/replay で再通知する必要がある）。過去スキャンのチャネルは常に待つ
+type EventBufferConfig struct {
+	Size       int
+	DropOldest bool
+}
+
+// イベントチャネルのバックプレッシャーのメトリクス（/debug/vars で公開）
+var (
+	eventChanBlockedMetric = expvar.NewInt("event_chan_blocked") // バッファが満杯で送信を待った回数
+	eventChanDroppedMetric = expvar.NewInt("event_chan_dropped") // DropOldest で破棄したイベント数
+)
+
+func (c EventBufferConfig) normalize() EventBufferConfig {
+	if c.Size <= 0 {
+		c.Size = DefaultEventBufferSize
+	}
+	return c
+}
+
+// sendEvent はイベントをチャネルに送る。ctx がキャンセルされた場合は false を返す
+// dropOldest が true でバッファが満杯の場合は、最も古いイベントを破棄してから送る
+func (g *FrimaContractGateway) sendEvent(ctx context.Context, eventChan chan *model.ContractEvent, event *model.ContractEvent, dropOldest bool) bool {
+	select {
+	case eventChan <- event:
+		return true
+	default:
+	}
+
+	if dropOldest {
+		for {
+			select {
+			case <-ctx.Done():
+				return false
+			case eventChan <- event:
+				return true
+			case dropped := <-eventChan:
+				eventChanDroppedMetric.Add(1)
+				logger.ForEvent(dropped).Warn("Event buffer full, dropping oldest event", "buffer_size", cap(eventChan))
+			}
+		}
+	}
+
+	eventChanBlockedMetric.Add(1)
+	select {
+	case eventChan <- event:
+		return true
+	case <-ctx.Done():
+		return false
+	}
+}
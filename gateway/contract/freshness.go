@@ -0,0 +1,87 @@
+package contract
+
+import (
+	"context"
+	"expvar"
+	"log"
+	"time"
+
+	"github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/core/types"
+)
+
+const (
+	// DefaultStallThreshold は新しいブロックを観測しないまま購読が停止したとみなすまでの時間
+	// Sepolia のブロック間隔（12秒）の10倍程度
+	DefaultStallThreshold = 2 * time.Minute
+	// stallCheckInterval は停止検知の確認間隔
+	stallCheckInterval = 15 * time.Second
+)
+
+// eventSourceStallsMetric は停止を検知して接続し直した回数（/debug/vars で公開）
+var eventSourceStallsMetric = expvar.NewInt("event_source_stalls")
+
+// LastBlockSeen は最後に観測したブロック番号と観測時刻を返す（未観測ならゼロ値）
+func (g *FrimaContractGateway) LastBlockSeen() (uint64, time.Time) {
+	g.freshMu.Lock()
+	defer g.freshMu.Unlock()
+	return g.lastBlockSeen, g.lastBlockSeenAt
+}
+
+// markBlockSeen は block がこれまでより新しい場合に観測時刻を更新する
+func (g *FrimaContractGateway) markBlockSeen(block uint64) {
+	g.freshMu.Lock()
+	defer g.freshMu.Unlock()
+
+	if block > g.lastBlockSeen || g.lastBlockSeenAt.IsZero() {
+		g.lastBlockSeen = block
+		g.lastBlockSeenAt = time.Now()
+	}
+}
+
+// resetStallClock は接続し直した直後に停止検知の起点を現在時刻に戻す
+// 接続直後に再び停止と判定して接続し直し続けないようにするため
+func (g *FrimaContractGateway) resetStallClock() {
+	g.freshMu.Lock()
+	defer g.freshMu.Unlock()
+	g.lastBlockSeenAt = time.Now()
+}
+
+// stalled は stallThreshold を超えて新しいブロックを観測していない場合に true を返す
+func (g *FrimaContractGateway) stalled() (bool, time.Duration) {
+	g.freshMu.Lock()
+	defer g.freshMu.Unlock()
+
+	if g.lastBlockSeenAt.IsZero() {
+		return false, 0
+	}
+	since := time.Since(g.lastBlockSeenAt)
+	return since > g.stallThreshold, since
+}
+
+// reportStall は停止の検知をログとメトリクスに残す
+func (g *FrimaContractGateway) reportStall(since time.Duration) {
+	block, _ := g.LastBlockSeen()
+	log.Printf("ERROR: No new block observed for %v (last block: %d, source: %s), forcing reconnection", since.Round(time.Second), block, g.EventSourceMode())
+	eventSourceStallsMetric.Add(1)
+}
+
+// subscribeHeads は現在の WebSocket 接続で新しいブロックを購読する（停止検知用）
+// ログの購読はイベントが無い間は何も届かず停止と区別できないため、ブロックの到着で進行を確認する。
+// 購読できない場合は nil を返し、その接続では停止検知を行わない
+func (g *FrimaContractGateway) subscribeHeads(ctx context.Context) (chan *types.Header, ethereum.Subscription) {
+	g.wsMu.Lock()
+	client := g.wsClient
+	g.wsMu.Unlock()
+	if client == nil {
+		return nil, nil
+	}
+
+	heads := make(chan *types.Header)
+	sub, err := client.SubscribeNewHead(ctx, heads)
+	if err != nil {
+		log.Printf("WARNING: Failed to subscribe to new heads, stall detection disabled for this connection: %v", err)
+		return nil, nil
+	}
+	return heads, sub
+}
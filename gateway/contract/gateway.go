@@ -1,61 +1,232 @@
 package contract
 
 import (
+	"cmp"
 	"context"
 	"errors"
 	"fmt"
 	"log"
 	"math/big"
+	"slices"
 	"strings"
+	"sync"
 	"time"
 
 	"github.com/ethereum/go-ethereum"
 	"github.com/ethereum/go-ethereum/accounts/abi"
 	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
 	"github.com/ethereum/go-ethereum/core/types"
 	"github.com/ethereum/go-ethereum/ethclient"
 
+	"uttc-hack-back-onchain/internal/txwait"
+	"uttc-hack-back-onchain/logger"
 	"uttc-hack-back-onchain/model"
 )
 
+// ErrCallTimeout はノードへの読み取り呼び出し（eth_call など）がタイムアウトした
+var ErrCallTimeout = errors.New("node call timed out")
+
+// DefaultCallTimeout はノードへの読み取り呼び出し1回あたりのタイムアウトのデフォルト
+const DefaultCallTimeout = 8 * time.Second
+
+// ErrContractUnavailable はコントラクトの呼び出しがリバートした、またはアドレスにコードが無い（未デプロイ）
+var ErrContractUnavailable = errors.New("contract call reverted or contract is not deployed")
+
+// ErrTxDropped はトランザクションがマイニングされないまま破棄・置き換えられた
+var ErrTxDropped = txwait.ErrTxDropped
+
 // ContractGateway はスマートコントラクトとの連携を担当
 type ContractGateway interface {
 	// GetItem はコントラクトから商品情報を取得
 	GetItem(ctx context.Context, itemId uint64) (*model.ContractItem, error)
 
+	// GetItemCount はコントラクトに登録された商品数（itemIdCounter）を取得
+	// 呼び出しがリバートした・コントラクトが未デプロイの場合は ErrContractUnavailable を返す
+	GetItemCount(ctx context.Context) (uint64, error)
+
 	// SubscribeEvents はコントラクトイベントを購読
+	// WebSocket の切断はゲートウェイ内で再購読するため、返すチャネルは ctx のキャンセル（またはポーリングの停止）まで閉じない
 	SubscribeEvents(ctx context.Context) (<-chan *model.ContractEvent, error)
 
 	// ScanPastEvents は過去のブロックからイベントをスキャン
-	ScanPastEvents(ctx context.Context, fromBlock uint64, toBlock *uint64) (<-chan *model.ContractEvent, error)
+	// eventTypes が空でない場合、その種類のイベントのみをノード側で絞り込んで取得する
+	ScanPastEvents(ctx context.Context, fromBlock uint64, toBlock *uint64, eventTypes []model.EventType) (<-chan *model.ContractEvent, error)
+
+	// GetItemHistory は指定商品に関するすべてのイベントをブロック順に取得
+	GetItemHistory(ctx context.Context, itemId uint64, fromBlock uint64) ([]*model.ContractEvent, error)
+
+	// GetListedItemIds は seller が出品した商品の itemId を ItemListed イベントから昇順で取得する（プライマリのみ）
+	GetListedItemIds(ctx context.Context, seller common.Address, fromBlock uint64) ([]uint64, error)
 
 	// GetContractAddress はコントラクトアドレスを返す
 	GetContractAddress() string
 
+	// GetNFTContractAddress はマーケットプレイスの nftContract のアドレスを返す（初回取得後はキャッシュ）
+	// マーケットプレイスが未デプロイの場合は ErrContractUnavailable を返す
+	GetNFTContractAddress(ctx context.Context) (string, error)
+
+	// EventSourceMode は現在のイベント受信方式（idle / subscription / polling）を返す
+	EventSourceMode() string
+
+	// WSReconnectCount は起動後に WebSocket 購読を張り直した回数を返す
+	WSReconnectCount() uint64
+
+	// LastBlockSeen は最後に観測したブロック番号と観測時刻を返す（購読の停止検知用）
+	LastBlockSeen() (uint64, time.Time)
+
+	// ActiveWSEndpoint は現在使っている WebSocket のエンドポイント（API キーを除いた URL）を返す
+	ActiveWSEndpoint() string
+
+	// GetChainID は接続先ネットワークのチェーンIDを返す
+	GetChainID(ctx context.Context) (uint64, error)
+
+	// EventSignatures はABIのイベント名ごとのシグネチャハッシュ（topic0）を返す
+	EventSignatures() map[string]string
+
+	// GetLatestBlock は最新のブロック番号を返す
+	GetLatestBlock(ctx context.Context) (uint64, error)
+
 	// VerifyTransaction はトランザクションを検証
 	VerifyTransaction(ctx context.Context, txHash string) (*model.TxVerification, error)
+
+	// WaitForReceipt はトランザクションが confirmations 件の確認を得るまで待つ
+	// マイニングされないまま破棄・置き換えられた場合は ErrTxDropped、ctx が終了した場合は ctx.Err() を返す
+	WaitForReceipt(ctx context.Context, txHash string, confirmations uint64) error
+
+	// GetTokenMetadata は NFT の tokenURI とメタデータ（name・description・image）を取得する
+	// トークンが存在しない場合は ErrTokenNotFound、メタデータが取得できない場合は Error を設定した部分的な結果を返す
+	GetTokenMetadata(ctx context.Context, tokenId uint64) (*model.NFTMetadata, error)
+
+	// DecodeTransactionLogs はトランザクションのレシートのログをイベント処理と同じ parseLog でデコードする
+	DecodeTransactionLogs(ctx context.Context, txHash string) (*model.DecodedTxLogs, error)
+}
+
+// DefaultReorgDepth はポーリング時に毎回再取得する直近ブロック数のデフォルト値
+const DefaultReorgDepth uint64 = 6
+
+// DefaultScanLookbackBlocks は開始ブロック未指定時に過去スキャンする直近ブロック数のデフォルト値（約1.4日分）
+const DefaultScanLookbackBlocks uint64 = 10000
+
+// pastScanChunkSize は過去スキャンで1回の eth_getLogs に含めるブロック数
+const pastScanChunkSize uint64 = 2000
+
+const (
+	// DefaultPollInterval はWebSocketが使えない場合のポーリング間隔のデフォルト
+	DefaultPollInterval = 5 * time.Second
+	// MinPollInterval はポーリング間隔の下限（Infura のクォータ保護のため）
+	MinPollInterval = 1 * time.Second
+	// DefaultMaxPollInterval は適応モードで延ばすポーリング間隔の上限のデフォルト
+	// 毎回の接続ヘルスチェックもこの間隔になるため、長くしすぎると切断の検知が遅れる
+	DefaultMaxPollInterval = 60 * time.Second
+	// pollIdleTicksBeforeBackoff は適応モードで間隔を延ばし始めるまでの連続した空振り回数
+	pollIdleTicksBeforeBackoff = 3
+)
+
+// PollConfig はポーリングの間隔設定
+// Adaptive が false の場合は常に Interval で、true の場合はイベントの無いポーリングが続くと
+// MaxInterval まで間隔を倍々に延ばし、イベントを受信したら Interval に戻す
+type PollConfig struct {
+	Interval    time.Duration
+	MaxInterval time.Duration
+	Adaptive    bool
+}
+
+// normalize は範囲外の値を下限・デフォルトに丸めた設定を返す
+func (c PollConfig) normalize() PollConfig {
+	if c.Interval <= 0 {
+		c.Interval = DefaultPollInterval
+	}
+	c.Interval = max(c.Interval, MinPollInterval)
+	if c.MaxInterval <= 0 {
+		c.MaxInterval = DefaultMaxPollInterval
+	}
+	c.MaxInterval = max(c.MaxInterval, c.Interval)
+	return c
 }
 
 // FrimaContractGateway はFrimaMarketplaceコントラクトとの連携実装
 type FrimaContractGateway struct {
-	client          *ethclient.Client
-	contractAddress common.Address
-	contractABI     abi.ABI
+	client          *ethclient.Client // 関数呼び出し・ログ取得用（HTTP）
+	contractAddress common.Address    // プライマリ（関数呼び出しの宛先）
+	// イベントを監視するアドレス（プライマリを先頭に、移行中の旧デプロイメントを含む）
+	contractAddresses []common.Address
+	contractABI       abi.ABI
+	nftABI            abi.ABI
+	reorgDepth        uint64 // ポーリング時に再取得する直近ブロック数
+	// 開始ブロック未指定時に過去スキャンする直近ブロック数
+	scanLookbackBlocks uint64
+	pollConfig         PollConfig
+	callTimeout        time.Duration // 読み取り呼び出し1回あたりのタイムアウト
+	eventBuffer        EventBufferConfig
+	blockTimes         *blockTimeCache
+
+	// イベント購読用の WebSocket 接続（切断時は破棄して接続し直す）
+	// 接続・購読に失敗した場合は wsURLs の次の URL に切り替える
+	wsURLs   []string
+	wsIndex  int
+	wsMu     sync.Mutex
+	wsClient *ethclient.Client
+	mode     string
+	// WebSocket 購読を張り直した回数（切断後の再購読・ポーリングからの復帰）
+	wsReconnects uint64
+
+	// nftContract() の結果（不変のため初回取得後はキャッシュする）
+	nftMu      sync.Mutex
+	nftAddress common.Address
+
+	// 停止検知: 最後に観測したブロック（新しいブロックの購読・イベント・ポーリング）とその時刻
+	stallThreshold  time.Duration
+	freshMu         sync.Mutex
+	lastBlockSeen   uint64
+	lastBlockSeenAt time.Time
 }
 
 // NewFrimaContractGateway は新しいコントラクトゲートウェイを作成
-func NewFrimaContractGateway(client *ethclient.Client, contractAddr string) (*FrimaContractGateway, error) {
+// contractAddrs の先頭がプライマリで、残りはイベントのみ監視する追加アドレス（旧デプロイメントなど）
+// wsURLs はイベント購読用の WebSocket URL（先頭から順に使い、失敗したら次に切り替える）で、
+// どれにも接続できない間はポーリングし、定期的に購読への復帰を試みる
+// callTimeout は GetItem などの読み取り呼び出しのタイムアウト（0以下なら DefaultCallTimeout）
+// eventBuffer はイベントチャネルのバッファ数と満杯時の挙動（Size が0以下なら DefaultEventBufferSize）
+// stallThreshold はこの時間新しいブロックを観測しなければ購読が停止したとみなして接続し直す（0以下なら DefaultStallThreshold）
+func NewFrimaContractGateway(client *ethclient.Client, wsURLs []string, contractAddrs []string, reorgDepth uint64, scanLookbackBlocks uint64, pollConfig PollConfig, callTimeout time.Duration, eventBuffer EventBufferConfig, stallThreshold time.Duration) (*FrimaContractGateway, error) {
+	if callTimeout <= 0 {
+		callTimeout = DefaultCallTimeout
+	}
+	if stallThreshold <= 0 {
+		stallThreshold = DefaultStallThreshold
+	}
+
+	nftABI, err := abi.JSON(strings.NewReader(erc721MetadataABI))
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse ERC-721 ABI: %w", err)
+	}
+
 	parsedABI, err := abi.JSON(strings.NewReader(FrimaMarketplaceABI))
 	if err != nil {
 		log.Printf("Failed to parse ABI: %v", err)
 		return nil, err
 	}
 
-	contractAddress := common.HexToAddress(contractAddr)
+	if len(contractAddrs) == 0 {
+		return nil, errors.New("no contract address specified")
+	}
+	contractAddresses := make([]common.Address, 0, len(contractAddrs))
+	for _, addr := range contractAddrs {
+		address := common.HexToAddress(addr)
+		if slices.Contains(contractAddresses, address) {
+			continue
+		}
+		contractAddresses = append(contractAddresses, address)
+	}
+	contractAddress := contractAddresses[0]
 	log.Printf("Initializing contract gateway: %s", contractAddress.Hex())
+	for _, address := range contractAddresses[1:] {
+		log.Printf("Also watching events from contract: %s", address.Hex())
+	}
 
 	// イベントが正しく定義されているか確認
-	eventNames := []string{"ItemListed", "ItemPurchased", "ItemUpdated", "ItemCancelled", "ReceiptConfirmed"}
+	eventNames := []string{"ItemListed", "ItemPurchased", "ItemUpdated", "ItemCancelled", "ReceiptConfirmed", "PriceReduced"}
 	for _, eventName := range eventNames {
 		if _, ok := parsedABI.Events[eventName]; !ok {
 			log.Printf("WARNING: Event '%s' not found in ABI", eventName)
@@ -67,9 +238,20 @@ func NewFrimaContractGateway(client *ethclient.Client, contractAddr string) (*Fr
 	}
 
 	return &FrimaContractGateway{
-		client:          client,
-		contractAddress: contractAddress,
-		contractABI:     parsedABI,
+		client:             client,
+		contractAddress:    contractAddress,
+		contractAddresses:  contractAddresses,
+		contractABI:        parsedABI,
+		nftABI:             nftABI,
+		reorgDepth:         reorgDepth,
+		scanLookbackBlocks: scanLookbackBlocks,
+		pollConfig:         pollConfig.normalize(),
+		eventBuffer:        eventBuffer.normalize(),
+		callTimeout:        callTimeout,
+		blockTimes:         newBlockTimeCache(),
+		wsURLs:             wsURLs,
+		mode:               EventSourceIdle,
+		stallThreshold:     stallThreshold,
 	}, nil
 }
 
@@ -77,19 +259,79 @@ func (g *FrimaContractGateway) GetContractAddress() string {
 	return g.contractAddress.Hex()
 }
 
-// GetItem はコントラクトから商品情報を取得
-func (g *FrimaContractGateway) GetItem(ctx context.Context, itemId uint64) (*model.ContractItem, error) {
-	data, err := g.contractABI.Pack("getItem", big.NewInt(int64(itemId)))
-	if err != nil {
-		return nil, err
+// isWatchedAddress はアドレスがイベント監視対象のコントラクトか判定
+func (g *FrimaContractGateway) isWatchedAddress(address common.Address) bool {
+	return slices.Contains(g.contractAddresses, address)
+}
+
+// withCallTimeout は読み取り呼び出し用に callTimeout の期限を付けた ctx を返す
+func (g *FrimaContractGateway) withCallTimeout(ctx context.Context) (context.Context, context.CancelFunc) {
+	return context.WithTimeout(ctx, g.callTimeout)
+}
+
+// callError はタイムアウトした呼び出しのエラーを ErrCallTimeout に変換する
+func (g *FrimaContractGateway) callError(ctx context.Context, err error) error {
+	if errors.Is(ctx.Err(), context.DeadlineExceeded) {
+		return fmt.Errorf("%w (after %v): %v", ErrCallTimeout, g.callTimeout, err)
 	}
+	return err
+}
+
+// callContract はプライマリコントラクトの view 関数を callTimeout 付きで呼び出す
+func (g *FrimaContractGateway) callContract(ctx context.Context, data []byte) ([]byte, error) {
+	ctx, cancel := g.withCallTimeout(ctx)
+	defer cancel()
 
 	msg := ethereum.CallMsg{
 		To:   &g.contractAddress,
 		Data: data,
 	}
-
 	result, err := g.client.CallContract(ctx, msg, nil)
+	if err != nil {
+		return nil, g.callError(ctx, err)
+	}
+	return result, nil
+}
+
+func (g *FrimaContractGateway) GetChainID(ctx context.Context) (uint64, error) {
+	ctx, cancel := g.withCallTimeout(ctx)
+	defer cancel()
+
+	chainID, err := g.client.ChainID(ctx)
+	if err != nil {
+		return 0, g.callError(ctx, err)
+	}
+	return chainID.Uint64(), nil
+}
+
+// EventSignatures は parseLog がイベントの判別に使う topic0 をイベント名ごとに返す
+func (g *FrimaContractGateway) EventSignatures() map[string]string {
+	signatures := make(map[string]string, len(g.contractABI.Events))
+	for name, event := range g.contractABI.Events {
+		signatures[name] = event.ID.Hex()
+	}
+	return signatures
+}
+
+func (g *FrimaContractGateway) GetLatestBlock(ctx context.Context) (uint64, error) {
+	ctx, cancel := g.withCallTimeout(ctx)
+	defer cancel()
+
+	block, err := g.client.BlockNumber(ctx)
+	if err != nil {
+		return 0, g.callError(ctx, err)
+	}
+	return block, nil
+}
+
+// GetItem はコントラクトから商品情報を取得
+func (g *FrimaContractGateway) GetItem(ctx context.Context, itemId uint64) (*model.ContractItem, error) {
+	data, err := g.contractABI.Pack("getItem", big.NewInt(int64(itemId)))
+	if err != nil {
+		return nil, err
+	}
+
+	result, err := g.callContract(ctx, data)
 	if err != nil {
 		return nil, err
 	}
@@ -137,10 +379,42 @@ func (g *FrimaContractGateway) GetItem(ctx context.Context, itemId uint64) (*mod
 	}, nil
 }
 
+// GetItemCount はitemIdCounterビュー関数を呼び出して商品数を取得
+func (g *FrimaContractGateway) GetItemCount(ctx context.Context) (uint64, error) {
+	data, err := g.contractABI.Pack("itemIdCounter")
+	if err != nil {
+		return 0, err
+	}
+
+	result, err := g.callContract(ctx, data)
+	if err != nil {
+		if strings.Contains(err.Error(), "execution reverted") {
+			return 0, fmt.Errorf("%w: %v", ErrContractUnavailable, err)
+		}
+		return 0, err
+	}
+	// コードの無いアドレスへの呼び出しはエラーにならず空の結果を返す
+	if len(result) == 0 {
+		return 0, fmt.Errorf("%w: no code at %s", ErrContractUnavailable, g.contractAddress.Hex())
+	}
+
+	values, err := g.contractABI.Unpack("itemIdCounter", result)
+	if err != nil {
+		return 0, err
+	}
+	count, ok := values[0].(*big.Int)
+	if !ok {
+		return 0, errors.New("unexpected itemIdCounter result type")
+	}
+
+	return count.Uint64(), nil
+}
+
 // SubscribeEvents はコントラクトイベントをWebSocket経由で購読
-// WebSocket接続が失敗した場合、定期的なポーリングにフォールバックする
+// 購読中の接続エラーはゲートウェイ内で再購読・補完するため、チャネルは ctx のキャンセルまで閉じない
+// 初回のWebSocket接続が失敗した場合、定期的なポーリングにフォールバックし、WebSocket が復旧したら購読に戻る
 func (g *FrimaContractGateway) SubscribeEvents(ctx context.Context) (<-chan *model.ContractEvent, error) {
-	eventChan := make(chan *model.ContractEvent, 100)
+	eventChan := make(chan *model.ContractEvent, g.eventBuffer.Size)
 
 	// 接続のヘルスチェック（タイムアウト付き）
 	healthCtx, cancel := context.WithTimeout(ctx, 10*time.Second)
@@ -149,99 +423,316 @@ func (g *FrimaContractGateway) SubscribeEvents(ctx context.Context) (<-chan *mod
 	if err != nil {
 		return nil, fmt.Errorf("connection test failed (connection may be lost): %w", err)
 	}
+	g.markBlockSeen(header.Number.Uint64())
+	g.resetStallClock()
 
 	// WebSocket接続を試みる
-	query := ethereum.FilterQuery{
-		Addresses: []common.Address{g.contractAddress},
-	}
-	logs := make(chan types.Log)
-	sub, err := g.client.SubscribeFilterLogs(ctx, query, logs)
+	sub, logs, err := g.subscribeLogs(ctx)
 	if err != nil {
 		log.Printf("WARNING: WebSocket subscription failed, falling back to polling: %v", err)
+		g.setMode(EventSourcePolling)
 		go g.pollEvents(ctx, eventChan, header.Number.Uint64())
 		return eventChan, nil
 	}
 
 	log.Printf("Subscribed to events via WebSocket (latest block: %d)", header.Number.Uint64())
+	g.setMode(EventSourceSubscription)
 
-	go func() {
-		defer func() {
+	go g.runSubscription(ctx, eventChan, sub, logs, header.Number.Uint64())
+
+	return eventChan, nil
+}
+
+// runSubscription は WebSocket 購読で受信したイベントを eventChan に送る
+// 購読エラー時はチャネルを閉じずにゲートウェイ内で再購読し、切断中に取りこぼしたブロックを
+// 最後に受信したブロックから FilterLogs で補完する。補完範囲は送信済みのイベントと重なるが、
+// useCase 側の重複排除で除外される。eventChan は ctx のキャンセル時（またはパニック時）のみ閉じる
+// エラーにならずに配信が止まる場合に備え、新しいブロックを stallThreshold の間観測しなければ同様に再購読する
+func (g *FrimaContractGateway) runSubscription(ctx context.Context, eventChan chan *model.ContractEvent, sub ethereum.Subscription, logs chan types.Log, lastSeenBlock uint64) {
+	defer close(eventChan)
+	heads, headSub := g.subscribeHeads(ctx)
+	defer func() {
+		if sub != nil {
 			sub.Unsubscribe()
-			// WebSocket接続が切れたときにチャネルを閉じる（useCase側で再接続が試みられる）
-			close(eventChan)
-		}()
+		}
+		if headSub != nil {
+			headSub.Unsubscribe()
+		}
+	}()
+	defer func() {
+		if r := recover(); r != nil {
+			// pollEvents と同じく、パニック時もチャネルを閉じてuseCase側で再接続を試みる
+			log.Printf("ERROR: runSubscription panic: %v", r)
+		}
+	}()
 
-		for {
-			select {
-			case <-ctx.Done():
+	headErr := func() <-chan error {
+		if headSub == nil {
+			return nil
+		}
+		return headSub.Err()
+	}
+	// 購読を張り直し、切断中のブロックを補完する（ctx がキャンセルされた場合は false）
+	reconnect := func() bool {
+		sub.Unsubscribe()
+		if headSub != nil {
+			headSub.Unsubscribe()
+		}
+		g.dropWSClient()
+		sub, logs, lastSeenBlock = g.resubscribe(ctx, eventChan, lastSeenBlock)
+		if sub == nil {
+			headSub = nil
+			return false
+		}
+		heads, headSub = g.subscribeHeads(ctx)
+		g.resetStallClock()
+		return true
+	}
+
+	watchdog := time.NewTicker(stallCheckInterval)
+	defer watchdog.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case err := <-sub.Err():
+			log.Printf("ERROR: WebSocket subscription error, resubscribing: %v", err)
+			if !reconnect() {
 				return
-			case err := <-sub.Err():
-				log.Printf("ERROR: WebSocket error, channel will be closed for reconnection: %v", err)
-				// チャネルを閉じてuseCase側の再接続ロジックをトリガー
+			}
+		case err := <-headErr():
+			log.Printf("ERROR: New head subscription error, resubscribing: %v", err)
+			if !reconnect() {
 				return
-			case vLog, ok := <-logs:
-				if !ok {
-					log.Printf("WARNING: WebSocket logs channel closed, reconnecting...")
+			}
+		case head := <-heads:
+			g.markBlockSeen(head.Number.Uint64())
+		case <-watchdog.C:
+			// 新しいブロックの購読が無い接続では、イベントが無いだけの状態と区別できないため判定しない
+			if headSub == nil {
+				continue
+			}
+			if stalled, since := g.stalled(); stalled {
+				g.reportStall(since)
+				if !reconnect() {
 					return
 				}
-				if vLog.Address != g.contractAddress {
-					continue
-				}
-				event := g.parseLog(vLog)
-				if event != nil {
-					log.Printf("Event received: %s itemId=%d tx=%s", event.Type, event.ItemId, event.TxHash)
-					select {
-					case eventChan <- event:
-					case <-ctx.Done():
-						return
-					}
+			}
+		case vLog := <-logs:
+			if !g.isWatchedAddress(vLog.Address) {
+				continue
+			}
+			g.markBlockSeen(vLog.BlockNumber)
+			lastSeenBlock = max(lastSeenBlock, vLog.BlockNumber)
+			event := g.parseLog(vLog)
+			if event != nil {
+				logger.ForEvent(event).Info("Event received")
+				if !g.sendEvent(ctx, eventChan, event, g.eventBuffer.DropOldest) {
+					return
 				}
 			}
 		}
-	}()
+	}
+}
 
-	return eventChan, nil
+// resubscribe は WebSocket 購読を張り直し、fromBlock から最新ブロックまでのイベントを補完する
+// 購読と補完の両方に成功するまで指数バックオフで再試行し、補完した最新ブロックを返す
+// ctx がキャンセルされた場合は nil の購読を返す
+func (g *FrimaContractGateway) resubscribe(ctx context.Context, eventChan chan *model.ContractEvent, fromBlock uint64) (ethereum.Subscription, chan types.Log, uint64) {
+	retryDelay := 1 * time.Second
+	maxRetryDelay := 60 * time.Second
+
+	for {
+		sub, logs, err := g.subscribeLogs(ctx)
+		if err == nil {
+			// 先に購読してから補完することで、補完中に発生したイベントも購読側で受け取れる
+			toBlock, err := g.backfillEvents(ctx, eventChan, fromBlock)
+			if err == nil {
+				log.Printf("WebSocket resubscribed (backfilled blocks %d-%d)", fromBlock, toBlock)
+				g.countReconnect()
+				return sub, logs, max(fromBlock, toBlock)
+			}
+			sub.Unsubscribe()
+			log.Printf("ERROR: Failed to backfill events from block %d: %v, retrying in %v", fromBlock, err, retryDelay)
+		} else {
+			log.Printf("ERROR: Failed to resubscribe: %v, retrying in %v", err, retryDelay)
+		}
+
+		select {
+		case <-ctx.Done():
+			return nil, nil, fromBlock
+		case <-time.After(retryDelay):
+			retryDelay = min(retryDelay*2, maxRetryDelay)
+		}
+	}
+}
+
+// backfillEvents は fromBlock から最新ブロックまでのイベントを pastScanChunkSize ブロックずつ取得して eventChan に送る
+// 送信したブロック範囲の終端を返す
+func (g *FrimaContractGateway) backfillEvents(ctx context.Context, eventChan chan *model.ContractEvent, fromBlock uint64) (uint64, error) {
+	toBlock, err := g.GetLatestBlock(ctx)
+	if err != nil {
+		return 0, fmt.Errorf("failed to get latest block: %w", err)
+	}
+
+	for start := fromBlock; start <= toBlock; start += pastScanChunkSize {
+		end := min(start+pastScanChunkSize-1, toBlock)
+		logs, err := g.client.FilterLogs(ctx, ethereum.FilterQuery{
+			Addresses: g.contractAddresses,
+			FromBlock: new(big.Int).SetUint64(start),
+			ToBlock:   new(big.Int).SetUint64(end),
+		})
+		if err != nil {
+			return 0, fmt.Errorf("failed to filter logs in blocks %d-%d: %w", start, end, err)
+		}
+
+		for _, vLog := range logs {
+			if !g.isWatchedAddress(vLog.Address) {
+				continue
+			}
+			event := g.parseLog(vLog)
+			if event == nil {
+				continue
+			}
+			logger.ForEvent(event).Info("Backfilled event")
+			if !g.sendEvent(ctx, eventChan, event, g.eventBuffer.DropOldest) {
+				return 0, ctx.Err()
+			}
+		}
+	}
+
+	return toBlock, nil
 }
 
 // pollEvents は定期的にブロックチェーンをポーリングしてイベントを取得
-// チャネルをクローズしない（永続実行）
-func (g *FrimaContractGateway) pollEvents(ctx context.Context, eventChan chan<- *model.ContractEvent, startBlock uint64) {
+// 終了時（ctxのキャンセル・接続エラー・パニック）にチャネルを閉じ、useCase側に再接続または停止を促す
+//
+// リオルグ対策として、毎回直近 reorgDepth ブロックを再取得してカノニカルなログを再送する。
+// 変化していないログは useCase 側の重複排除で除外される。イベントを送出したブロックのハッシュが
+// 変わっていた場合、そのブロックの旧イベントを Removed=true で送出して置き換えられたことを通知する。
+// reorgDepth を大きくするほど深いリオルグに対応できるが、毎回の eth_getLogs の範囲と
+// ヘッダー取得の RPC 呼び出しが増える。また通知自体は取り込み直後に行うため、
+// バックエンドは後から置き換えられるイベントを受け取る可能性がある。
+func (g *FrimaContractGateway) pollEvents(ctx context.Context, eventChan chan *model.ContractEvent, startBlock uint64) {
+	// WebSocket 購読に切り替えた場合、チャネルは runSubscription が引き継ぐ
+	handedOff := false
+	defer func() {
+		if !handedOff {
+			close(eventChan)
+		}
+	}()
 	defer func() {
 		if r := recover(); r != nil {
+			// パニック時もチャネルを閉じてuseCase側で再接続を試みる
 			log.Printf("ERROR: pollEvents panic: %v", r)
-			// パニック時はチャネルを閉じてuseCase側で再接続を試みる
-			close(eventChan)
 		}
 	}()
 
-	ticker := time.NewTicker(5 * time.Second)
-	defer ticker.Stop()
+	// 適応モードでは間隔が変わるため、Ticker ではなく毎回 Timer を張り直す
+	interval := g.pollConfig.Interval
+	idleTicks := 0
+	timer := time.NewTimer(interval)
+	defer timer.Stop()
+
+	upgrade := time.NewTicker(wsUpgradeInterval)
+	defer upgrade.Stop()
 
 	lastProcessedBlock := startBlock
-	log.Printf("Starting event polling from block %d", lastProcessedBlock)
+	log.Printf("Starting event polling from block %d (reorg depth: %d, interval: %v, adaptive: %v)", lastProcessedBlock, g.reorgDepth, interval, g.pollConfig.Adaptive)
+
+	// リオルグ検知用: イベントを送出したブロックのハッシュと、そのブロックのイベント
+	emittedHashes := make(map[uint64]common.Hash)
+	emittedEvents := make(map[uint64][]*model.ContractEvent)
+
+	send := func(event *model.ContractEvent) bool {
+		return g.sendEvent(ctx, eventChan, event, g.eventBuffer.DropOldest)
+	}
 
-		for {
-			select {
-			case <-ctx.Done():
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-upgrade.C:
+			// WebSocket が復旧していれば購読に切り替え、ポーリングで処理した範囲の後から補完する
+			sub, logs, err := g.subscribeLogs(ctx)
+			if err != nil {
+				continue
+			}
+			toBlock, err := g.backfillEvents(ctx, eventChan, lastProcessedBlock)
+			if err != nil {
+				log.Printf("ERROR: Failed to backfill events before switching to WebSocket: %v", err)
+				sub.Unsubscribe()
+				continue
+			}
+			log.Printf("WebSocket available again, switching from polling to subscription (backfilled blocks %d-%d)", lastProcessedBlock, toBlock)
+			g.setMode(EventSourceSubscription)
+			g.countReconnect()
+			handedOff = true
+			go g.runSubscription(ctx, eventChan, sub, logs, max(lastProcessedBlock, toBlock))
+			return
+		case <-timer.C:
+			// 接続のヘルスチェック
+			header, err := g.client.HeaderByNumber(ctx, nil)
+			if err != nil {
+				log.Printf("ERROR: Failed to get latest block (connection may be lost): %v", err)
+				// 接続エラーの場合、チャネルを閉じてuseCase側で再接続を試みる
 				return
-			case <-ticker.C:
-				// 接続のヘルスチェック
-				header, err := g.client.HeaderByNumber(ctx, nil)
-				if err != nil {
-					log.Printf("ERROR: Failed to get latest block (connection may be lost): %v", err)
-					// 接続エラーの場合、チャネルを閉じてuseCase側で再接続を試みる
-					close(eventChan)
-					return
-				}
+			}
 
 			currentBlock := header.Number.Uint64()
-			if currentBlock <= lastProcessedBlock {
+			g.markBlockSeen(currentBlock)
+			// ノードの最新ブロックが進まない場合は、チャネルを閉じてuseCase側で接続し直す
+			if stalled, since := g.stalled(); stalled {
+				g.reportStall(since)
+				return
+			}
+
+			// 直近 reorgDepth ブロックを再取得の対象に含める（購読開始前のブロックは過去スキャンの担当）
+			fromBlock := startBlock + 1
+			if lastProcessedBlock+1 > startBlock+g.reorgDepth {
+				fromBlock = lastProcessedBlock + 1 - g.reorgDepth
+			}
+			if currentBlock < fromBlock {
+				timer.Reset(interval)
 				continue
 			}
 
+			// イベントを送出したブロックがリオルグで置き換えられていないか確認
+			for blockNo, oldHash := range emittedHashes {
+				if blockNo < fromBlock {
+					// リオルグ範囲外になったブロックは追跡をやめる
+					delete(emittedHashes, blockNo)
+					delete(emittedEvents, blockNo)
+					continue
+				}
+				if blockNo > currentBlock {
+					continue
+				}
+				canonical, err := g.client.HeaderByNumber(ctx, new(big.Int).SetUint64(blockNo))
+				if err != nil {
+					log.Printf("ERROR: Failed to get header for block %d: %v", blockNo, err)
+					continue
+				}
+				if canonical.Hash() == oldHash {
+					continue
+				}
+
+				log.Printf("WARNING: Reorg detected at block %d (%s -> %s)", blockNo, oldHash.Hex(), canonical.Hash().Hex())
+				for _, old := range emittedEvents[blockNo] {
+					superseded := *old
+					superseded.Removed = true
+					if !send(&superseded) {
+						return
+					}
+				}
+				delete(emittedHashes, blockNo)
+				delete(emittedEvents, blockNo)
+			}
+
 			query := ethereum.FilterQuery{
-				Addresses: []common.Address{g.contractAddress},
-				FromBlock: new(big.Int).SetUint64(lastProcessedBlock + 1),
+				Addresses: g.contractAddresses,
+				FromBlock: new(big.Int).SetUint64(fromBlock),
 				ToBlock:   new(big.Int).SetUint64(currentBlock),
 			}
 
@@ -249,36 +740,90 @@ func (g *FrimaContractGateway) pollEvents(ctx context.Context, eventChan chan<-
 			if err != nil {
 				log.Printf("ERROR: Failed to filter logs: %v", err)
 				lastProcessedBlock = currentBlock
+				timer.Reset(interval)
 				continue
 			}
 
 			if len(logs) > 0 {
-				log.Printf("Found %d events (blocks %d-%d)", len(logs), lastProcessedBlock+1, currentBlock)
+				log.Printf("Found %d events (blocks %d-%d)", len(logs), fromBlock, currentBlock)
 			}
+			interval, idleTicks = g.nextPollInterval(interval, idleTicks, len(logs) > 0)
 
 			for _, vLog := range logs {
-				if vLog.Address != g.contractAddress {
+				if !g.isWatchedAddress(vLog.Address) {
 					continue
 				}
 				event := g.parseLog(vLog)
-				if event != nil {
-					log.Printf("Event received: %s itemId=%d tx=%s", event.Type, event.ItemId, event.TxHash)
-					select {
-					case eventChan <- event:
-					case <-ctx.Done():
-						return
-					}
+				if event == nil {
+					continue
+				}
+
+				// リオルグ検知のため、送出したイベントをブロックハッシュごとに記録
+				if emittedHashes[vLog.BlockNumber] != vLog.BlockHash {
+					emittedHashes[vLog.BlockNumber] = vLog.BlockHash
+					emittedEvents[vLog.BlockNumber] = nil
+				}
+				alreadyRecorded := slices.ContainsFunc(emittedEvents[vLog.BlockNumber], func(e *model.ContractEvent) bool {
+					return e.TxHash == event.TxHash && e.LogIndex == event.LogIndex
+				})
+				if !alreadyRecorded {
+					emittedEvents[vLog.BlockNumber] = append(emittedEvents[vLog.BlockNumber], event)
+				}
+
+				logger.ForEvent(event).Info("Event received")
+				if !send(event) {
+					return
 				}
 			}
 
 			lastProcessedBlock = currentBlock
+			timer.Reset(interval)
 		}
 	}
 }
 
+// nextPollInterval は今回のポーリング結果から次のポーリング間隔と連続した空振り回数を返す
+// リオルグ対策で直近ブロックを再取得するため、直近のイベントが再取得されている間は空振りとみなさない
+func (g *FrimaContractGateway) nextPollInterval(interval time.Duration, idleTicks int, foundLogs bool) (time.Duration, int) {
+	if !g.pollConfig.Adaptive {
+		return interval, 0
+	}
+	if foundLogs {
+		if interval != g.pollConfig.Interval {
+			log.Printf("Events found, polling interval reset to %v", g.pollConfig.Interval)
+		}
+		return g.pollConfig.Interval, 0
+	}
+
+	idleTicks++
+	if idleTicks < pollIdleTicksBeforeBackoff || interval >= g.pollConfig.MaxInterval {
+		return interval, idleTicks
+	}
+	next := min(interval*2, g.pollConfig.MaxInterval)
+	log.Printf("No events for %d polls, polling interval increased to %v", idleTicks, next)
+	return next, 0
+}
+
 // ScanPastEvents は過去のブロックからイベントをスキャン
-func (g *FrimaContractGateway) ScanPastEvents(ctx context.Context, fromBlock uint64, toBlock *uint64) (<-chan *model.ContractEvent, error) {
-	eventChan := make(chan *model.ContractEvent, 100)
+// fromBlock が0の場合は直近 scanLookbackBlocks ブロックを対象にする。
+// Infura の eth_getLogs の結果件数制限を避けるため pastScanChunkSize ブロックずつ分割して取得し、
+// 一部のチャンクが失敗しても残りのチャンクのスキャンは続ける
+func (g *FrimaContractGateway) ScanPastEvents(ctx context.Context, fromBlock uint64, toBlock *uint64, eventTypes []model.EventType) (<-chan *model.ContractEvent, error) {
+	// イベントの種類は Topics[0]（イベントシグネチャ）で絞り込む。空の場合はすべてのイベント
+	var topics [][]common.Hash
+	if len(eventTypes) > 0 {
+		sigs := make([]common.Hash, 0, len(eventTypes))
+		for _, eventType := range eventTypes {
+			event, ok := g.contractABI.Events[string(eventType)]
+			if !ok {
+				return nil, fmt.Errorf("unknown event type: %s", eventType)
+			}
+			sigs = append(sigs, event.ID)
+		}
+		topics = [][]common.Hash{sigs}
+	}
+
+	eventChan := make(chan *model.ContractEvent, g.eventBuffer.Size)
 
 	go func() {
 		defer close(eventChan)
@@ -292,9 +837,8 @@ func (g *FrimaContractGateway) ScanPastEvents(ctx context.Context, fromBlock uin
 		currentBlock := header.Number.Uint64()
 		actualFromBlock := fromBlock
 		if fromBlock == 0 {
-			// より広い範囲をスキャン（10000ブロック、約1.4日分）
-			if currentBlock > 10000 {
-				actualFromBlock = currentBlock - 10000
+			if currentBlock > g.scanLookbackBlocks {
+				actualFromBlock = currentBlock - g.scanLookbackBlocks
 			}
 		}
 
@@ -302,47 +846,156 @@ func (g *FrimaContractGateway) ScanPastEvents(ctx context.Context, fromBlock uin
 		if toBlock != nil {
 			actualToBlock = *toBlock
 		}
-
-		query := ethereum.FilterQuery{
-			Addresses: []common.Address{g.contractAddress},
-			FromBlock: new(big.Int).SetUint64(actualFromBlock),
-			ToBlock:   new(big.Int).SetUint64(actualToBlock),
-		}
-
-		logs, err := g.client.FilterLogs(ctx, query)
-		if err != nil {
-			log.Printf("ERROR: Failed to scan past events: %v", err)
+		if actualFromBlock > actualToBlock {
+			log.Printf("No blocks to scan (from %d > to %d)", actualFromBlock, actualToBlock)
 			return
 		}
 
-		log.Printf("Scanned %d past events (blocks %d-%d)", len(logs), actualFromBlock, actualToBlock)
+		totalChunks := (actualToBlock-actualFromBlock)/pastScanChunkSize + 1
+		log.Printf("Scanning past events in blocks %d-%d (%d chunks)", actualFromBlock, actualToBlock, totalChunks)
 
-		for _, vLog := range logs {
-			if vLog.Address != g.contractAddress {
+		var total, failedChunks int
+		chunk := uint64(0)
+		for start := actualFromBlock; start <= actualToBlock; start += pastScanChunkSize {
+			chunk++
+			end := min(start+pastScanChunkSize-1, actualToBlock)
+
+			query := ethereum.FilterQuery{
+				Addresses: g.contractAddresses,
+				FromBlock: new(big.Int).SetUint64(start),
+				ToBlock:   new(big.Int).SetUint64(end),
+				Topics:    topics,
+			}
+
+			logs, err := g.client.FilterLogs(ctx, query)
+			if err != nil {
+				if ctx.Err() != nil {
+					return
+				}
+				failedChunks++
+				log.Printf("ERROR: Failed to scan past events in blocks %d-%d (chunk %d/%d): %v", start, end, chunk, totalChunks, err)
 				continue
 			}
-			event := g.parseLog(vLog)
-			if event != nil {
-				log.Printf("Past event: %s itemId=%d tx=%s", event.Type, event.ItemId, event.TxHash)
-				eventChan <- event
+
+			log.Printf("Scanned %d past events in blocks %d-%d (chunk %d/%d)", len(logs), start, end, chunk, totalChunks)
+			total += len(logs)
+
+			for _, vLog := range logs {
+				if !g.isWatchedAddress(vLog.Address) {
+					continue
+				}
+				event := g.parseLog(vLog)
+				if event == nil {
+					continue
+				}
+				logger.ForEvent(event).Info("Past event")
+				// 過去スキャンは完了を待って処理済みを記録するため、イベントを破棄しない
+				if !g.sendEvent(ctx, eventChan, event, false) {
+					return
+				}
+			}
+
+			// uint64 のオーバーフローを防ぐ
+			if end == actualToBlock {
+				break
 			}
 		}
+
+		if failedChunks > 0 {
+			log.Printf("WARNING: Past event scan finished with %d failed chunks", failedChunks)
+		}
+		log.Printf("Scanned %d past events (blocks %d-%d)", total, actualFromBlock, actualToBlock)
 	}()
 
 	return eventChan, nil
 }
 
+// GetItemHistory は itemId を indexed トピックに持つイベントを全種類まとめて取得し、ブロック順に返す
+// すべてのイベントで itemId は1番目の indexed 引数（Topics[1]）
+func (g *FrimaContractGateway) GetItemHistory(ctx context.Context, itemId uint64, fromBlock uint64) ([]*model.ContractEvent, error) {
+	eventSigs := make([]common.Hash, 0, len(g.contractABI.Events))
+	for _, event := range g.contractABI.Events {
+		eventSigs = append(eventSigs, event.ID)
+	}
+
+	// uint256 の indexed 引数は32バイト左詰めゼロパディングでトピックに格納される
+	itemIdTopic := common.BigToHash(new(big.Int).SetUint64(itemId))
+
+	query := ethereum.FilterQuery{
+		Addresses: []common.Address{g.contractAddress}, // itemId はデプロイメントごとの連番のためプライマリのみ
+		FromBlock: new(big.Int).SetUint64(fromBlock),
+		Topics:    [][]common.Hash{eventSigs, {itemIdTopic}},
+	}
+
+	logs, err := g.client.FilterLogs(ctx, query)
+	if err != nil {
+		return nil, fmt.Errorf("failed to filter item logs: %w", err)
+	}
+
+	events := make([]*model.ContractEvent, 0, len(logs))
+	for _, vLog := range logs {
+		if event := g.parseLog(vLog); event != nil {
+			events = append(events, event)
+		}
+	}
+
+	slices.SortFunc(events, func(a, b *model.ContractEvent) int {
+		if a.BlockNo != b.BlockNo {
+			return cmp.Compare(a.BlockNo, b.BlockNo)
+		}
+		return cmp.Compare(a.LogIndex, b.LogIndex)
+	})
+
+	log.Printf("Found %d events for item %d (from block %d)", len(events), itemId, fromBlock)
+	return events, nil
+}
+
+// GetListedItemIds は indexed の seller でフィルタした ItemListed ログから itemId を取得する
+// 出品後に状態が変わった商品も含むため、現在の状態は呼び出し側で GetItem により確認する
+func (g *FrimaContractGateway) GetListedItemIds(ctx context.Context, seller common.Address, fromBlock uint64) ([]uint64, error) {
+	// ItemListed の indexed 引数は itemId, tokenId, seller の順でトピックに格納される
+	query := ethereum.FilterQuery{
+		Addresses: []common.Address{g.contractAddress}, // itemId はデプロイメントごとの連番のためプライマリのみ
+		FromBlock: new(big.Int).SetUint64(fromBlock),
+		Topics: [][]common.Hash{
+			{g.contractABI.Events["ItemListed"].ID},
+			nil,
+			nil,
+			{common.BytesToHash(seller.Bytes())},
+		},
+	}
+
+	logs, err := g.client.FilterLogs(ctx, query)
+	if err != nil {
+		return nil, fmt.Errorf("failed to filter seller logs: %w", err)
+	}
+
+	itemIds := make([]uint64, 0, len(logs))
+	for _, vLog := range logs {
+		if len(vLog.Topics) < 2 || vLog.Removed {
+			continue
+		}
+		itemIds = append(itemIds, new(big.Int).SetBytes(vLog.Topics[1].Bytes()).Uint64())
+	}
+	slices.Sort(itemIds)
+	itemIds = slices.Compact(itemIds)
+
+	log.Printf("Found %d listed items for seller %s (from block %d)", len(itemIds), seller.Hex(), fromBlock)
+	return itemIds, nil
+}
+
 // parseLog はログをContractEventに変換
 func (g *FrimaContractGateway) parseLog(vLog types.Log) *model.ContractEvent {
-	if len(vLog.Topics) == 0 {
-		log.Printf("Received log with no topics (tx: %s, address: %s)", vLog.TxHash.Hex(), vLog.Address.Hex())
+	// 監視対象のコントラクトアドレスか確認
+	if !g.isWatchedAddress(vLog.Address) {
+		log.Printf("Log address mismatch: expected one of %v, got %s (tx: %s)", g.contractAddresses, vLog.Address.Hex(), vLog.TxHash.Hex())
 		return nil
 	}
 
-	// コントラクトアドレスが一致するか確認
-	if vLog.Address != g.contractAddress {
-		log.Printf("Log address mismatch: expected %s, got %s (tx: %s)", g.contractAddress.Hex(), vLog.Address.Hex(), vLog.TxHash.Hex())
-		return nil
+	// 匿名イベントはシグネチャのトピックを持たないため、種類を判別できない
+	if len(vLog.Topics) == 0 {
+		log.Printf("WARNING: Received log with no topics (tx: %s, address: %s). This might be an anonymous event.", vLog.TxHash.Hex(), vLog.Address.Hex())
+		return g.finishEvent(g.parseUnknown(vLog), vLog)
 	}
 
 	eventSig := vLog.Topics[0].Hex()
@@ -351,24 +1004,48 @@ func (g *FrimaContractGateway) parseLog(vLog types.Log) *model.ContractEvent {
 	itemUpdatedSig := g.contractABI.Events["ItemUpdated"].ID.Hex()
 	itemCancelledSig := g.contractABI.Events["ItemCancelled"].ID.Hex()
 	receiptConfirmedSig := g.contractABI.Events["ReceiptConfirmed"].ID.Hex()
+	priceReducedSig := g.contractABI.Events["PriceReduced"].ID.Hex()
 
+	var event *model.ContractEvent
 	switch eventSig {
 	case itemListedSig:
-		return g.parseItemListed(vLog)
+		event = g.parseItemListed(vLog)
 	case itemPurchasedSig:
-		return g.parseItemPurchased(vLog)
+		event = g.parseItemPurchased(vLog)
 	case itemUpdatedSig:
-		return g.parseItemUpdated(vLog)
+		event = g.parseItemUpdated(vLog)
 	case itemCancelledSig:
-		return g.parseItemCancelled(vLog)
+		event = g.parseItemCancelled(vLog)
 	case receiptConfirmedSig:
-		return g.parseReceiptConfirmed(vLog)
+		event = g.parseReceiptConfirmed(vLog)
+	case priceReducedSig:
+		event = g.parseItemPriceReduced(vLog)
 	default:
 		// 未知のイベントシグネチャをログに記録（デバッグ用）
 		log.Printf("WARNING: Unknown event signature: %s (tx: %s, block: %d, address: %s). This might be from another contract or a different event.",
 			eventSig, vLog.TxHash.Hex(), vLog.BlockNumber, vLog.Address.Hex())
-		return nil
+		event = g.parseUnknown(vLog)
 	}
+
+	return g.finishEvent(event, vLog)
+}
+
+// finishEvent はイベントの種類によらない共通の情報をログから設定する
+func (g *FrimaContractGateway) finishEvent(event *model.ContractEvent, vLog types.Log) *model.ContractEvent {
+	// 移行中は複数のデプロイメントを監視するため、発行元のコントラクトを記録する
+	event.ContractAddress = vLog.Address.Hex()
+	// 重複排除に使うログの位置情報
+	event.BlockHash = vLog.BlockHash.Hex()
+	event.LogIndex = vLog.Index
+	// コントラクトの createdAt などとは別に、ブロックの実際の時刻を記録する
+	event.BlockTime = g.blockTime(vLog.BlockHash)
+	// WebSocket購読ではリオルグで取り消されたログに Removed が立つ
+	event.Removed = vLog.Removed
+	// parseLog から notifyBackend までのログを相関させるためのID
+	event.TraceID = logger.NewTraceID()
+	// 通知・配信するアドレスの表記をそろえる
+	event.NormalizeAddresses()
+	return event
 }
 
 func (g *FrimaContractGateway) parseItemListed(vLog types.Log) *model.ContractEvent {
@@ -496,6 +1173,23 @@ func (g *FrimaContractGateway) parseItemUpdated(vLog types.Log) *model.ContractE
 	return event
 }
 
+// parseUnknown はABIに無いイベント（匿名イベントを含む）の生のトピックとデータを保持する
+// ABIの不一致を本番環境で調査するため。通常の処理では useCase 側で破棄される
+func (g *FrimaContractGateway) parseUnknown(vLog types.Log) *model.ContractEvent {
+	topics := make([]string, 0, len(vLog.Topics))
+	for _, topic := range vLog.Topics {
+		topics = append(topics, topic.Hex())
+	}
+
+	return &model.ContractEvent{
+		Type:      model.EventUnknown,
+		TxHash:    vLog.TxHash.Hex(),
+		BlockNo:   vLog.BlockNumber,
+		RawTopics: topics,
+		RawData:   hexutil.Encode(vLog.Data),
+	}
+}
+
 func (g *FrimaContractGateway) parseItemCancelled(vLog types.Log) *model.ContractEvent {
 	event := &model.ContractEvent{
 		Type:    model.EventItemCancelled,
@@ -541,6 +1235,47 @@ func (g *FrimaContractGateway) parseReceiptConfirmed(vLog types.Log) *model.Cont
 	return event
 }
 
+func (g *FrimaContractGateway) parseItemPriceReduced(vLog types.Log) *model.ContractEvent {
+	event := &model.ContractEvent{
+		Type:    model.EventPriceReduced,
+		TxHash:  vLog.TxHash.Hex(),
+		BlockNo: vLog.BlockNumber,
+	}
+
+	// indexed: itemId
+	if len(vLog.Topics) >= 2 {
+		event.ItemId = new(big.Int).SetBytes(vLog.Topics[1].Bytes()).Uint64()
+	}
+
+	// non-indexed データをデコード
+	data := make(map[string]interface{})
+	err := g.contractABI.UnpackIntoMap(data, "PriceReduced", vLog.Data)
+	if err != nil {
+		log.Printf("Failed to unpack PriceReduced: %v", err)
+		return event
+	}
+
+	if oldPrice, ok := data["oldPrice"].(*big.Int); ok {
+		event.OldPrice = oldPrice
+	}
+	if newPrice, ok := data["newPrice"].(*big.Int); ok {
+		event.Price = newPrice
+	}
+
+	return event
+}
+
+// WaitForReceipt は txwait.WaitForReceipt でレシートと確認数をポーリングする
+func (g *FrimaContractGateway) WaitForReceipt(ctx context.Context, txHash string, confirmations uint64) error {
+	txHashObj := common.HexToHash(txHash)
+	if txHashObj.Big().Cmp(big.NewInt(0)) == 0 {
+		return errors.New("invalid transaction hash format")
+	}
+
+	_, err := txwait.WaitForReceipt(ctx, g.client, txHashObj, confirmations)
+	return err
+}
+
 // VerifyTransaction はトランザクションを検証
 func (g *FrimaContractGateway) VerifyTransaction(ctx context.Context, txHash string) (*model.TxVerification, error) {
 	txHashObj := common.HexToHash(txHash)
@@ -554,11 +1289,14 @@ func (g *FrimaContractGateway) VerifyTransaction(ctx context.Context, txHash str
 	}
 
 	if isPending {
-		return &model.TxVerification{
+		verification := &model.TxVerification{
 			TxHash:  txHash,
 			Status:  "pending",
 			Success: false,
-		}, nil
+			TxType:  tx.Type(),
+		}
+		verification.MethodName, verification.MethodArgs = g.decodeCall(tx)
+		return verification, nil
 	}
 
 	receipt, err := g.client.TransactionReceipt(ctx, txHashObj)
@@ -571,18 +1309,141 @@ func (g *FrimaContractGateway) VerifyTransaction(ctx context.Context, txHash str
 		BlockNumber: receipt.BlockNumber.Uint64(),
 		GasUsed:     receipt.GasUsed,
 		Success:     receipt.Status == types.ReceiptStatusSuccessful,
+		TxType:      tx.Type(),
+	}
+
+	// ブロックのタイムスタンプとベースフィー（実効ガス単価の補完に使う）
+	var baseFee *big.Int
+	header, err := g.client.HeaderByNumber(ctx, receipt.BlockNumber)
+	if err != nil {
+		log.Printf("WARNING: Failed to get header for block %d: %v", receipt.BlockNumber.Uint64(), err)
+	} else {
+		verification.BlockTimestamp = header.Time
+		baseFee = header.BaseFee
+	}
+
+	// 手数料 = gasUsed * effectiveGasPrice
+	if gasPrice := effectiveGasPrice(tx, receipt, baseFee); gasPrice != nil {
+		verification.EffectiveGasPrice = gasPrice.String()
+		verification.FeeWei = new(big.Int).Mul(gasPrice, new(big.Int).SetUint64(receipt.GasUsed)).String()
 	}
 
 	if receipt.Status == types.ReceiptStatusSuccessful {
 		verification.Status = "success"
 	} else {
 		verification.Status = "failed"
+		verification.RevertReason = g.revertReason(ctx, tx, receipt.BlockNumber)
 	}
 
 	// コントラクト呼び出しかどうかを確認
-	if tx.To() != nil && *tx.To() == g.contractAddress {
-		verification.IsContractCall = true
+	// receive()/fallback で ETH を受け取るコントラクトへの送金は calldata が空になるため、
+	// tx.Data() ではなく送金先にコードが存在するかで判定する
+	if tx.To() != nil {
+		if g.isWatchedAddress(*tx.To()) {
+			verification.IsContractCall = true
+			verification.MethodName, verification.MethodArgs = g.decodeCall(tx)
+		} else {
+			code, err := g.client.CodeAt(ctx, *tx.To(), receipt.BlockNumber)
+			if err != nil {
+				log.Printf("WARNING: Failed to get code for %s: %v", tx.To().Hex(), err)
+			} else {
+				verification.IsContractCall = len(code) > 0
+			}
+		}
 	}
 
 	return verification, nil
 }
+
+// decodeCall はマーケットプレイスコントラクト宛てのトランザクションの calldata から
+// 呼び出した関数名と引数を復元する（buyItem と cancelListing などを区別するため）
+// 監視対象外の宛先・ETHの単純送金（calldata が空）・ABIに無いセレクタの場合は空を返す
+func (g *FrimaContractGateway) decodeCall(tx *types.Transaction) (string, map[string]interface{}) {
+	data := tx.Data()
+	if tx.To() == nil || !g.isWatchedAddress(*tx.To()) || len(data) < 4 {
+		return "", nil
+	}
+
+	method, err := g.contractABI.MethodById(data[:4])
+	if err != nil {
+		log.Printf("WARNING: Unknown method selector %x in tx %s", data[:4], tx.Hash().Hex())
+		return "", nil
+	}
+
+	args := make(map[string]interface{})
+	if err := method.Inputs.UnpackIntoMap(args, data[4:]); err != nil {
+		log.Printf("WARNING: Failed to decode %s arguments in tx %s: %v", method.Name, tx.Hash().Hex(), err)
+		return method.Name, nil
+	}
+	// uint256 は JSON の数値で精度が落ちないよう10進数文字列にする
+	for name, value := range args {
+		if v, ok := value.(*big.Int); ok {
+			args[name] = v.String()
+		}
+	}
+	return method.Name, args
+}
+
+// effectiveGasPrice は実際に支払われたガス単価を返す
+// 通常はレシートの effectiveGasPrice を使い、ノードが返さない場合はトランザクションから算出する
+// legacy (type 0/1) はガス単価そのもの、EIP-1559 (type 2) は min(feeCap, baseFee + tipCap)
+func effectiveGasPrice(tx *types.Transaction, receipt *types.Receipt, baseFee *big.Int) *big.Int {
+	if receipt.EffectiveGasPrice != nil && receipt.EffectiveGasPrice.Sign() > 0 {
+		return receipt.EffectiveGasPrice
+	}
+
+	switch tx.Type() {
+	case types.LegacyTxType, types.AccessListTxType:
+		return tx.GasPrice()
+	default:
+		if baseFee == nil {
+			return nil
+		}
+		price := new(big.Int).Add(baseFee, tx.GasTipCap())
+		if price.Cmp(tx.GasFeeCap()) > 0 {
+			price.Set(tx.GasFeeCap())
+		}
+		return price
+	}
+}
+
+// DecodeTransactionLogs はレシートの各ログを parseLog に通し、デコード結果とデコードできなかったログを返す
+// 本番のイベント処理には影響しない（調査用）
+func (g *FrimaContractGateway) DecodeTransactionLogs(ctx context.Context, txHash string) (*model.DecodedTxLogs, error) {
+	txHashObj := common.HexToHash(txHash)
+	if txHashObj.Big().Cmp(big.NewInt(0)) == 0 {
+		return nil, errors.New("invalid transaction hash format")
+	}
+
+	receipt, err := g.client.TransactionReceipt(ctx, txHashObj)
+	if err != nil {
+		return nil, errors.New("failed to get transaction receipt")
+	}
+
+	result := &model.DecodedTxLogs{
+		TxHash:    txHash,
+		Events:    []*model.ContractEvent{},
+		Unmatched: []model.RawLog{},
+	}
+	for _, vLog := range receipt.Logs {
+		// 監視対象外のコントラクトのログは parseLog に渡さない（不一致の警告ログを出さないため）
+		if g.isWatchedAddress(vLog.Address) {
+			if event := g.parseLog(*vLog); event != nil && event.Type != model.EventUnknown {
+				result.Events = append(result.Events, event)
+				continue
+			}
+		}
+
+		topics := make([]string, 0, len(vLog.Topics))
+		for _, topic := range vLog.Topics {
+			topics = append(topics, topic.Hex())
+		}
+		result.Unmatched = append(result.Unmatched, model.RawLog{
+			Address:  vLog.Address.Hex(),
+			LogIndex: vLog.Index,
+			Topics:   topics,
+			Data:     hexutil.Encode(vLog.Data),
+		})
+	}
+	return result, nil
+}
@@ -0,0 +1,94 @@
+package contract
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/ethereum/go-ethereum/ethclient"
+)
+
+// newStalledGateway は応答を返さないノードに接続したゲートウェイを作成する
+func newStalledGateway(t *testing.T, callTimeout time.Duration) *FrimaContractGateway {
+	t.Helper()
+	stop := make(chan struct{})
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		select {
+		case <-r.Context().Done():
+		case <-stop:
+		}
+	}))
+	t.Cleanup(func() {
+		close(stop)
+		srv.Close()
+	})
+
+	client, err := ethclient.Dial(srv.URL)
+	if err != nil {
+		t.Fatalf("Dial: %v", err)
+	}
+	t.Cleanup(client.Close)
+
+	g, err := NewFrimaContractGateway(client, nil, []string{"0x00000000000000000000000000000000000000aa"}, 0, 0, PollConfig{}, callTimeout, EventBufferConfig{}, 0)
+	if err != nil {
+		t.Fatalf("NewFrimaContractGateway: %v", err)
+	}
+	return g
+}
+
+func TestReadCallsTimeOut(t *testing.T) {
+	const callTimeout = 50 * time.Millisecond
+	g := newStalledGateway(t, callTimeout)
+
+	calls := map[string]func(ctx context.Context) error{
+		"GetItem": func(ctx context.Context) error {
+			_, err := g.GetItem(ctx, 1)
+			return err
+		},
+		"GetItemCount": func(ctx context.Context) error {
+			_, err := g.GetItemCount(ctx)
+			return err
+		},
+		"GetLatestBlock": func(ctx context.Context) error {
+			_, err := g.GetLatestBlock(ctx)
+			return err
+		},
+		"GetChainID": func(ctx context.Context) error {
+			_, err := g.GetChainID(ctx)
+			return err
+		},
+	}
+	for name, call := range calls {
+		t.Run(name, func(t *testing.T) {
+			start := time.Now()
+			err := call(context.Background())
+			if !errors.Is(err, ErrCallTimeout) {
+				t.Fatalf("err = %v, want ErrCallTimeout", err)
+			}
+			if elapsed := time.Since(start); elapsed > 2*time.Second {
+				t.Errorf("call took %v, want about %v", elapsed, callTimeout)
+			}
+		})
+	}
+}
+
+func TestCallerCancellationIsNotReportedAsTimeout(t *testing.T) {
+	g := newStalledGateway(t, time.Minute)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	time.AfterFunc(20*time.Millisecond, cancel)
+	_, err := g.GetItem(ctx, 1)
+	if err == nil || errors.Is(err, ErrCallTimeout) {
+		t.Fatalf("err = %v, want a non-timeout error", err)
+	}
+}
+
+func TestDefaultCallTimeout(t *testing.T) {
+	g := newStalledGateway(t, 0)
+	if g.callTimeout != DefaultCallTimeout {
+		t.Errorf("callTimeout = %v, want %v", g.callTimeout, DefaultCallTimeout)
+	}
+}
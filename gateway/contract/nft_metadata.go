@@ -0,0 +1,229 @@
+package contract
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"math/big"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/common"
+
+	"uttc-hack-back-onchain/model"
+)
+
+// ErrTokenNotFound は tokenURI がリバートした（トークンが存在しない、またはバーン済み）
+var ErrTokenNotFound = errors.New("nft token not found")
+
+// erc721MetadataABI は ERC-721 Metadata 拡張のうち tokenURI のみのABI
+const erc721MetadataABI = `[
+  {
+    "inputs": [{"internalType": "uint256", "name": "tokenId", "type": "uint256"}],
+    "name": "tokenURI",
+    "outputs": [{"internalType": "string", "name": "", "type": "string"}],
+    "stateMutability": "view",
+    "type": "function"
+  }
+]`
+
+const (
+	// ipfsGatewayURL は ipfs:// のURIを取得する際に使う公開ゲートウェイ
+	ipfsGatewayURL = "https://ipfs.io/ipfs/"
+	// metadataFetchTimeout はメタデータJSONの取得のタイムアウト
+	metadataFetchTimeout = 5 * time.Second
+	// maxMetadataBytes はメタデータJSONの最大サイズ
+	maxMetadataBytes = 1 << 20
+)
+
+// GetTokenMetadata はマーケットプレイスの nftContract から tokenURI を読み、メタデータJSONを取得する
+// tokenURI が読めない場合はエラーを返すが、メタデータの取得・解析に失敗した場合は
+// 取得できた項目（tokenURI など）と Error を設定した部分的な結果を返す
+func (g *FrimaContractGateway) GetTokenMetadata(ctx context.Context, tokenId uint64) (*model.NFTMetadata, error) {
+	nftAddr, err := g.nftContract(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	tokenURI, err := g.tokenURI(ctx, nftAddr, tokenId)
+	if err != nil {
+		return nil, err
+	}
+
+	metadata := &model.NFTMetadata{
+		TokenId:     tokenId,
+		NFTContract: nftAddr.Hex(),
+		TokenURI:    tokenURI,
+	}
+	if err := fetchMetadata(ctx, tokenURI, metadata); err != nil {
+		metadata.Error = err.Error()
+	}
+	return metadata, nil
+}
+
+// GetNFTContractAddress はマーケットプレイスが発行する NFT のコントラクトアドレスを返す
+func (g *FrimaContractGateway) GetNFTContractAddress(ctx context.Context) (string, error) {
+	addr, err := g.nftContract(ctx)
+	if err != nil {
+		return "", err
+	}
+	return addr.Hex(), nil
+}
+
+// nftContract はマーケットプレイスの nftContract() を呼び出す
+// コンストラクタで設定され変更されないため、最初に取得できた値をキャッシュする（失敗した場合は次回に再試行）
+func (g *FrimaContractGateway) nftContract(ctx context.Context) (common.Address, error) {
+	g.nftMu.Lock()
+	defer g.nftMu.Unlock()
+	if g.nftAddress != (common.Address{}) {
+		return g.nftAddress, nil
+	}
+
+	data, err := g.contractABI.Pack("nftContract")
+	if err != nil {
+		return common.Address{}, err
+	}
+
+	result, err := g.callContract(ctx, data)
+	if err != nil {
+		if strings.Contains(err.Error(), "execution reverted") {
+			return common.Address{}, fmt.Errorf("%w: %v", ErrContractUnavailable, err)
+		}
+		return common.Address{}, err
+	}
+	if len(result) == 0 {
+		return common.Address{}, fmt.Errorf("%w: marketplace is not deployed at %s", ErrContractUnavailable, g.contractAddress.Hex())
+	}
+
+	values, err := g.contractABI.Unpack("nftContract", result)
+	if err != nil {
+		return common.Address{}, err
+	}
+	addr, ok := values[0].(common.Address)
+	if !ok {
+		return common.Address{}, errors.New("unexpected nftContract result type")
+	}
+	if addr == (common.Address{}) {
+		return common.Address{}, fmt.Errorf("%w: marketplace %s has no nft contract", ErrContractUnavailable, g.contractAddress.Hex())
+	}
+	g.nftAddress = addr
+	return addr, nil
+}
+
+// tokenURI は ERC-721 の tokenURI(tokenId) を呼び出す
+func (g *FrimaContractGateway) tokenURI(ctx context.Context, nftAddr common.Address, tokenId uint64) (string, error) {
+	data, err := g.nftABI.Pack("tokenURI", new(big.Int).SetUint64(tokenId))
+	if err != nil {
+		return "", err
+	}
+
+	callCtx, cancel := g.withCallTimeout(ctx)
+	defer cancel()
+	result, err := g.client.CallContract(callCtx, ethereum.CallMsg{To: &nftAddr, Data: data}, nil)
+	if err != nil {
+		if strings.Contains(err.Error(), "execution reverted") {
+			return "", fmt.Errorf("%w: token %d", ErrTokenNotFound, tokenId)
+		}
+		return "", g.callError(callCtx, err)
+	}
+	if len(result) == 0 {
+		return "", fmt.Errorf("%w: no code at nft contract %s", ErrContractUnavailable, nftAddr.Hex())
+	}
+
+	values, err := g.nftABI.Unpack("tokenURI", result)
+	if err != nil {
+		return "", err
+	}
+	uri, ok := values[0].(string)
+	if !ok {
+		return "", errors.New("unexpected tokenURI result type")
+	}
+	return uri, nil
+}
+
+// fetchMetadata は tokenURI のメタデータJSONを取得し、name・description・image を metadata に設定する
+// data:application/json（オンチェーンのメタデータ）と ipfs:// に対応する
+func fetchMetadata(ctx context.Context, tokenURI string, metadata *model.NFTMetadata) error {
+	var body []byte
+	switch {
+	case tokenURI == "":
+		return errors.New("tokenURI is empty")
+	case strings.HasPrefix(tokenURI, "data:"):
+		decoded, err := decodeDataURI(tokenURI)
+		if err != nil {
+			return err
+		}
+		body = decoded
+	default:
+		fetched, err := fetchURL(ctx, resolveIPFS(tokenURI))
+		if err != nil {
+			return err
+		}
+		body = fetched
+	}
+
+	var raw struct {
+		Name        string `json:"name"`
+		Description string `json:"description"`
+		Image       string `json:"image"`
+	}
+	if err := json.Unmarshal(body, &raw); err != nil {
+		return fmt.Errorf("malformed metadata JSON: %w", err)
+	}
+	metadata.Name = raw.Name
+	metadata.Description = raw.Description
+	metadata.Image = resolveIPFS(raw.Image)
+	return nil
+}
+
+// resolveIPFS は ipfs:// のURIを公開ゲートウェイのURLに変換する（それ以外はそのまま）
+func resolveIPFS(uri string) string {
+	if rest, ok := strings.CutPrefix(uri, "ipfs://"); ok {
+		return ipfsGatewayURL + strings.TrimPrefix(rest, "ipfs/")
+	}
+	return uri
+}
+
+// decodeDataURI は data:application/json[;base64],... 形式のURIの中身を返す
+func decodeDataURI(uri string) ([]byte, error) {
+	header, payload, ok := strings.Cut(strings.TrimPrefix(uri, "data:"), ",")
+	if !ok {
+		return nil, errors.New("malformed data URI")
+	}
+	if strings.HasSuffix(header, ";base64") {
+		decoded, err := base64.StdEncoding.DecodeString(payload)
+		if err != nil {
+			return nil, fmt.Errorf("malformed base64 data URI: %w", err)
+		}
+		return decoded, nil
+	}
+	return []byte(payload), nil
+}
+
+func fetchURL(ctx context.Context, url string) ([]byte, error) {
+	if !strings.HasPrefix(url, "https://") && !strings.HasPrefix(url, "http://") {
+		return nil, fmt.Errorf("unsupported tokenURI scheme: %s", url)
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, metadataFetchTimeout)
+	defer cancel()
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return nil, err
+	}
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch metadata: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("metadata fetch returned status %d", resp.StatusCode)
+	}
+	return io.ReadAll(io.LimitReader(resp.Body, maxMetadataBytes))
+}
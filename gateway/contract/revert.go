@@ -0,0 +1,133 @@
+package contract
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"math/big"
+	"strings"
+
+	"github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/accounts/abi"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/rpc"
+)
+
+// panicSelector は Solidity の Panic(uint256) のセレクタ
+var panicSelector = []byte{0x4e, 0x48, 0x7b, 0x71}
+
+// panicReasons は Panic(uint256) のコードごとの説明
+var panicReasons = map[uint64]string{
+	0x01: "assertion failed",
+	0x11: "arithmetic overflow or underflow",
+	0x12: "division by zero",
+	0x21: "invalid enum value",
+	0x22: "invalid storage byte array",
+	0x31: "pop on empty array",
+	0x32: "array index out of bounds",
+	0x41: "out of memory",
+	0x51: "call to zero-initialized function",
+}
+
+// revertReason は失敗したトランザクションを同じブロックの状態で eth_call し直し、リバート理由を復元する
+// 取り込まれたブロックの直前ではなく直後の状態での再実行のため、同じブロック内の先行トランザクション次第では
+// 実際の理由と異なる（あるいは成功してしまう）ことがある。復元できない場合は空を返す
+func (g *FrimaContractGateway) revertReason(ctx context.Context, tx *types.Transaction, blockNumber *big.Int) string {
+	if tx.To() == nil {
+		return ""
+	}
+
+	var signer types.Signer
+	if tx.Protected() {
+		signer = types.LatestSignerForChainID(tx.ChainId())
+	} else {
+		signer = types.HomesteadSigner{}
+	}
+	from, err := types.Sender(signer, tx)
+	if err != nil {
+		log.Printf("WARNING: Failed to recover sender of %s: %v", tx.Hash().Hex(), err)
+		return ""
+	}
+
+	callCtx, cancel := g.withCallTimeout(ctx)
+	defer cancel()
+
+	_, err = g.client.CallContract(callCtx, ethereum.CallMsg{
+		From:  from,
+		To:    tx.To(),
+		Gas:   tx.Gas(),
+		Value: tx.Value(),
+		Data:  tx.Data(),
+	}, blockNumber)
+	if err == nil {
+		return ""
+	}
+
+	var dataErr rpc.DataError
+	if !errors.As(err, &dataErr) {
+		// リバートデータを返さないノード（または revert() のみ）の場合
+		log.Printf("WARNING: No revert data for %s: %v", tx.Hash().Hex(), err)
+		return ""
+	}
+	hexData, ok := dataErr.ErrorData().(string)
+	if !ok {
+		return ""
+	}
+	data, err := hexutil.Decode(hexData)
+	if err != nil {
+		return ""
+	}
+	return g.decodeRevert(data)
+}
+
+// decodeRevert はリバートデータを Error(string)・Panic(uint256)・コントラクトのカスタムエラーの順に解釈する
+// ABI に無いカスタムエラーはセレクタのみを返す
+func (g *FrimaContractGateway) decodeRevert(data []byte) string {
+	if len(data) < 4 {
+		return ""
+	}
+
+	if reason, err := abi.UnpackRevert(data); err == nil {
+		return reason
+	}
+
+	selector := data[:4]
+	if bytes.Equal(selector, panicSelector) && len(data) >= 36 {
+		code := new(big.Int).SetBytes(data[4:36])
+		if code.IsUint64() {
+			if reason, ok := panicReasons[code.Uint64()]; ok {
+				return fmt.Sprintf("panic: %s (0x%x)", reason, code.Uint64())
+			}
+		}
+		return fmt.Sprintf("panic: 0x%x", code)
+	}
+
+	for name, abiErr := range g.contractABI.Errors {
+		if !bytes.Equal(abiErr.ID[:4], selector) {
+			continue
+		}
+		values, err := abiErr.Unpack(data)
+		if err != nil {
+			return name
+		}
+		args, ok := values.([]interface{})
+		if !ok || len(args) == 0 {
+			return name
+		}
+		parts := make([]string, len(args))
+		for i, arg := range args {
+			if addr, ok := arg.(common.Address); ok {
+				parts[i] = addr.Hex()
+			} else {
+				parts[i] = fmt.Sprint(arg)
+			}
+		}
+		return fmt.Sprintf("%s(%s)", name, strings.Join(parts, ", "))
+	}
+
+	return "unknown custom error " + hexutil.Encode(selector)
+}
@@ -0,0 +1,140 @@
+package contract
+
+import (
+	"context"
+	"errors"
+	"expvar"
+	"log"
+	"time"
+
+	"github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/ethclient"
+
+	"uttc-hack-back-onchain/internal/httpclient"
+)
+
+// イベントの受信方式（EventSourceMode の戻り値）
+const (
+	EventSourceIdle         = "idle"         // 購読前
+	EventSourceSubscription = "subscription" // WebSocket 購読
+	EventSourcePolling      = "polling"      // eth_getLogs のポーリング
+)
+
+const (
+	// wsUpgradeInterval はポーリング中に WebSocket 購読への復帰を試みる間隔
+	wsUpgradeInterval = 1 * time.Minute
+	// wsDialTimeout は WebSocket 接続のタイムアウト
+	wsDialTimeout = 10 * time.Second
+)
+
+// eventSourceMetric は現在のイベント受信方式（/debug/vars で確認できる）
+var eventSourceMetric = expvar.NewString("event_source_mode")
+
+// EventSourceMode は現在のイベント受信方式を返す（ヘルスチェック・メトリクス用）
+func (g *FrimaContractGateway) EventSourceMode() string {
+	g.wsMu.Lock()
+	defer g.wsMu.Unlock()
+	return g.mode
+}
+
+// setMode はイベント受信方式を記録し、切り替わった場合はログに残す
+func (g *FrimaContractGateway) setMode(mode string) {
+	g.wsMu.Lock()
+	defer g.wsMu.Unlock()
+
+	if g.mode != mode {
+		log.Printf("Event source mode changed: %s -> %s", g.mode, mode)
+		g.mode = mode
+		eventSourceMetric.Set(mode)
+	}
+}
+
+// WSReconnectCount は WebSocket 購読を張り直した回数を返す
+func (g *FrimaContractGateway) WSReconnectCount() uint64 {
+	g.wsMu.Lock()
+	defer g.wsMu.Unlock()
+	return g.wsReconnects
+}
+
+func (g *FrimaContractGateway) countReconnect() {
+	g.wsMu.Lock()
+	defer g.wsMu.Unlock()
+	g.wsReconnects++
+}
+
+// subscribeLogs は WebSocket 接続（未接続なら接続する）で監視対象のログを購読する
+// 失敗した場合は接続を破棄し、次回の呼び出しで接続し直す
+func (g *FrimaContractGateway) subscribeLogs(ctx context.Context) (ethereum.Subscription, chan types.Log, error) {
+	if len(g.wsURLs) == 0 {
+		return nil, nil, errors.New("websocket url not configured")
+	}
+
+	g.wsMu.Lock()
+	client := g.wsClient
+	index := g.wsIndex
+	g.wsMu.Unlock()
+
+	if client == nil {
+		dialCtx, cancel := context.WithTimeout(ctx, wsDialTimeout)
+		defer cancel()
+		c, err := ethclient.DialContext(dialCtx, g.wsURLs[index])
+		if err != nil {
+			g.rotateWSEndpoint(index, err)
+			return nil, nil, err
+		}
+		client = c
+	}
+
+	logs := make(chan types.Log)
+	sub, err := client.SubscribeFilterLogs(ctx, ethereum.FilterQuery{Addresses: g.contractAddresses}, logs)
+	if err != nil {
+		client.Close()
+		g.wsMu.Lock()
+		if g.wsClient == client {
+			g.wsClient = nil
+		}
+		g.wsMu.Unlock()
+		g.rotateWSEndpoint(index, err)
+		return nil, nil, err
+	}
+
+	g.wsMu.Lock()
+	g.wsClient = client
+	g.wsMu.Unlock()
+	return sub, logs, nil
+}
+
+// ActiveWSEndpoint は現在使っている WebSocket のエンドポイントを返す（未設定なら空文字）
+func (g *FrimaContractGateway) ActiveWSEndpoint() string {
+	g.wsMu.Lock()
+	defer g.wsMu.Unlock()
+
+	if len(g.wsURLs) == 0 {
+		return ""
+	}
+	return httpclient.RedactURL(g.wsURLs[g.wsIndex])
+}
+
+// rotateWSEndpoint は index の URL への接続・購読が失敗した場合に次の URL に切り替える
+func (g *FrimaContractGateway) rotateWSEndpoint(index int, err error) {
+	g.wsMu.Lock()
+	defer g.wsMu.Unlock()
+
+	if len(g.wsURLs) > 1 && g.wsIndex == index {
+		g.wsIndex = (index + 1) % len(g.wsURLs)
+		log.Printf("WARNING: WebSocket endpoint %s failed (%v), switching to %s",
+			httpclient.RedactURL(g.wsURLs[index]), err, httpclient.RedactURL(g.wsURLs[g.wsIndex]))
+	}
+}
+
+// dropWSClient は切断された WebSocket 接続を破棄する
+func (g *FrimaContractGateway) dropWSClient() {
+	g.wsMu.Lock()
+	defer g.wsMu.Unlock()
+
+	if g.wsClient != nil {
+		g.wsClient.Close()
+		g.wsClient = nil
+	}
+}
@@ -0,0 +1,69 @@
+package contract
+
+import (
+	"context"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+
+	"uttc-hack-back-onchain/logger"
+	"uttc-hack-back-onchain/model"
+)
+
+// fakeSubscription は Unsubscribe の呼び出しを記録する購読
+type fakeSubscription struct {
+	errc         chan error
+	unsubscribed atomic.Bool
+}
+
+func (s *fakeSubscription) Unsubscribe()      { s.unsubscribed.Store(true) }
+func (s *fakeSubscription) Err() <-chan error { return s.errc }
+
+func TestRunSubscriptionRecoversFromParsePanic(t *testing.T) {
+	const address = "0x00000000000000000000000000000000000000aa"
+	g, err := NewFrimaContractGateway(nil, nil, []string{address}, 0, 0, PollConfig{}, 0, EventBufferConfig{}, 0)
+	if err != nil {
+		t.Fatalf("NewFrimaContractGateway: %v", err)
+	}
+	// ログの解析中のパニックを再現するため、ブロック時刻のキャッシュを壊しておく
+	g.blockTimes = nil
+
+	logs, restore := logger.Capture()
+	defer restore()
+
+	sub := &fakeSubscription{errc: make(chan error)}
+	logCh := make(chan types.Log, 1)
+	eventChan := make(chan *model.ContractEvent, 1)
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		g.runSubscription(context.Background(), eventChan, sub, logCh, 0)
+	}()
+
+	logCh <- types.Log{
+		Address:     common.HexToAddress(address),
+		Topics:      []common.Hash{g.contractABI.Events["ItemCancelled"].ID},
+		BlockNumber: 10,
+	}
+
+	select {
+	case <-done:
+	case <-time.After(5 * time.Second):
+		t.Fatal("runSubscription did not return after a panic")
+	}
+
+	// useCase 側が再購読できるよう、チャネルは閉じられている
+	if _, ok := <-eventChan; ok {
+		t.Error("event channel was not closed")
+	}
+	if !sub.unsubscribed.Load() {
+		t.Error("subscription was not unsubscribed")
+	}
+	if !strings.Contains(logs.String(), "runSubscription panic") {
+		t.Errorf("panic was not logged: %s", logs.String())
+	}
+}
@@ -7,34 +7,95 @@ import (
 	"fmt"
 	"log"
 	"math/big"
+	"net"
 	"net/http"
+	"slices"
+	"strings"
+	"time"
 
+	"github.com/ethereum/go-ethereum"
 	"github.com/ethereum/go-ethereum/common"
 	"github.com/ethereum/go-ethereum/core/types"
 	"github.com/ethereum/go-ethereum/ethclient"
 
+	"uttc-hack-back-onchain/internal/txwait"
 	"uttc-hack-back-onchain/model"
 )
 
 // デモ用: 固定の支払い金額 (0.001 ETH in Wei)
 var DemoPaymentAmount = new(big.Int).Mul(big.NewInt(1), big.NewInt(1e15)) // 0.001 ETH
 
+// 支払い検証の失敗理由（ユースケース・ハンドラーで errors.Is により判別する）
+var (
+	ErrInvalidTxHash      = errors.New("invalid transaction hash format")
+	ErrTxNotFound         = errors.New("transaction not found")
+	ErrTxPending          = errors.New("transaction is still pending")
+	ErrTxReverted         = errors.New("transaction failed on chain (reverted)")
+	ErrInsufficientAmount = errors.New("insufficient payment amount")
+	ErrWrongRecipient     = errors.New("transaction sent to wrong recipient address")
+	ErrWrongSender        = errors.New("transaction sent from a wallet other than the buyer")
+	ErrTxDropped          = txwait.ErrTxDropped
+)
+
+// ErrInvalidExpectedAmount は期待する支払い額が未設定または0以下（商品ごとの金額の設定ミスなど）
+// このまま検証すると0 Weiの送金でも支払い済みになってしまうため、検証自体を拒否する
+var ErrInvalidExpectedAmount = errors.New("expected payment amount must be positive; check the product amount configuration")
+
+// 商品価格の取得失敗の理由
+var (
+	ErrProductNotFound      = errors.New("product not found")
+	ErrVariantNotFound      = errors.New("product variant not found")
+	ErrProductLookupTimeout = errors.New("product lookup timed out")
+)
+
+// productLookupTimeout はバックエンドへの商品価格問い合わせのタイムアウト
+// バックエンドが応答しない場合に注文作成リクエストが止まり続けないようにする
+const productLookupTimeout = 5 * time.Second
+
+const (
+	// productLookupAttempts は商品価格問い合わせの最大試行回数（接続エラー・タイムアウト・5xx のみリトライ）
+	productLookupAttempts = 3
+	// productLookupRetryDelay はリトライ前の待機時間の初期値（試行ごとに2倍）
+	productLookupRetryDelay = 200 * time.Millisecond
+)
+
 // ===============================================
 // 1. インターフェース定義
 // ===============================================
 
 type BlockchainGateway interface {
 	// GetProductPrice は商品の価格（円）を取得する
-	GetProductPrice(productID string) (int, error)
+	// variant が指定された場合はそのバリエーション（SKU）の価格を返す
+	// 商品が存在しない場合は ErrProductNotFound、タイムアウトした場合は ErrProductLookupTimeout を返す
+	GetProductPrice(ctx context.Context, productID string, variant string) (int, error)
 
-	// GetRequiredAmount は支払いに必要なETH量を返す (デモ用: 0.001 ETH固定)
-	GetRequiredAmount() *big.Int
+	// RequiredAmountFor は商品（variant 指定時はそのバリエーション）の支払いに必要なETH量 (Wei) を返す
+	// バリエーションの設定が無い場合は商品の金額、商品の設定も無い場合はデフォルト金額 (DemoPaymentAmount) を返す
+	RequiredAmountFor(productID string, variant string) (*big.Int, error)
 
 	// GetPaymentAddress はアプリの集金用ウォレットアドレスを返す
 	GetPaymentAddress() string
 
+	// ChainID は支払いを受け付けるネットワークのチェーンIDを返す
+	ChainID() uint64
+
 	// CheckPaymentStatus はトランザクションハッシュを受け取り、支払い完了を検証・確定する
-	CheckPaymentStatus(ctx context.Context, txHash string, expectedAddr string, expectedWei *big.Int) (model.OrderStatus, error)
+	// 検証に失敗した場合もステータス（Pending/Error）を含む結果を返す
+	// 失敗理由は ErrTxNotFound などのエラーで返す
+	// expectedSender が空でない場合、トランザクションの送信者がそのアドレスであることも検証する
+	// 直接送金でない場合、InternalTransferFinder が設定されていればコントラクト経由で集金アドレスに届いた額も確認する
+	CheckPaymentStatus(ctx context.Context, txHash string, expectedAddr string, expectedSender string, expectedWei *big.Int) (*model.PaymentCheck, error)
+
+	// InspectPayment は CheckPaymentStatus と同じ検証を途中で打ち切らずに実行し、各チェックの結果を返す
+	// 検証の失敗はエラーではなくレポートに記録する（ノードエラーなどの場合のみエラーを返す）
+	InspectPayment(ctx context.Context, txHash string, expectedAddr string, expectedSender string, expectedWei *big.Int) (*model.PaymentReport, error)
+
+	// WaitForReceipt はトランザクションが confirmations 件の確認を得るまで待つ
+	// マイニングされないまま破棄・置き換えられた場合は ErrTxDropped、ctx が終了した場合は ctx.Err() を返す
+	WaitForReceipt(ctx context.Context, txHash string, confirmations uint64) error
+
+	// GetCollectedBalance は集金用ウォレットの現在の残高 (Wei) を返す
+	GetCollectedBalance(ctx context.Context) (*big.Int, error)
 }
 
 // ===============================================
@@ -43,19 +104,93 @@ type BlockchainGateway interface {
 
 type EthGateway struct {
 	client           *ethclient.Client
-	appCollectWallet common.Address // アプリの集金用ウォレットアドレス
-	backendBaseURL   string         // uttc-hackathon-backend のベースURL
+	httpClient       *http.Client           // バックエンドAPI呼び出し用
+	appCollectWallet common.Address         // アプリの集金用ウォレットアドレス
+	backendBaseURL   string                 // uttc-hackathon-backend のベースURL
+	productAmounts   map[string]*big.Int    // 商品IDごとの支払い金額 (Wei)
+	chainID          uint64                 // 接続先ネットワークのチェーンID（起動時に検証済み）
+	transferFinder   InternalTransferFinder // nil の場合はコントラクト経由の送金を検証しない
+	acceptedTokens   []AcceptedToken        // ETHの代わりに受け付けるトークン（空の場合はETHのみ）
+
+	// 以前の集金用ウォレット（ローテーション前のアドレス宛ての支払いも受け付けるため）
+	previousCollectWallets []common.Address
 }
 
 // NewEthGateway は ethclient.Client を受け取る
-func NewEthGateway(client *ethclient.Client, collectAddr string, backendBaseURL string) *EthGateway {
+// productAmounts は商品IDごとの支払い金額 (Wei)。nil の場合は全商品がデフォルト金額
+// chainID は接続先ネットワークのチェーンID（注文に表示するネットワーク名に使う）
+// transport は商品価格の問い合わせに使う共有トランスポート（nil の場合は http.DefaultTransport）
+// transferFinder はコントラクト経由の支払いを検証する場合のみ指定する（nil の場合は直接送金のみ）
+// previousCollectAddrs はローテーション前の集金アドレス（支払いの受け付けのみに使い、新しい注文には使わない）
+// acceptedTokens はETHの代わりに支払いとして受け付けるトークン（WETH など、空の場合はETHのみ）
+func NewEthGateway(client *ethclient.Client, collectAddr string, previousCollectAddrs []string, backendBaseURL string, productAmounts map[string]*big.Int, chainID uint64, transport http.RoundTripper, transferFinder InternalTransferFinder, acceptedTokens []AcceptedToken) *EthGateway {
+	previous := make([]common.Address, 0, len(previousCollectAddrs))
+	for _, addr := range previousCollectAddrs {
+		previous = append(previous, common.HexToAddress(addr))
+	}
 	return &EthGateway{
 		client:           client,
+		httpClient:       &http.Client{Timeout: productLookupTimeout, Transport: transport},
 		appCollectWallet: common.HexToAddress(collectAddr),
 		backendBaseURL:   backendBaseURL,
+		productAmounts:   productAmounts,
+		chainID:          chainID,
+		transferFinder:   transferFinder,
+		acceptedTokens:   acceptedTokens,
+
+		previousCollectWallets: previous,
 	}
 }
 
+// ParseProductAmounts は "商品ID=Wei,商品ID:バリエーション=Wei" 形式の設定を商品ごとの支払い金額に変換する
+func ParseProductAmounts(value string) (map[string]*big.Int, error) {
+	amounts := make(map[string]*big.Int)
+	for _, pair := range strings.Split(value, ",") {
+		pair = strings.TrimSpace(pair)
+		if pair == "" {
+			continue
+		}
+		productID, weiStr, ok := strings.Cut(pair, "=")
+		if !ok {
+			return nil, fmt.Errorf("invalid product amount %q (expected product_id=wei)", pair)
+		}
+		wei, ok := new(big.Int).SetString(strings.TrimSpace(weiStr), 10)
+		if !ok || wei.Sign() <= 0 {
+			return nil, fmt.Errorf("invalid wei amount for product %s: %q", productID, weiStr)
+		}
+		key := strings.TrimSpace(productID)
+		if id, variant, ok := strings.Cut(key, ":"); ok {
+			key = productAmountKey(strings.TrimSpace(id), strings.TrimSpace(variant))
+		}
+		amounts[key] = wei
+	}
+	return amounts, nil
+}
+
+// productAmountKey は商品ごとの支払い金額のキー（バリエーションは "商品ID:バリエーション"）
+func productAmountKey(productID, variant string) string {
+	if variant == "" {
+		return productID
+	}
+	return productID + ":" + variant
+}
+
+// ParseCollectAddresses はカンマ区切りのアドレス一覧を検証して返す（空の場合は nil）
+func ParseCollectAddresses(value string) ([]string, error) {
+	var addrs []string
+	for _, addr := range strings.Split(value, ",") {
+		addr = strings.TrimSpace(addr)
+		if addr == "" {
+			continue
+		}
+		if !common.IsHexAddress(addr) {
+			return nil, fmt.Errorf("invalid address %q", addr)
+		}
+		addrs = append(addrs, common.HexToAddress(addr).Hex())
+	}
+	return addrs, nil
+}
+
 // ItemResponse はバックエンドからの商品レスポンス
 type ItemResponse struct {
 	ID          int      `json:"id"`
@@ -68,36 +203,116 @@ type ItemResponse struct {
 	Category    string   `json:"category"`
 	LikeCount   int      `json:"like_count"`
 	CreatedAt   string   `json:"created_at"`
+	// Variants は価格違いのバリエーション（バックエンドが返さない場合は空）
+	Variants []VariantResponse `json:"variants,omitempty"`
+}
+
+// VariantResponse は商品バリエーション（SKU）ごとの価格
+type VariantResponse struct {
+	SKU   string `json:"sku"`
+	Price int    `json:"price"`
 }
 
 // GetProductPrice は商品IDからバックエンドAPIを呼び出し、価格（円）を取得する
-func (g *EthGateway) GetProductPrice(productID string) (int, error) {
+// variant が空の場合は商品本体の価格を返す（従来と同じ挙動）
+// バックエンドの一時的な障害で注文作成が失敗しないよう、接続エラー・タイムアウト・5xx は指数バックオフでリトライする
+func (g *EthGateway) GetProductPrice(ctx context.Context, productID string, variant string) (int, error) {
+	var item *ItemResponse
+	delay := productLookupRetryDelay
+	for attempt := 1; ; attempt++ {
+		var retryable bool
+		var err error
+		item, retryable, err = g.fetchProduct(ctx, productID)
+		if err == nil {
+			break
+		}
+		if !retryable || attempt >= productLookupAttempts {
+			return 0, err
+		}
+		// 待機後に ctx の期限を過ぎる場合はリトライしない
+		if deadline, ok := ctx.Deadline(); ok && time.Until(deadline) <= delay {
+			return 0, err
+		}
+		log.Printf("WARNING: Product lookup for %s failed (attempt %d/%d), retrying in %v: %v", productID, attempt, productLookupAttempts, delay, err)
+		select {
+		case <-ctx.Done():
+			return 0, err
+		case <-time.After(delay):
+		}
+		delay *= 2
+	}
+
+	if variant != "" {
+		for _, v := range item.Variants {
+			if v.SKU == variant {
+				log.Printf("Product %s (%s): %s - %d JPY", productID, variant, item.Title, v.Price)
+				return v.Price, nil
+			}
+		}
+		log.Printf("Variant %s not found for product %s", variant, productID)
+		return 0, ErrVariantNotFound
+	}
+
+	log.Printf("Product %s: %s - %d JPY", productID, item.Title, item.Price)
+	return item.Price, nil
+}
+
+// fetchProduct はバックエンドから商品を1回取得する
+// retryable は接続エラー・タイムアウト・5xx のように再試行で回復し得る失敗の場合に true
+func (g *EthGateway) fetchProduct(ctx context.Context, productID string) (*ItemResponse, bool, error) {
 	url := fmt.Sprintf("%s/getItems/%s", g.backendBaseURL, productID)
-	resp, err := http.Get(url)
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return nil, false, fmt.Errorf("failed to create product request: %w", err)
+	}
+
+	resp, err := g.httpClient.Do(req)
 	if err != nil {
 		log.Printf("Error fetching product %s: %v", productID, err)
-		return 0, errors.New("failed to fetch product information")
+		// 呼び出し元の ctx が終了している場合はリトライしない
+		retryable := ctx.Err() == nil
+		// ctx の期限切れ・クライアントのタイムアウトのどちらも同じエラーとして扱う
+		var netErr net.Error
+		if errors.Is(err, context.DeadlineExceeded) || (errors.As(err, &netErr) && netErr.Timeout()) {
+			return nil, retryable, ErrProductLookupTimeout
+		}
+		return nil, retryable, errors.New("failed to fetch product information")
 	}
 	defer resp.Body.Close()
 
+	if resp.StatusCode == http.StatusNotFound {
+		log.Printf("Product %s not found", productID)
+		return nil, false, ErrProductNotFound
+	}
 	if resp.StatusCode != http.StatusOK {
 		log.Printf("Backend returned status %d for product %s", resp.StatusCode, productID)
-		return 0, errors.New("product not found")
+		return nil, resp.StatusCode >= 500, fmt.Errorf("failed to fetch product information: backend returned status %d", resp.StatusCode)
 	}
 
 	var item ItemResponse
 	if err := json.NewDecoder(resp.Body).Decode(&item); err != nil {
 		log.Printf("Error decoding product response: %v", err)
-		return 0, errors.New("failed to parse product information")
+		return nil, false, errors.New("failed to parse product information")
 	}
+	return &item, false, nil
+}
 
-	log.Printf("Product %s: %s - %d JPY", productID, item.Title, item.Price)
-	return item.Price, nil
+// RequiredAmountFor はバリエーション・商品の順に設定された金額を探し、無ければデモ用の固定金額 (0.001 ETH) を返す
+func (g *EthGateway) RequiredAmountFor(productID string, variant string) (*big.Int, error) {
+	if variant != "" {
+		if amount, ok := g.productAmounts[productAmountKey(productID, variant)]; ok {
+			return new(big.Int).Set(amount), nil
+		}
+	}
+	if amount, ok := g.productAmounts[productID]; ok {
+		return new(big.Int).Set(amount), nil
+	}
+	return new(big.Int).Set(DemoPaymentAmount), nil
 }
 
-// GetRequiredAmount はデモ用の固定金額を返す (0.001 ETH)
-func (g *EthGateway) GetRequiredAmount() *big.Int {
-	return DemoPaymentAmount
+// ChainID (接続先ネットワークのチェーンIDを返す)
+func (g *EthGateway) ChainID() uint64 {
+	return g.chainID
 }
 
 // GetPaymentAddress (集金アドレスを文字列で返す)
@@ -105,50 +320,309 @@ func (g *EthGateway) GetPaymentAddress() string {
 	return g.appCollectWallet.Hex()
 }
 
+// acceptedRecipients は expectedAddr 宛ての支払いとして受け付ける送金先を返す
+// expectedAddr が集金用ウォレット（現在または以前のもの）の場合は、集金用ウォレットのいずれでもよい
+func (g *EthGateway) acceptedRecipients(expectedAddr string) []common.Address {
+	expected := common.HexToAddress(expectedAddr)
+	wallets := append([]common.Address{g.appCollectWallet}, g.previousCollectWallets...)
+	if slices.Contains(wallets, expected) {
+		return wallets
+	}
+	return []common.Address{expected}
+}
+
 // CheckPaymentStatus はETH送金トランザクションを検証する
-func (g *EthGateway) CheckPaymentStatus(ctx context.Context, txHash string, expectedAddr string, expectedWei *big.Int) (model.OrderStatus, error) {
+func (g *EthGateway) CheckPaymentStatus(ctx context.Context, txHash string, expectedAddr string, expectedSender string, expectedWei *big.Int) (*model.PaymentCheck, error) {
+	check := &model.PaymentCheck{Status: model.StatusError}
+
+	if expectedWei == nil || expectedWei.Sign() <= 0 {
+		log.Printf("ERROR: Refusing to verify payment %s with expected amount %v", txHash, expectedWei)
+		return check, ErrInvalidExpectedAmount
+	}
+
 	// 1. TxHashを検証可能な型に変換
 	txHashObj := common.HexToHash(txHash)
 	if txHashObj.Big().Cmp(big.NewInt(0)) == 0 {
-		return model.StatusError, errors.New("invalid transaction hash format")
+		return check, ErrInvalidTxHash
 	}
 
-	expectedAddrObj := common.HexToAddress(expectedAddr)
+	recipients := g.acceptedRecipients(expectedAddr)
 
 	// 2. トランザクションが存在するか、Pendingでないかを確認
 	tx, isPending, err := g.client.TransactionByHash(ctx, txHashObj)
 	if err != nil {
 		log.Printf("Error retrieving transaction %s: %v", txHash, err)
-		return model.StatusError, errors.New("transaction not found or node error")
+		if errors.Is(err, ethereum.NotFound) {
+			return check, ErrTxNotFound
+		}
+		return check, fmt.Errorf("failed to get transaction: %w", err)
 	}
 	if isPending {
-		return model.StatusPending, errors.New("transaction is still pending")
+		check.Status = model.StatusPending
+		return check, ErrTxPending
 	}
 
 	// 3. レシートを取得し、Txが成功したかを確認
 	receipt, err := g.client.TransactionReceipt(ctx, txHashObj)
 	if err != nil {
 		log.Printf("Error retrieving receipt for transaction %s: %v", txHash, err)
-		return model.StatusError, errors.New("failed to get transaction receipt")
+		return check, errors.New("failed to get transaction receipt")
 	}
 	if receipt.Status != types.ReceiptStatusSuccessful {
-		return model.StatusError, errors.New("transaction failed on chain (reverted)")
+		return check, ErrTxReverted
+	}
+
+	// 4-5. 送金額と送金先の検証
+	var recipient common.Address
+	// 直接送金の条件を満たさない場合は、コントラクト経由（内部トランザクション）で集金アドレスに届いた額を確認し、
+	// それも無ければ受け付けるトークン（WETH など）の Transfer イベントを確認する
+	paidWei := txValue(tx)
+	asset := NativeAsset
+	if err := checkDirectTransfer(tx, recipients, expectedWei); err == nil {
+		recipient = *tx.To()
+	} else if internal, to, ok := g.findInternalTransferToAny(ctx, txHashObj, recipients, expectedWei); ok {
+		log.Printf("Payment received via internal transfer: %s Wei to %s (tx: %s)", internal.String(), to.Hex(), txHash)
+		paidWei, recipient = internal, to
+	} else if amount, token, to, ok := g.findTokenTransfer(receipt, recipients, expectedSender, expectedWei); ok {
+		log.Printf("Payment received in %s (%s): %s Wei equivalent to %s (tx: %s)", token.Symbol, token.Address.Hex(), amount.String(), to.Hex(), txHash)
+		paidWei, recipient, asset = amount, to, token.Symbol
+	} else {
+		return check, err
+	}
+	if recipient != common.HexToAddress(expectedAddr) {
+		log.Printf("Payment landed on collection wallet %s instead of %s (tx: %s)", recipient.Hex(), expectedAddr, txHash)
 	}
 
-	// 4. 送金額 (Value) の検証 - 期待額以上であればOK
-	if tx.Value().Cmp(expectedWei) < 0 {
-		log.Printf("Insufficient payment: got %s, expected %s", tx.Value().String(), expectedWei.String())
-		return model.StatusError, errors.New("insufficient payment amount")
+	// 6. 送信者 (From Address) の検証（購入者ウォレットが分かっている場合のみ）
+	if expectedSender != "" {
+		sender, err := g.recoverSender(ctx, tx)
+		if err != nil {
+			return check, err
+		}
+		if sender != common.HexToAddress(expectedSender) {
+			log.Printf("Sender mismatch: got %s, expected %s", sender.Hex(), expectedSender)
+			return check, ErrWrongSender
+		}
+	}
+
+	log.Printf("Payment verified: %s Wei to %s", paidWei.String(), recipient.Hex())
+	check.Status = model.StatusPaid
+	check.PaidWei = paidWei
+	check.Asset = asset
+	return check, nil
+}
+
+// txValue は送金額を返す（nil の場合は0として扱う）
+func txValue(tx *types.Transaction) *big.Int {
+	if value := tx.Value(); value != nil {
+		return value
+	}
+	return new(big.Int)
+}
+
+// checkDirectTransfer は tx.Value と tx.To が受け付ける送金先への期待額以上の直接送金か検証する
+func checkDirectTransfer(tx *types.Transaction, recipients []common.Address, expectedWei *big.Int) error {
+	// 送金額 (Value) の検証 - 期待額以上であればOK
+	if value := txValue(tx); value.Cmp(expectedWei) < 0 {
+		log.Printf("Insufficient payment: got %s, expected %s", value.String(), expectedWei.String())
+		return ErrInsufficientAmount
 	}
 
-	// 5. 送金先アドレス (To Address) の検証
+	// 送金先アドレス (To Address) の検証
 	if tx.To() == nil {
-		return model.StatusError, errors.New("transaction is not a transfer to a valid address")
+		return fmt.Errorf("%w: contract creation transaction", ErrWrongRecipient)
 	}
-	if *tx.To() != expectedAddrObj {
-		return model.StatusError, errors.New("transaction sent to wrong recipient address")
+	if !slices.Contains(recipients, *tx.To()) {
+		return ErrWrongRecipient
+	}
+	return nil
+}
+
+// findInternalTransfer は transferFinder で内部送金の合計を取得する
+// 未設定、またはノードが trace 系のメソッドを提供していない場合は false（直接送金の検証結果を使う）
+func (g *EthGateway) findInternalTransfer(ctx context.Context, txHash common.Hash, to common.Address) (*big.Int, bool) {
+	if g.transferFinder == nil {
+		return nil, false
+	}
+	amount, err := g.transferFinder.InternalTransferTo(ctx, txHash, to)
+	if err != nil {
+		log.Printf("WARNING: Failed to look up internal transfers for %s: %v", txHash.Hex(), err)
+		return nil, false
+	}
+	return amount, true
+}
+
+// findInternalTransferToAny は受け付ける送金先のうち、内部送金で期待額以上を受け取ったものを探す
+func (g *EthGateway) findInternalTransferToAny(ctx context.Context, txHash common.Hash, recipients []common.Address, expectedWei *big.Int) (*big.Int, common.Address, bool) {
+	for _, to := range recipients {
+		amount, ok := g.findInternalTransfer(ctx, txHash, to)
+		if !ok {
+			return nil, common.Address{}, false
+		}
+		if amount.Cmp(expectedWei) >= 0 {
+			return amount, to, true
+		}
+	}
+	return nil, common.Address{}, false
+}
+
+// GetCollectedBalance は最新ブロック時点の集金用ウォレットの残高を返す
+func (g *EthGateway) GetCollectedBalance(ctx context.Context) (*big.Int, error) {
+	balance, err := g.client.BalanceAt(ctx, g.appCollectWallet, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get balance of %s: %w", g.appCollectWallet.Hex(), err)
+	}
+	return balance, nil
+}
+
+// WaitForReceipt は txwait.WaitForReceipt でレシートと確認数をポーリングする
+func (g *EthGateway) WaitForReceipt(ctx context.Context, txHash string, confirmations uint64) error {
+	txHashObj := common.HexToHash(txHash)
+	if txHashObj.Big().Cmp(big.NewInt(0)) == 0 {
+		return ErrInvalidTxHash
+	}
+
+	_, err := txwait.WaitForReceipt(ctx, g.client, txHashObj, confirmations)
+	return err
+}
+
+// recoverSender は接続先チェーンの署名方式でトランザクションの送信者を復元する
+func (g *EthGateway) recoverSender(ctx context.Context, tx *types.Transaction) (common.Address, error) {
+	chainID, err := g.client.ChainID(ctx)
+	if err != nil {
+		return common.Address{}, fmt.Errorf("failed to get chain id: %w", err)
+	}
+	sender, err := types.Sender(types.LatestSignerForChainID(chainID), tx)
+	if err != nil {
+		return common.Address{}, fmt.Errorf("failed to recover sender: %w", err)
+	}
+	return sender, nil
+}
+
+// InspectPayment は支払いトランザクションを検証し、チェックごとの結果と確認数をレポートにまとめる
+func (g *EthGateway) InspectPayment(ctx context.Context, txHash string, expectedAddr string, expectedSender string, expectedWei *big.Int) (*model.PaymentReport, error) {
+	if expectedWei == nil || expectedWei.Sign() <= 0 {
+		return nil, ErrInvalidExpectedAmount
+	}
+	report := &model.PaymentReport{
+		TxHash:        txHash,
+		ExpectedWei:   expectedWei.String(),
+		Status:        model.StatusDryRun,
+		WouldBeStatus: model.StatusError,
+	}
+	add := func(name string, passed bool, detail string) {
+		report.Checks = append(report.Checks, model.PaymentCheckItem{Name: name, Passed: passed, Detail: detail})
+	}
+	skip := func(names ...string) {
+		for _, name := range names {
+			report.Checks = append(report.Checks, model.PaymentCheckItem{Name: name, Skipped: true})
+		}
+	}
+
+	// 1. TxHashの形式
+	txHashObj := common.HexToHash(txHash)
+	if txHashObj.Big().Sign() == 0 {
+		add("tx_hash_format", false, ErrInvalidTxHash.Error())
+		skip("tx_found", "tx_mined", "tx_succeeded", "amount_sufficient", "recipient_matches", "sender_matches")
+		return report, nil
+	}
+	add("tx_hash_format", true, "")
+
+	// 2. トランザクションの存在とPending
+	tx, isPending, err := g.client.TransactionByHash(ctx, txHashObj)
+	if err != nil {
+		if !errors.Is(err, ethereum.NotFound) {
+			return nil, fmt.Errorf("failed to get transaction: %w", err)
+		}
+		add("tx_found", false, ErrTxNotFound.Error())
+		skip("tx_mined", "tx_succeeded", "amount_sufficient", "recipient_matches", "sender_matches")
+		return report, nil
+	}
+	add("tx_found", true, "")
+	value := txValue(tx)
+	report.PaidWei = value.String()
+	if tx.To() != nil {
+		report.Recipient = tx.To().Hex()
+	}
+
+	// 3. 金額・送金先・送信者はPendingでも検証できる
+	amountOK := value.Cmp(expectedWei) >= 0
+	add("amount_sufficient", amountOK, fmt.Sprintf("paid %s Wei, expected %s Wei", value.String(), expectedWei.String()))
+
+	recipients := g.acceptedRecipients(expectedAddr)
+	recipientOK := tx.To() != nil && slices.Contains(recipients, *tx.To())
+	add("recipient_matches", recipientOK, fmt.Sprintf("recipient %s, expected %s", report.Recipient, expectedAddr))
+
+	senderOK := true
+	sender, err := g.recoverSender(ctx, tx)
+	if err != nil {
+		return nil, err
+	}
+	report.Sender = sender.Hex()
+	if expectedSender == "" {
+		report.Checks = append(report.Checks, model.PaymentCheckItem{Name: "sender_matches", Skipped: true, Detail: "no buyer wallet recorded"})
+	} else {
+		senderOK = sender == common.HexToAddress(expectedSender)
+		add("sender_matches", senderOK, fmt.Sprintf("sender %s, expected %s", sender.Hex(), expectedSender))
 	}
 
-	log.Printf("Payment verified: %s Wei to %s", tx.Value().String(), expectedAddr)
-	return model.StatusPaid, nil
+	// 4. マイニング済みか・成功したか
+	if isPending {
+		add("tx_mined", false, ErrTxPending.Error())
+		skip("tx_succeeded")
+		report.WouldBeStatus = model.StatusPending
+		return report, nil
+	}
+	add("tx_mined", true, "")
+
+	receipt, err := g.client.TransactionReceipt(ctx, txHashObj)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get transaction receipt: %w", err)
+	}
+	report.BlockNumber = receipt.BlockNumber.Uint64()
+	if latest, err := g.client.BlockNumber(ctx); err != nil {
+		log.Printf("WARNING: Failed to get latest block for confirmations: %v", err)
+	} else if latest >= report.BlockNumber {
+		report.Confirmations = latest - report.BlockNumber + 1
+	}
+
+	succeeded := receipt.Status == types.ReceiptStatusSuccessful
+	detail := ""
+	if !succeeded {
+		detail = ErrTxReverted.Error()
+	}
+	add("tx_succeeded", succeeded, detail)
+
+	// 直接送金の条件を満たさない場合は、確定時と同様にコントラクト経由の送金を確認する
+	if succeeded && (!amountOK || !recipientOK) && g.transferFinder != nil {
+		internal, to, found := g.findInternalTransferToAny(ctx, txHashObj, recipients, expectedWei)
+		detail := fmt.Sprintf("no internal transfer of %s Wei to %s", expectedWei.String(), expectedAddr)
+		if found {
+			detail = fmt.Sprintf("internal transfer %s Wei to %s, expected %s Wei", internal.String(), to.Hex(), expectedWei.String())
+		}
+		add("internal_transfer", found, detail)
+		if found {
+			amountOK, recipientOK = true, true
+			report.PaidWei = internal.String()
+		}
+	}
+	// それでも満たさない場合は、受け付けるトークンの Transfer イベントを確認する
+	if succeeded && (!amountOK || !recipientOK) && len(g.acceptedTokens) > 0 {
+		amount, token, to, found := g.findTokenTransfer(receipt, recipients, expectedSender, expectedWei)
+		detail := fmt.Sprintf("no accepted token transfer of %s Wei equivalent to %s", expectedWei.String(), expectedAddr)
+		if found {
+			detail = fmt.Sprintf("%s transfer %s Wei equivalent to %s, expected %s Wei", token.Symbol, amount.String(), to.Hex(), expectedWei.String())
+		}
+		add("token_transfer", found, detail)
+		if found {
+			amountOK, recipientOK = true, true
+			report.PaidWei = amount.String()
+			report.Asset = token.Symbol
+		}
+	}
+
+	if succeeded && amountOK && recipientOK && senderOK {
+		report.WouldBeStatus = model.StatusPaid
+	}
+	return report, nil
 }
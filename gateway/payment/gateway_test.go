@@ -0,0 +1,51 @@
+package gateway
+
+import (
+	"context"
+	"errors"
+	"math/big"
+	"testing"
+
+	"uttc-hack-back-onchain/model"
+)
+
+const testTxHash = "0x5c504ed432cb51138bcf09aa5e8a410dd4a1e204ef84bfed1be16dfba1b22060"
+
+func TestCheckPaymentStatusRejectsNonPositiveExpectedAmount(t *testing.T) {
+	// ノードへの問い合わせ前に拒否されること（client が nil でも呼ばれない）
+	g := &EthGateway{}
+	for name, expected := range map[string]*big.Int{
+		"nil":      nil,
+		"zero":     big.NewInt(0),
+		"negative": big.NewInt(-1),
+	} {
+		t.Run(name, func(t *testing.T) {
+			check, err := g.CheckPaymentStatus(context.Background(), testTxHash, testCollect.Hex(), "", expected)
+			if !errors.Is(err, ErrInvalidExpectedAmount) {
+				t.Fatalf("err = %v, want ErrInvalidExpectedAmount", err)
+			}
+			if check == nil || check.Status == model.StatusPaid {
+				t.Fatalf("check = %+v, must not be paid", check)
+			}
+		})
+	}
+}
+
+func TestInspectPaymentRejectsNonPositiveExpectedAmount(t *testing.T) {
+	g := &EthGateway{}
+	for name, expected := range map[string]*big.Int{
+		"nil":      nil,
+		"zero":     big.NewInt(0),
+		"negative": big.NewInt(-1),
+	} {
+		t.Run(name, func(t *testing.T) {
+			report, err := g.InspectPayment(context.Background(), testTxHash, testCollect.Hex(), "", expected)
+			if !errors.Is(err, ErrInvalidExpectedAmount) {
+				t.Fatalf("err = %v, want ErrInvalidExpectedAmount", err)
+			}
+			if report != nil {
+				t.Fatalf("report = %+v, want nil", report)
+			}
+		})
+	}
+}
@@ -0,0 +1,121 @@
+package gateway
+
+import (
+	"context"
+	"fmt"
+	"math/big"
+	"slices"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/rpc"
+)
+
+// InternalTransferFinder はコントラクト経由（ルーターなど）の支払いで、トランザクションの実行中に
+// 集金アドレスへ送られたETH（内部トランザクション）を探す。
+// tx.Value / tx.To による直接送金の検証に失敗した場合のフォールバックとしてのみ使う
+type InternalTransferFinder interface {
+	// InternalTransferTo は txHash の実行中に to へ送られたETHの合計を返す（無い場合は0）
+	InternalTransferTo(ctx context.Context, txHash common.Hash, to common.Address) (*big.Int, error)
+}
+
+// TraceTransferFinder は trace_transaction（Erigon・Nethermind などの trace API）で内部送金を探す
+// ノードが trace_transaction を提供している必要がある（Infura ではプランによって利用可能）
+type TraceTransferFinder struct {
+	client *rpc.Client
+}
+
+func NewTraceTransferFinder(client *rpc.Client) *TraceTransferFinder {
+	return &TraceTransferFinder{client: client}
+}
+
+type traceEntry struct {
+	Type         string `json:"type"`
+	Error        string `json:"error"`
+	TraceAddress []int  `json:"traceAddress"`
+	Action       struct {
+		CallType string          `json:"callType"`
+		To       *common.Address `json:"to"`
+		Value    *hexutil.Big    `json:"value"`
+	} `json:"action"`
+}
+
+func (f *TraceTransferFinder) InternalTransferTo(ctx context.Context, txHash common.Hash, to common.Address) (*big.Int, error) {
+	var traces []traceEntry
+	if err := f.client.CallContext(ctx, &traces, "trace_transaction", txHash); err != nil {
+		return nil, fmt.Errorf("trace_transaction failed: %w", err)
+	}
+
+	// 失敗した呼び出しの子の送金も巻き戻されるため、失敗した呼び出しの traceAddress を記録しておく
+	var failed [][]int
+	for _, t := range traces {
+		if t.Error != "" {
+			failed = append(failed, t.TraceAddress)
+		}
+	}
+
+	total := new(big.Int)
+	for _, t := range traces {
+		// delegatecall・staticcall は送金を伴わない
+		if t.Type != "call" || t.Action.CallType != "call" || isUnderFailedTrace(t.TraceAddress, failed) {
+			continue
+		}
+		if t.Action.To == nil || *t.Action.To != to || t.Action.Value == nil {
+			continue
+		}
+		total.Add(total, t.Action.Value.ToInt())
+	}
+	return total, nil
+}
+
+// isUnderFailedTrace は traceAddress が失敗した呼び出し（またはその子孫）のものか判定する
+func isUnderFailedTrace(traceAddress []int, failed [][]int) bool {
+	for _, f := range failed {
+		if len(f) <= len(traceAddress) && slices.Equal(f, traceAddress[:len(f)]) {
+			return true
+		}
+	}
+	return false
+}
+
+// CallTracerTransferFinder は debug_traceTransaction の callTracer（Geth の debug API）で内部送金を探す
+// ノードが debug_traceTransaction を提供している必要がある
+type CallTracerTransferFinder struct {
+	client *rpc.Client
+}
+
+func NewCallTracerTransferFinder(client *rpc.Client) *CallTracerTransferFinder {
+	return &CallTracerTransferFinder{client: client}
+}
+
+type callFrame struct {
+	Type  string          `json:"type"`
+	To    *common.Address `json:"to"`
+	Value *hexutil.Big    `json:"value"`
+	Error string          `json:"error"`
+	Calls []callFrame     `json:"calls"`
+}
+
+func (f *CallTracerTransferFinder) InternalTransferTo(ctx context.Context, txHash common.Hash, to common.Address) (*big.Int, error) {
+	var root callFrame
+	if err := f.client.CallContext(ctx, &root, "debug_traceTransaction", txHash, map[string]string{"tracer": "callTracer"}); err != nil {
+		return nil, fmt.Errorf("debug_traceTransaction failed: %w", err)
+	}
+
+	total := new(big.Int)
+	sumCallFrames(root, to, total)
+	return total, nil
+}
+
+// sumCallFrames は成功した CALL のうち to への送金額を合計する（失敗した呼び出しの子は巻き戻されるため数えない）
+func sumCallFrames(frame callFrame, to common.Address, total *big.Int) {
+	if frame.Error != "" {
+		return
+	}
+	if frame.Type == "CALL" && frame.To != nil && *frame.To == to && frame.Value != nil {
+		total.Add(total, frame.Value.ToInt())
+	}
+	for _, child := range frame.Calls {
+		sumCallFrames(child, to, total)
+	}
+}
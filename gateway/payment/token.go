@@ -0,0 +1,114 @@
+package gateway
+
+import (
+	"fmt"
+	"math/big"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/crypto"
+)
+
+// NativeAsset はETHでの直接送金・内部送金の支払いを表すアセット名
+const NativeAsset = "ETH"
+
+// erc20TransferSig は ERC-20 の Transfer(address,address,uint256) イベントのシグネチャ
+var erc20TransferSig = crypto.Keccak256Hash([]byte("Transfer(address,address,uint256)"))
+
+// AcceptedToken はETHの代わりに支払いとして受け付けるトークン（WETH など）
+// 金額は 1 トークンあたりのETH建ての価格（EthRate）で Wei に換算してから比較する。
+// 換算レートは設定値で固定のため、ETH と価格が連動するトークン（WETH は 1）以外では
+// レートの更新が遅れた分だけ支払い額がずれることに注意
+type AcceptedToken struct {
+	Address  common.Address
+	Decimals uint8
+	Symbol   string   // 表示用（例: "WETH"）
+	EthRate  *big.Rat // 1 トークンあたりのETH（例: WETH は 1）
+}
+
+// ParseAcceptedTokens は "アドレス:桁数:シンボル:ETH建てレート,..." 形式の設定を読み取る
+// レートは必須（桁数だけで換算すると、6 桁のステーブルコイン 1 枚で 1 ETH の注文を満たせてしまうため）
+func ParseAcceptedTokens(value string) ([]AcceptedToken, error) {
+	var tokens []AcceptedToken
+	for _, entry := range strings.Split(value, ",") {
+		entry = strings.TrimSpace(entry)
+		if entry == "" {
+			continue
+		}
+		parts := strings.Split(entry, ":")
+		if len(parts) != 4 {
+			return nil, fmt.Errorf("invalid accepted token %q (expected address:decimals:symbol:eth_rate)", entry)
+		}
+		addr, decimalsStr, symbol, rateStr := strings.TrimSpace(parts[0]), strings.TrimSpace(parts[1]), strings.TrimSpace(parts[2]), strings.TrimSpace(parts[3])
+		if !common.IsHexAddress(addr) {
+			return nil, fmt.Errorf("invalid token address %q", addr)
+		}
+		decimals, err := strconv.ParseUint(decimalsStr, 10, 8)
+		if err != nil || decimals > 18 {
+			return nil, fmt.Errorf("invalid decimals for token %s: %q", addr, decimalsStr)
+		}
+		if symbol == "" {
+			return nil, fmt.Errorf("missing symbol for token %s", addr)
+		}
+		rate, ok := new(big.Rat).SetString(rateStr)
+		if !ok || rate.Sign() <= 0 {
+			return nil, fmt.Errorf("invalid ETH rate for token %s: %q", addr, rateStr)
+		}
+		tokens = append(tokens, AcceptedToken{
+			Address:  common.HexToAddress(addr),
+			Decimals: uint8(decimals),
+			Symbol:   symbol,
+			EthRate:  rate,
+		})
+	}
+	return tokens, nil
+}
+
+// toWei はトークンの最小単位の金額を EthRate でETHの Wei に換算する（端数は切り捨て）
+func (t AcceptedToken) toWei(amount *big.Int) *big.Int {
+	// amount / 10^Decimals * EthRate * 10^18
+	wei := new(big.Rat).SetInt(amount)
+	wei.Mul(wei, t.EthRate)
+	wei.Mul(wei, new(big.Rat).SetFrac(pow10(18), pow10(int64(t.Decimals))))
+	return new(big.Int).Quo(wei.Num(), wei.Denom())
+}
+
+func pow10(n int64) *big.Int {
+	return new(big.Int).Exp(big.NewInt(10), big.NewInt(n), nil)
+}
+
+// findTokenTransfer はレシートの Transfer イベントから、受け付けるトークンで受け付ける送金先へ
+// 期待額（Wei 相当）以上が送られたものを探す。同じトークン・送金先への複数の Transfer は合算する
+// expectedSender が空でない場合は、その購入者からの Transfer だけを数える（同じトランザクション内の他人の送金を除く）
+func (g *EthGateway) findTokenTransfer(receipt *types.Receipt, recipients []common.Address, expectedSender string, expectedWei *big.Int) (*big.Int, AcceptedToken, common.Address, bool) {
+	for _, token := range g.acceptedTokens {
+		totals := make(map[common.Address]*big.Int)
+		for _, vLog := range receipt.Logs {
+			if vLog.Address != token.Address || len(vLog.Topics) != 3 || vLog.Topics[0] != erc20TransferSig {
+				continue
+			}
+			if expectedSender != "" && common.BytesToAddress(vLog.Topics[1].Bytes()) != common.HexToAddress(expectedSender) {
+				continue
+			}
+			to := common.BytesToAddress(vLog.Topics[2].Bytes())
+			if !slices.Contains(recipients, to) {
+				continue
+			}
+			if totals[to] == nil {
+				totals[to] = new(big.Int)
+			}
+			totals[to].Add(totals[to], new(big.Int).SetBytes(vLog.Data))
+		}
+		for _, to := range recipients {
+			if total, ok := totals[to]; ok {
+				if paid := token.toWei(total); paid.Cmp(expectedWei) >= 0 {
+					return paid, token, to, true
+				}
+			}
+		}
+	}
+	return nil, AcceptedToken{}, common.Address{}, false
+}
@@ -0,0 +1,93 @@
+package gateway
+
+import (
+	"math/big"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+)
+
+var (
+	testWETH        = common.HexToAddress("0x7b79995e5f793A07Bc00c21412e50Ecae098E7f9")
+	testUSDC        = common.HexToAddress("0x1c7D4B196Cb0C7B01d743Fbc6116a902379C7238")
+	testCollect     = common.HexToAddress("0x00000000000000000000000000000000000000c0")
+	testBuyer       = common.HexToAddress("0x00000000000000000000000000000000000000b1")
+	testSomeoneElse = common.HexToAddress("0x00000000000000000000000000000000000000e1")
+)
+
+func transferLog(token, from, to common.Address, amount *big.Int) *types.Log {
+	return &types.Log{
+		Address: token,
+		Topics:  []common.Hash{erc20TransferSig, common.BytesToHash(from.Bytes()), common.BytesToHash(to.Bytes())},
+		Data:    common.LeftPadBytes(amount.Bytes(), 32),
+	}
+}
+
+func mustParseTokens(t *testing.T, value string) []AcceptedToken {
+	t.Helper()
+	tokens, err := ParseAcceptedTokens(value)
+	if err != nil {
+		t.Fatalf("ParseAcceptedTokens(%q): %v", value, err)
+	}
+	return tokens
+}
+
+func TestParseAcceptedTokensRequiresRate(t *testing.T) {
+	for _, value := range []string{
+		testWETH.Hex() + ":18:WETH",
+		testWETH.Hex() + ":18:WETH:0",
+		testWETH.Hex() + ":18:WETH:-1",
+		testWETH.Hex() + ":18:WETH:abc",
+	} {
+		if _, err := ParseAcceptedTokens(value); err == nil {
+			t.Errorf("ParseAcceptedTokens(%q) succeeded, want error", value)
+		}
+	}
+}
+
+func TestAcceptedTokenToWei(t *testing.T) {
+	tokens := mustParseTokens(t, testWETH.Hex()+":18:WETH:1,"+testUSDC.Hex()+":6:USDC:0.0004")
+
+	oneEth := pow10(18)
+	if got := tokens[0].toWei(oneEth); got.Cmp(oneEth) != 0 {
+		t.Errorf("1 WETH = %s Wei, want %s", got, oneEth)
+	}
+
+	// 1 USDC（6 桁）はレート 0.0004 ETH で 4*10^14 Wei
+	oneUSDC := pow10(6)
+	want := new(big.Int).Mul(big.NewInt(4), pow10(14))
+	if got := tokens[1].toWei(oneUSDC); got.Cmp(want) != 0 {
+		t.Errorf("1 USDC = %s Wei, want %s", got, want)
+	}
+}
+
+func TestFindTokenTransferDoesNotTreatStablecoinAsEth(t *testing.T) {
+	g := &EthGateway{acceptedTokens: mustParseTokens(t, testUSDC.Hex()+":6:USDC:0.0004")}
+	receipt := &types.Receipt{Logs: []*types.Log{transferLog(testUSDC, testBuyer, testCollect, pow10(6))}}
+
+	// 1 USDC では 1 ETH の注文を満たさない
+	if _, _, _, ok := g.findTokenTransfer(receipt, []common.Address{testCollect}, "", pow10(18)); ok {
+		t.Fatal("1 USDC satisfied a 1 ETH order")
+	}
+}
+
+func TestFindTokenTransferChecksSender(t *testing.T) {
+	g := &EthGateway{acceptedTokens: mustParseTokens(t, testWETH.Hex()+":18:WETH:1")}
+	expected := pow10(15)
+	recipients := []common.Address{testCollect}
+
+	others := &types.Receipt{Logs: []*types.Log{transferLog(testWETH, testSomeoneElse, testCollect, expected)}}
+	if _, _, _, ok := g.findTokenTransfer(others, recipients, testBuyer.Hex(), expected); ok {
+		t.Error("transfer from another wallet was accepted for the buyer")
+	}
+
+	own := &types.Receipt{Logs: []*types.Log{transferLog(testWETH, testBuyer, testCollect, expected)}}
+	paid, token, to, ok := g.findTokenTransfer(own, recipients, testBuyer.Hex(), expected)
+	if !ok {
+		t.Fatal("buyer's own transfer was not accepted")
+	}
+	if paid.Cmp(expected) != 0 || token.Symbol != "WETH" || to != testCollect {
+		t.Errorf("got paid=%s token=%s to=%s", paid, token.Symbol, to.Hex())
+	}
+}
@@ -0,0 +1,160 @@
+package signer
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"math/big"
+	"strings"
+	"sync"
+
+	"github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/accounts/abi/bind"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/ethereum/go-ethereum/ethclient"
+
+	"uttc-hack-back-onchain/internal/txwait"
+)
+
+// SignerGateway はバックエンドが保持する鍵でトランザクションを送信する
+type SignerGateway interface {
+	// Address は署名者のウォレットアドレスを返す
+	Address() string
+
+	// ChainID は接続先ネットワークのチェーンIDを返す
+	ChainID() *big.Int
+
+	// SendValue は指定アドレスにETHを送金し、ブロックに取り込まれるまで待ってトランザクションハッシュを返す
+	// ctx の期限までにマイニングされなかった場合は、トランザクションハッシュと ErrNotMinedYet を返す
+	SendValue(ctx context.Context, to string, valueWei *big.Int) (string, error)
+}
+
+// EthSignerGateway は秘密鍵を使った SignerGateway の実装
+// 秘密鍵はログに出力しないこと
+type EthSignerGateway struct {
+	client  *ethclient.Client
+	opts    *bind.TransactOpts
+	chainID *big.Int
+
+	// 代理送信とセルフテストが同時に同じ nonce を使わないよう、nonce の決定から送信までを直列化する
+	sendMu sync.Mutex
+	// 次に使う nonce（送信に失敗した場合は0に戻し、ノードの値で同期し直す）
+	// ノードの PendingNonceAt が直前の送信をまだ反映していない場合に備えて、大きい方を使う
+	nextNonce uint64
+}
+
+// NewEthSignerGateway は16進数の秘密鍵から署名ゲートウェイを作成
+func NewEthSignerGateway(ctx context.Context, client *ethclient.Client, privateKeyHex string) (*EthSignerGateway, error) {
+	key, err := crypto.HexToECDSA(strings.TrimPrefix(privateKeyHex, "0x"))
+	if err != nil {
+		// 鍵の内容が含まれないようエラー詳細は出さない
+		return nil, errors.New("invalid signer private key")
+	}
+
+	chainID, err := client.ChainID(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get chain id: %w", err)
+	}
+
+	opts, err := bind.NewKeyedTransactorWithChainID(key, chainID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create transactor: %w", err)
+	}
+
+	log.Printf("Signer initialized: %s (chain id: %s)", opts.From.Hex(), chainID)
+	return &EthSignerGateway{
+		client:  client,
+		opts:    opts,
+		chainID: chainID,
+	}, nil
+}
+
+func (g *EthSignerGateway) Address() string {
+	return g.opts.From.Hex()
+}
+
+func (g *EthSignerGateway) ChainID() *big.Int {
+	return new(big.Int).Set(g.chainID)
+}
+
+// SendValue はEIP-1559形式の送金トランザクションを作成・署名・送信し、マイニングを待つ
+func (g *EthSignerGateway) SendValue(ctx context.Context, to string, valueWei *big.Int) (string, error) {
+	signedTx, err := g.sendTransaction(ctx, common.HexToAddress(to), valueWei, nil)
+	if err != nil {
+		return "", err
+	}
+	log.Printf("Transaction sent: %s (%s Wei to %s)", signedTx.Hash().Hex(), valueWei.String(), to)
+
+	if _, err := g.waitMined(ctx, signedTx); err != nil {
+		return signedTx.Hash().Hex(), err
+	}
+
+	return signedTx.Hash().Hex(), nil
+}
+
+// waitMined はトランザクションのレシートを ctx の期限まで待つ
+// 期限切れの場合は ErrNotMinedYet を、マイニングされずに消えた場合は txwait.ErrTxDropped を返す
+func (g *EthSignerGateway) waitMined(ctx context.Context, signedTx *types.Transaction) (*types.Receipt, error) {
+	receipt, err := txwait.WaitForReceipt(ctx, g.client, signedTx.Hash(), 1)
+	if err != nil {
+		if errors.Is(err, context.DeadlineExceeded) {
+			return nil, fmt.Errorf("%w: %s", ErrNotMinedYet, signedTx.Hash().Hex())
+		}
+		return nil, fmt.Errorf("failed to wait for transaction: %w", err)
+	}
+	return receipt, nil
+}
+
+// sendTransaction はEIP-1559形式のトランザクションを作成・署名して送信する（マイニングは待たない）
+func (g *EthSignerGateway) sendTransaction(ctx context.Context, toAddr common.Address, valueWei *big.Int, data []byte) (*types.Transaction, error) {
+	g.sendMu.Lock()
+	defer g.sendMu.Unlock()
+
+	nonce, err := g.client.PendingNonceAt(ctx, g.opts.From)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get nonce: %w", err)
+	}
+	nonce = max(nonce, g.nextNonce)
+
+	tipCap, err := g.client.SuggestGasTipCap(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to suggest gas tip: %w", err)
+	}
+	header, err := g.client.HeaderByNumber(ctx, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get latest block: %w", err)
+	}
+	// ベースフィーの上昇に備えて2倍の余裕を持たせる
+	feeCap := new(big.Int).Add(new(big.Int).Mul(header.BaseFee, big.NewInt(2)), tipCap)
+
+	// 送金先がコントラクトの場合に備えてガス量を見積もる（revert する呼び出しはここで失敗する）
+	gas, err := g.client.EstimateGas(ctx, ethereum.CallMsg{From: g.opts.From, To: &toAddr, Value: valueWei, Data: data})
+	if err != nil {
+		return nil, fmt.Errorf("failed to estimate gas: %w", err)
+	}
+
+	tx := types.NewTx(&types.DynamicFeeTx{
+		ChainID:   g.chainID,
+		Nonce:     nonce,
+		GasTipCap: tipCap,
+		GasFeeCap: feeCap,
+		Gas:       gas,
+		To:        &toAddr,
+		Value:     valueWei,
+		Data:      data,
+	})
+	signedTx, err := g.opts.Signer(g.opts.From, tx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to sign transaction: %w", err)
+	}
+
+	if err := g.client.SendTransaction(ctx, signedTx); err != nil {
+		g.nextNonce = 0
+		return nil, fmt.Errorf("failed to send transaction: %w", err)
+	}
+	g.nextNonce = nonce + 1
+	return signedTx, nil
+}
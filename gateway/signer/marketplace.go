@@ -0,0 +1,125 @@
+package signer
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"math/big"
+	"strings"
+
+	"github.com/ethereum/go-ethereum/accounts/abi"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+
+	"uttc-hack-back-onchain/gateway/contract"
+)
+
+// MarketplaceRelayer はリレイヤーウォレットとしてマーケットプレイスコントラクトの関数を呼び出す
+type MarketplaceRelayer interface {
+	// Address はリレイヤーのウォレットアドレスを返す
+	Address() string
+
+	// SubmitBuyItem は buyItem トランザクションを送信し、マイニングを待たずにトランザクションハッシュを返す
+	// buyerUid はコントラクトに記録される購入者のユーザーID
+	SubmitBuyItem(ctx context.Context, itemId uint64, valueWei *big.Int, buyerUid string) (string, error)
+
+	// SubmitListItem は listItem トランザクションを送信してマイニングを待ち、
+	// トランザクションハッシュとレシートの ItemListed ログから読み取った itemId を返す
+	// ctx の期限までにマイニングされなかった場合は、トランザクションハッシュと ErrNotMinedYet を返す
+	SubmitListItem(ctx context.Context, params ListItemParams) (string, uint64, error)
+}
+
+// ErrNotMinedYet はトランザクションを送信したが、待機時間内にマイニングを確認できなかった
+// トランザクション自体は有効なままで、後からマイニングされる可能性がある
+var ErrNotMinedYet = errors.New("transaction was sent but not mined within the wait timeout")
+
+// ListItemParams は listItem の引数
+type ListItemParams struct {
+	Title       string
+	Price       *big.Int
+	Explanation string
+	ImageUrl    string
+	Uid         string
+	Category    string
+	TokenURI    string
+}
+
+// EthMarketplaceRelayer は EthSignerGateway の鍵で buyItem を送信する MarketplaceRelayer の実装
+type EthMarketplaceRelayer struct {
+	signer          *EthSignerGateway
+	contractAddress common.Address
+	contractABI     abi.ABI
+}
+
+// NewEthMarketplaceRelayer はマーケットプレイスコントラクト向けのリレイヤーを作成
+func NewEthMarketplaceRelayer(signer *EthSignerGateway, contractAddr string) (*EthMarketplaceRelayer, error) {
+	if !common.IsHexAddress(contractAddr) {
+		return nil, fmt.Errorf("invalid contract address: %s", contractAddr)
+	}
+
+	parsedABI, err := abi.JSON(strings.NewReader(contract.FrimaMarketplaceABI))
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse ABI: %w", err)
+	}
+
+	log.Printf("Marketplace relayer initialized: %s -> %s", signer.Address(), contractAddr)
+	return &EthMarketplaceRelayer{
+		signer:          signer,
+		contractAddress: common.HexToAddress(contractAddr),
+		contractABI:     parsedABI,
+	}, nil
+}
+
+func (r *EthMarketplaceRelayer) Address() string {
+	return r.signer.Address()
+}
+
+func (r *EthMarketplaceRelayer) SubmitBuyItem(ctx context.Context, itemId uint64, valueWei *big.Int, buyerUid string) (string, error) {
+	data, err := r.contractABI.Pack("buyItem", new(big.Int).SetUint64(itemId), buyerUid)
+	if err != nil {
+		return "", fmt.Errorf("failed to pack buyItem: %w", err)
+	}
+
+	signedTx, err := r.signer.sendTransaction(ctx, r.contractAddress, valueWei, data)
+	if err != nil {
+		return "", err
+	}
+
+	log.Printf("buyItem submitted: %s (itemId=%d, %s Wei)", signedTx.Hash().Hex(), itemId, valueWei.String())
+	return signedTx.Hash().Hex(), nil
+}
+
+func (r *EthMarketplaceRelayer) SubmitListItem(ctx context.Context, params ListItemParams) (string, uint64, error) {
+	data, err := r.contractABI.Pack("listItem", params.Title, params.Price, params.Explanation, params.ImageUrl, params.Uid, params.Category, params.TokenURI)
+	if err != nil {
+		return "", 0, fmt.Errorf("failed to pack listItem: %w", err)
+	}
+
+	signedTx, err := r.signer.sendTransaction(ctx, r.contractAddress, big.NewInt(0), data)
+	if err != nil {
+		return "", 0, err
+	}
+	txHash := signedTx.Hash().Hex()
+	log.Printf("listItem submitted: %s (price=%s Wei)", txHash, params.Price.String())
+
+	receipt, err := r.signer.waitMined(ctx, signedTx)
+	if err != nil {
+		return txHash, 0, err
+	}
+	if receipt.Status != types.ReceiptStatusSuccessful {
+		return txHash, 0, errors.New("listItem transaction reverted")
+	}
+
+	// 新しい itemId は ItemListed の1番目の indexed 引数
+	itemListedID := r.contractABI.Events["ItemListed"].ID
+	for _, vLog := range receipt.Logs {
+		if vLog.Address != r.contractAddress || len(vLog.Topics) < 2 || vLog.Topics[0] != itemListedID {
+			continue
+		}
+		itemId := new(big.Int).SetBytes(vLog.Topics[1].Bytes()).Uint64()
+		log.Printf("listItem mined: %s (itemId=%d)", txHash, itemId)
+		return txHash, itemId, nil
+	}
+	return txHash, 0, errors.New("ItemListed log not found in receipt")
+}
@@ -0,0 +1,29 @@
+package handler
+
+import (
+	"encoding/json"
+	"net/http"
+
+	"uttc-hack-back-onchain/handler/httpjson"
+	"uttc-hack-back-onchain/usecase/chain"
+)
+
+type ChainHandler struct {
+	chainUC usecase.ChainUsecase
+}
+
+func NewChainHandler(uc usecase.ChainUsecase) *ChainHandler {
+	return &ChainHandler{chainUC: uc}
+}
+
+// HandleGetConditions は現在のネットワーク手数料状況を返す
+func (h *ChainHandler) HandleGetConditions(w http.ResponseWriter, r *http.Request) {
+	conditions, err := h.chainUC.GetConditions(r.Context())
+	if err != nil {
+		httpjson.WriteError(w, http.StatusInternalServerError, err.Error(), httpjson.CodeInternal)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(conditions)
+}
@@ -2,14 +2,30 @@ package handler
 
 import (
 	"encoding/json"
+	"errors"
+	"log"
+	"math"
+	"math/big"
 	"net/http"
 	"strconv"
+	"time"
 
+	"uttc-hack-back-onchain/gateway/contract"
+	"uttc-hack-back-onchain/gateway/signer"
+	"uttc-hack-back-onchain/handler/httpjson"
+	"uttc-hack-back-onchain/model"
 	"uttc-hack-back-onchain/usecase/contract"
 
 	"github.com/gorilla/mux"
 )
 
+const (
+	// defaultListLimit は商品一覧の1ページあたりのデフォルト件数
+	defaultListLimit = 20
+	// maxListLimit は商品一覧の1ページあたりの最大件数
+	maxListLimit = 100
+)
+
 type ContractHandler struct {
 	contractUC usecase.ContractUsecase
 }
@@ -25,22 +41,232 @@ func (h *ContractHandler) HandleGetItem(w http.ResponseWriter, r *http.Request)
 
 	itemId, err := strconv.ParseUint(itemIdStr, 10, 64)
 	if err != nil {
-		http.Error(w, "Invalid item ID", http.StatusBadRequest)
+		httpjson.WriteError(w, http.StatusBadRequest, "Invalid item ID", httpjson.CodeInvalidItemID)
 		return
 	}
 
 	item, err := h.contractUC.GetItem(r.Context(), itemId)
 	if err != nil {
-		http.Error(w, err.Error(), http.StatusInternalServerError)
+		writeReadError(w, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(itemResponse(item))
+}
+
+// HandleGetItemMetadata は商品のNFTのメタデータ（name・description・image）を返す
+// メタデータが取得できない場合も tokenURI などの部分的な結果を200で返す
+func (h *ContractHandler) HandleGetItemMetadata(w http.ResponseWriter, r *http.Request) {
+	itemId, err := strconv.ParseUint(mux.Vars(r)["itemId"], 10, 64)
+	if err != nil {
+		httpjson.WriteError(w, http.StatusBadRequest, "Invalid item ID", httpjson.CodeInvalidItemID)
+		return
+	}
+
+	metadata, err := h.contractUC.GetItemMetadata(r.Context(), itemId)
+	if err != nil {
+		switch {
+		case errors.Is(err, usecase.ErrItemNotFound):
+			httpjson.WriteError(w, http.StatusNotFound, err.Error(), httpjson.CodeItemNotFound)
+		case errors.Is(err, contract.ErrTokenNotFound):
+			httpjson.WriteError(w, http.StatusNotFound, err.Error(), httpjson.CodeTokenNotFound)
+		default:
+			writeReadError(w, err)
+		}
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(metadata)
+}
+
+// HandleListItems はコントラクトの商品一覧をページングして返す
+func (h *ContractHandler) HandleListItems(w http.ResponseWriter, r *http.Request) {
+	offset, err := parseUintQuery(r, "offset", 0)
+	if err != nil {
+		httpjson.WriteError(w, http.StatusBadRequest, "Invalid offset", httpjson.CodeInvalidParam)
+		return
+	}
+	limit, err := parseUintQuery(r, "limit", defaultListLimit)
+	if err != nil || limit == 0 {
+		httpjson.WriteError(w, http.StatusBadRequest, "Invalid limit", httpjson.CodeInvalidParam)
+		return
+	}
+	if limit > maxListLimit {
+		limit = maxListLimit
+	}
+
+	items, total, err := h.contractUC.ListItems(r.Context(), offset, limit)
+	if err != nil {
+		writeReadError(w, err)
+		return
+	}
+
+	responses := make([]map[string]interface{}, 0, len(items))
+	for _, item := range items {
+		responses = append(responses, itemResponse(item))
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"items":  responses,
+		"total":  total,
+		"offset": offset,
+		"limit":  limit,
+	})
+}
+
+// HandleListCategoryItems はカテゴリが一致する商品（大文字小文字を区別しない）をページングして返す
+func (h *ContractHandler) HandleListCategoryItems(w http.ResponseWriter, r *http.Request) {
+	category := mux.Vars(r)["category"]
+	offset, err := parseUintQuery(r, "offset", 0)
+	if err != nil {
+		httpjson.WriteError(w, http.StatusBadRequest, "Invalid offset", httpjson.CodeInvalidParam)
+		return
+	}
+	limit, err := parseUintQuery(r, "limit", defaultListLimit)
+	if err != nil || limit == 0 {
+		httpjson.WriteError(w, http.StatusBadRequest, "Invalid limit", httpjson.CodeInvalidParam)
+		return
+	}
+	if limit > maxListLimit {
+		limit = maxListLimit
+	}
+
+	items, total, err := h.contractUC.GetItemsByCategory(r.Context(), category, offset, limit)
+	if err != nil {
+		writeReadError(w, err)
+		return
+	}
+
+	responses := make([]map[string]interface{}, 0, len(items))
+	for _, item := range items {
+		responses = append(responses, itemResponse(item))
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"items":  responses,
+		"total":  total,
+		"offset": offset,
+		"limit":  limit,
+	})
+}
+
+// HandleListCompletedItems は取引が成立した商品をページングして返す
+// 購入者・出品者・価格に加え、完了件数全体の GMV（価格の合計）を返す
+func (h *ContractHandler) HandleListCompletedItems(w http.ResponseWriter, r *http.Request) {
+	offset, err := parseUintQuery(r, "offset", 0)
+	if err != nil {
+		httpjson.WriteError(w, http.StatusBadRequest, "Invalid offset", httpjson.CodeInvalidParam)
+		return
+	}
+	limit, err := parseUintQuery(r, "limit", defaultListLimit)
+	if err != nil || limit == 0 {
+		httpjson.WriteError(w, http.StatusBadRequest, "Invalid limit", httpjson.CodeInvalidParam)
+		return
+	}
+	if limit > maxListLimit {
+		limit = maxListLimit
+	}
+
+	items, total, gmv, err := h.contractUC.GetCompletedItems(r.Context(), offset, limit)
+	if err != nil {
+		writeReadError(w, err)
+		return
+	}
+
+	responses := make([]map[string]interface{}, 0, len(items))
+	for _, item := range items {
+		resp := itemResponse(item)
+		resp["buyer_uid"] = item.BuyerUid
+		resp["receipt_confirmed"] = item.Status == model.ItemStatusCompleted
+		responses = append(responses, resp)
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"items":   responses,
+		"total":   total,
+		"offset":  offset,
+		"limit":   limit,
+		"gmv_wei": gmv.String(),
+		"gmv_eth": model.WeiToEthString(gmv),
+	})
+}
+
+// HandleListSellerItems は出品者の現在の出品（購入済みなども含む）をページングして返す
+func (h *ContractHandler) HandleListSellerItems(w http.ResponseWriter, r *http.Request) {
+	seller := mux.Vars(r)["address"]
+	offset, err := parseUintQuery(r, "offset", 0)
+	if err != nil {
+		httpjson.WriteError(w, http.StatusBadRequest, "Invalid offset", httpjson.CodeInvalidParam)
 		return
 	}
+	limit, err := parseUintQuery(r, "limit", defaultListLimit)
+	if err != nil || limit == 0 {
+		httpjson.WriteError(w, http.StatusBadRequest, "Invalid limit", httpjson.CodeInvalidParam)
+		return
+	}
+	if limit > maxListLimit {
+		limit = maxListLimit
+	}
+
+	items, total, err := h.contractUC.GetItemsBySeller(r.Context(), seller, offset, limit)
+	if err != nil {
+		if errors.Is(err, usecase.ErrInvalidAddress) {
+			httpjson.WriteError(w, http.StatusBadRequest, "Invalid seller address", httpjson.CodeInvalidAddress)
+			return
+		}
+		writeReadError(w, err)
+		return
+	}
+
+	responses := make([]map[string]interface{}, 0, len(items))
+	for _, item := range items {
+		responses = append(responses, itemResponse(item))
+	}
 
-	// Priceをstring形式に変換してレスポンス
-	response := map[string]interface{}{
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"items":  responses,
+		"total":  total,
+		"offset": offset,
+		"limit":  limit,
+	})
+}
+
+// writeReadError はコントラクトの読み取りの失敗をHTTPステータスに変換して返す
+// ノードの応答が遅い場合は 504、コントラクトが呼び出せない場合は 503
+func writeReadError(w http.ResponseWriter, err error) {
+	switch {
+	case errors.Is(err, contract.ErrCallTimeout):
+		httpjson.WriteError(w, http.StatusGatewayTimeout, err.Error(), httpjson.CodeCallTimeout)
+	case errors.Is(err, contract.ErrContractUnavailable):
+		httpjson.WriteError(w, http.StatusServiceUnavailable, err.Error(), httpjson.CodeContractUnavailable)
+	default:
+		httpjson.WriteError(w, http.StatusInternalServerError, err.Error(), httpjson.CodeInternal)
+	}
+}
+
+// parseUintQuery はクエリパラメータを符号なし整数として読み取る（未指定ならデフォルト値）
+func parseUintQuery(r *http.Request, key string, defaultValue uint64) (uint64, error) {
+	value := r.URL.Query().Get(key)
+	if value == "" {
+		return defaultValue, nil
+	}
+	return strconv.ParseUint(value, 10, 64)
+}
+
+// itemResponse はPriceをstring形式に変換したレスポンス用のマップを作成
+func itemResponse(item *model.ContractItem) map[string]interface{} {
+	return map[string]interface{}{
 		"item_id":      item.ItemId,
 		"token_id":     item.TokenId,
 		"title":        item.Title,
 		"price_wei":    item.Price.String(),
+		"price_eth":    model.WeiToEthString(item.Price),
 		"explanation":  item.Explanation,
 		"image_url":    item.ImageUrl,
 		"uid":          item.Uid,
@@ -51,10 +277,93 @@ func (h *ContractHandler) HandleGetItem(w http.ResponseWriter, r *http.Request)
 		"seller":       item.Seller,
 		"buyer":        item.Buyer,
 		"status":       item.Status,
+		"status_label": item.StatusString(),
+	}
+}
+
+// HandleGetItemHistory は商品のオンチェーンイベント履歴をブロック順に返す
+func (h *ContractHandler) HandleGetItemHistory(w http.ResponseWriter, r *http.Request) {
+	itemId, err := strconv.ParseUint(mux.Vars(r)["itemId"], 10, 64)
+	if err != nil {
+		httpjson.WriteError(w, http.StatusBadRequest, "Invalid item ID", httpjson.CodeInvalidItemID)
+		return
+	}
+
+	events, err := h.contractUC.GetItemHistory(r.Context(), itemId)
+	if err != nil {
+		httpjson.WriteError(w, http.StatusInternalServerError, err.Error(), httpjson.CodeInternal)
+		return
+	}
+
+	responses := make([]map[string]interface{}, 0, len(events))
+	for _, event := range events {
+		responses = append(responses, eventResponse(event))
 	}
 
 	w.Header().Set("Content-Type", "application/json")
-	json.NewEncoder(w).Encode(response)
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"item_id": itemId,
+		"events":  responses,
+	})
+}
+
+// HandleResyncItemEvents は商品のオンチェーンイベントをすべてバックエンドに再通知する（管理者用）
+func (h *ContractHandler) HandleResyncItemEvents(w http.ResponseWriter, r *http.Request) {
+	itemId, err := strconv.ParseUint(mux.Vars(r)["itemId"], 10, 64)
+	if err != nil {
+		httpjson.WriteError(w, http.StatusBadRequest, "Invalid item ID", httpjson.CodeInvalidItemID)
+		return
+	}
+
+	// 通知件数によっては時間がかかるため（上限はユースケース側で設ける）、サーバー全体の WriteTimeout はこのリクエストだけ解除する
+	if err := http.NewResponseController(w).SetWriteDeadline(time.Time{}); err != nil {
+		log.Printf("WARNING: Failed to disable write deadline for item event resync: %v", err)
+	}
+
+	results, err := h.contractUC.ResyncItemEvents(r.Context(), itemId)
+	if err != nil {
+		httpjson.WriteError(w, http.StatusInternalServerError, err.Error(), httpjson.CodeInternal)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"item_id": itemId,
+		"events":  results,
+	})
+}
+
+// ReplayEventsRequest はイベント再通知リクエスト
+type ReplayEventsRequest struct {
+	FromBlock uint64 `json:"from_block"`
+	ToBlock   uint64 `json:"to_block"`
+}
+
+// HandleReplayEvents はブロック範囲のイベントをすべてバックエンドに再通知し、種類ごとの件数を返す（管理者用）
+func (h *ContractHandler) HandleReplayEvents(w http.ResponseWriter, r *http.Request) {
+	var req ReplayEventsRequest
+	if err := httpjson.DecodeStrict(w, r, &req); err != nil {
+		httpjson.WriteError(w, http.StatusBadRequest, err.Error(), httpjson.CodeInvalidRequest)
+		return
+	}
+
+	// 範囲によっては通知に時間がかかるため、サーバー全体の WriteTimeout はこのリクエストだけ解除する
+	if err := http.NewResponseController(w).SetWriteDeadline(time.Time{}); err != nil {
+		log.Printf("WARNING: Failed to disable write deadline for event replay: %v", err)
+	}
+
+	summary, err := h.contractUC.ReplayEvents(r.Context(), req.FromBlock, req.ToBlock)
+	if err != nil {
+		if errors.Is(err, usecase.ErrInvalidBlockRange) {
+			httpjson.WriteError(w, http.StatusBadRequest, err.Error(), httpjson.CodeInvalidBlockRange)
+			return
+		}
+		httpjson.WriteError(w, http.StatusInternalServerError, err.Error(), httpjson.CodeInternal)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(summary)
 }
 
 // VerifyTxRequest はトランザクション検証リクエスト
@@ -65,19 +374,39 @@ type VerifyTxRequest struct {
 // HandleVerifyTransaction はトランザクションを検証
 func (h *ContractHandler) HandleVerifyTransaction(w http.ResponseWriter, r *http.Request) {
 	var req VerifyTxRequest
-	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
-		http.Error(w, "Invalid request body", http.StatusBadRequest)
+	if err := httpjson.DecodeStrict(w, r, &req); err != nil {
+		httpjson.WriteError(w, http.StatusBadRequest, err.Error(), httpjson.CodeInvalidRequest)
 		return
 	}
 
 	if req.TxHash == "" {
-		http.Error(w, "tx_hash is required", http.StatusBadRequest)
+		httpjson.WriteError(w, http.StatusBadRequest, "tx_hash is required", httpjson.CodeInvalidRequest)
 		return
 	}
 
-	verification, err := h.contractUC.VerifyTransaction(r.Context(), req.TxHash)
+	// ?wait_seconds=N の場合はマイニングされるまで最大 N 秒（サーバー側の上限あり）待ってから返す
+	waitSeconds, err := parseUintQuery(r, "wait_seconds", 0)
 	if err != nil {
-		http.Error(w, err.Error(), http.StatusInternalServerError)
+		httpjson.WriteError(w, http.StatusBadRequest, "Invalid wait_seconds", httpjson.CodeInvalidParam)
+		return
+	}
+
+	var verification *model.TxVerification
+	if waitSeconds > 0 {
+		// 待機はユースケース側の上限で打ち切られるため、サーバー全体の WriteTimeout はこのリクエストだけ解除する
+		if err := http.NewResponseController(w).SetWriteDeadline(time.Time{}); err != nil {
+			log.Printf("WARNING: Failed to disable write deadline for verify-tx wait: %v", err)
+		}
+		verification, err = h.contractUC.VerifyTransactionAndWait(r.Context(), req.TxHash, time.Duration(min(waitSeconds, uint64(math.MaxInt32)))*time.Second)
+	} else {
+		verification, err = h.contractUC.VerifyTransaction(r.Context(), req.TxHash)
+	}
+	if err != nil {
+		if errors.Is(err, contract.ErrTxDropped) {
+			httpjson.WriteError(w, http.StatusGone, err.Error(), httpjson.CodeTxDropped)
+			return
+		}
+		httpjson.WriteError(w, http.StatusInternalServerError, err.Error(), httpjson.CodeInternal)
 		return
 	}
 
@@ -85,14 +414,188 @@ func (h *ContractHandler) HandleVerifyTransaction(w http.ResponseWriter, r *http
 	json.NewEncoder(w).Encode(verification)
 }
 
-// HandleContractInfo はコントラクト情報を返す
+// HandleDecodeTransaction はトランザクションのログをサービスがどうデコードするかを返す（ABI不一致の調査用）
+func (h *ContractHandler) HandleDecodeTransaction(w http.ResponseWriter, r *http.Request) {
+	var req VerifyTxRequest
+	if err := httpjson.DecodeStrict(w, r, &req); err != nil {
+		httpjson.WriteError(w, http.StatusBadRequest, err.Error(), httpjson.CodeInvalidRequest)
+		return
+	}
+
+	if req.TxHash == "" {
+		httpjson.WriteError(w, http.StatusBadRequest, "tx_hash is required", httpjson.CodeInvalidRequest)
+		return
+	}
+
+	decoded, err := h.contractUC.DecodeTransactionLogs(r.Context(), req.TxHash)
+	if err != nil {
+		httpjson.WriteError(w, http.StatusInternalServerError, err.Error(), httpjson.CodeInternal)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(decoded)
+}
+
+// HandleInjectTestEvent は合成したイベントを実際のイベントと同じ経路でバックエンドへ通知する（結合テスト用）
+func (h *ContractHandler) HandleInjectTestEvent(w http.ResponseWriter, r *http.Request) {
+	var event model.ContractEvent
+	if err := httpjson.DecodeStrict(w, r, &event); err != nil {
+		httpjson.WriteError(w, http.StatusBadRequest, err.Error(), httpjson.CodeInvalidRequest)
+		return
+	}
+
+	if err := h.contractUC.InjectTestEvent(r.Context(), &event); err != nil {
+		if errors.Is(err, usecase.ErrInvalidEvent) {
+			httpjson.WriteError(w, http.StatusBadRequest, err.Error(), httpjson.CodeInvalidEvent)
+			return
+		}
+		httpjson.WriteError(w, http.StatusBadGateway, err.Error(), httpjson.CodeNotifyFailed)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"notified": true,
+		"type":     event.Type,
+		"item_id":  event.ItemId,
+		"trace_id": event.TraceID,
+	})
+}
+
+// HandleContractInfo はコントラクトアドレス・チェーンID・最新ブロック・NFTコントラクトのアドレスを返す
 func (h *ContractHandler) HandleContractInfo(w http.ResponseWriter, r *http.Request) {
-	// ContractUsecaseからコントラクトアドレスを取得する場合は別途メソッド追加が必要
-	// ここでは簡易的にハンドラーで保持している情報を返す
-	info := map[string]string{
-		"message": "Contract API is running",
+	info, err := h.contractUC.GetContractInfo(r.Context())
+	if err != nil {
+		writeReadError(w, err)
+		return
 	}
 
 	w.Header().Set("Content-Type", "application/json")
 	json.NewEncoder(w).Encode(info)
 }
+
+// HandleEventSignatures はイベント名ごとのシグネチャハッシュ（topic0）を返す
+func (h *ContractHandler) HandleEventSignatures(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(h.contractUC.EventSignatures())
+}
+
+// HandleStatus はイベントリスナーの状態（受信方式・処理済みブロック・イベント数・直近の通知エラー）を返す
+func (h *ContractHandler) HandleStatus(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(h.contractUC.Status())
+}
+
+// HandleGetItemCount はコントラクトに登録された商品数を返す
+func (h *ContractHandler) HandleGetItemCount(w http.ResponseWriter, r *http.Request) {
+	count, err := h.contractUC.GetItemCount(r.Context())
+	if err != nil {
+		writeReadError(w, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]uint64{"count": count})
+}
+
+// RelayBuyItemRequest は代理購入リクエスト
+type RelayBuyItemRequest struct {
+	BuyerUid string `json:"buyer_uid"`
+}
+
+// HandleRelayBuyItem はリレイヤーウォレットから buyItem トランザクションを送信する（管理者用）
+func (h *ContractHandler) HandleRelayBuyItem(w http.ResponseWriter, r *http.Request) {
+	itemId, err := strconv.ParseUint(mux.Vars(r)["itemId"], 10, 64)
+	if err != nil {
+		httpjson.WriteError(w, http.StatusBadRequest, "Invalid item ID", httpjson.CodeInvalidItemID)
+		return
+	}
+
+	var req RelayBuyItemRequest
+	if err := httpjson.DecodeStrict(w, r, &req); err != nil {
+		httpjson.WriteError(w, http.StatusBadRequest, err.Error(), httpjson.CodeInvalidRequest)
+		return
+	}
+	if req.BuyerUid == "" {
+		httpjson.WriteError(w, http.StatusBadRequest, "buyer_uid is required", httpjson.CodeInvalidRequest)
+		return
+	}
+
+	relayed, err := h.contractUC.RelayBuyItem(r.Context(), itemId, req.BuyerUid)
+	if err != nil {
+		switch {
+		case errors.Is(err, usecase.ErrRelayerDisabled):
+			httpjson.WriteError(w, http.StatusServiceUnavailable, err.Error(), httpjson.CodeRelayerDisabled)
+		case errors.Is(err, usecase.ErrItemNotForSale):
+			httpjson.WriteError(w, http.StatusConflict, err.Error(), httpjson.CodeItemNotForSale)
+		default:
+			httpjson.WriteError(w, http.StatusInternalServerError, err.Error(), httpjson.CodeInternal)
+		}
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusAccepted)
+	json.NewEncoder(w).Encode(relayed)
+}
+
+// RelayListItemRequest は代理出品リクエスト
+type RelayListItemRequest struct {
+	Title       string `json:"title"`
+	PriceWei    string `json:"price_wei"`
+	Explanation string `json:"explanation"`
+	ImageUrl    string `json:"image_url"`
+	Uid         string `json:"uid"`
+	Category    string `json:"category"`
+	TokenURI    string `json:"token_uri"`
+}
+
+// HandleRelayListItem はリレイヤーウォレットから listItem トランザクションを送信し、新しい itemId を返す（管理者用）
+func (h *ContractHandler) HandleRelayListItem(w http.ResponseWriter, r *http.Request) {
+	var req RelayListItemRequest
+	if err := httpjson.DecodeStrict(w, r, &req); err != nil {
+		httpjson.WriteError(w, http.StatusBadRequest, err.Error(), httpjson.CodeInvalidRequest)
+		return
+	}
+	price, ok := new(big.Int).SetString(req.PriceWei, 10)
+	if !ok {
+		httpjson.WriteError(w, http.StatusBadRequest, "price_wei must be a decimal integer", httpjson.CodeInvalidRequest)
+		return
+	}
+
+	// マイニングの待機はユースケース側のタイムアウトで打ち切られるため、サーバー全体の WriteTimeout はこのリクエストだけ解除する
+	if err := http.NewResponseController(w).SetWriteDeadline(time.Time{}); err != nil {
+		log.Printf("WARNING: Failed to disable write deadline for relayed listItem: %v", err)
+	}
+
+	relayed, err := h.contractUC.RelayListItem(r.Context(), signer.ListItemParams{
+		Title:       req.Title,
+		Price:       price,
+		Explanation: req.Explanation,
+		ImageUrl:    req.ImageUrl,
+		Uid:         req.Uid,
+		Category:    req.Category,
+		TokenURI:    req.TokenURI,
+	})
+	if err != nil {
+		switch {
+		case errors.Is(err, usecase.ErrRelayerDisabled):
+			httpjson.WriteError(w, http.StatusServiceUnavailable, err.Error(), httpjson.CodeRelayerDisabled)
+		case errors.Is(err, usecase.ErrInvalidListing):
+			httpjson.WriteError(w, http.StatusBadRequest, err.Error(), httpjson.CodeInvalidListing)
+		default:
+			httpjson.WriteError(w, http.StatusInternalServerError, err.Error(), httpjson.CodeInternal)
+		}
+		return
+	}
+
+	// マイニングを確認できなかった場合は送信済みとして 202 を返す
+	status := http.StatusCreated
+	if relayed.Pending {
+		status = http.StatusAccepted
+	}
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(relayed)
+}
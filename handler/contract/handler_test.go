@@ -0,0 +1,37 @@
+package handler
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"uttc-hack-back-onchain/gateway/contract"
+	"uttc-hack-back-onchain/handler/httpjson"
+)
+
+func TestWriteReadError(t *testing.T) {
+	tests := []struct {
+		err        error
+		wantStatus int
+		wantCode   string
+	}{
+		{fmt.Errorf("failed to call getItem: %w", contract.ErrCallTimeout), http.StatusGatewayTimeout, httpjson.CodeCallTimeout},
+		{contract.ErrContractUnavailable, http.StatusServiceUnavailable, httpjson.CodeContractUnavailable},
+		{errors.New("boom"), http.StatusInternalServerError, httpjson.CodeInternal},
+	}
+	for _, tt := range tests {
+		rec := httptest.NewRecorder()
+		writeReadError(rec, tt.err)
+
+		var body struct {
+			Code string `json:"code"`
+		}
+		json.NewDecoder(rec.Body).Decode(&body)
+		if rec.Code != tt.wantStatus || body.Code != tt.wantCode {
+			t.Errorf("writeReadError(%v) = %d %q, want %d %q", tt.err, rec.Code, body.Code, tt.wantStatus, tt.wantCode)
+		}
+	}
+}
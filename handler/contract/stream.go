@@ -0,0 +1,112 @@
+package handler
+
+import (
+	"encoding/json"
+	"fmt"
+	"log"
+	"net/http"
+	"strconv"
+	"time"
+
+	"uttc-hack-back-onchain/handler/httpjson"
+	"uttc-hack-back-onchain/model"
+)
+
+// streamHeartbeatInterval はプロキシにアイドル接続として切断されないためのコメント送信間隔
+const streamHeartbeatInterval = 15 * time.Second
+
+// HandleEventStream はコントラクトイベントを Server-Sent Events で配信する
+// イベントIDにはブロック番号を使い、再接続時の Last-Event-ID（または last_event_id クエリ）
+// より後のブロックのイベントをサーバーが保持している範囲で再送する
+func (h *ContractHandler) HandleEventStream(w http.ResponseWriter, r *http.Request) {
+	lastEventID := r.Header.Get("Last-Event-ID")
+	if lastEventID == "" {
+		lastEventID = r.URL.Query().Get("last_event_id")
+	}
+	var afterBlock uint64
+	if lastEventID != "" {
+		block, err := strconv.ParseUint(lastEventID, 10, 64)
+		if err != nil {
+			httpjson.WriteError(w, http.StatusBadRequest, "Invalid Last-Event-ID", httpjson.CodeInvalidParam)
+			return
+		}
+		afterBlock = block
+	}
+
+	// 長時間接続のため、サーバー全体の WriteTimeout をこのリクエストだけ解除する
+	rc := http.NewResponseController(w)
+	if err := rc.SetWriteDeadline(time.Time{}); err != nil {
+		log.Printf("WARNING: Failed to disable write deadline for event stream: %v", err)
+	}
+
+	events, unsubscribe := h.contractUC.SubscribeEvents(afterBlock)
+	defer unsubscribe()
+
+	w.Header().Set("Content-Type", "text/event-stream")
+	w.Header().Set("Cache-Control", "no-cache")
+	w.Header().Set("Connection", "keep-alive")
+	w.Header().Set("X-Accel-Buffering", "no")
+	w.WriteHeader(http.StatusOK)
+	fmt.Fprint(w, ": connected\n\n")
+	if err := rc.Flush(); err != nil {
+		log.Printf("ERROR: Event stream does not support flushing: %v", err)
+		return
+	}
+
+	heartbeat := time.NewTicker(streamHeartbeatInterval)
+	defer heartbeat.Stop()
+
+	for {
+		select {
+		case <-r.Context().Done():
+			return
+		case <-heartbeat.C:
+			fmt.Fprint(w, ": ping\n\n")
+		case event, ok := <-events:
+			if !ok {
+				// 低速による切断またはサーバー停止。クライアントは Last-Event-ID で再接続できる
+				return
+			}
+			data, err := json.Marshal(eventResponse(event))
+			if err != nil {
+				log.Printf("ERROR: Failed to marshal stream event: %v", err)
+				continue
+			}
+			fmt.Fprintf(w, "id: %d\nevent: %s\ndata: %s\n\n", event.BlockNo, event.Type, data)
+		}
+
+		if err := rc.Flush(); err != nil {
+			return
+		}
+	}
+}
+
+// eventResponse はPriceをstring形式に変換したストリーム配信用のマップを作成
+func eventResponse(event *model.ContractEvent) map[string]interface{} {
+	resp := map[string]interface{}{
+		"type":             event.Type,
+		"contract_address": event.ContractAddress,
+		"tx_hash":          event.TxHash,
+		"block_number":     event.BlockNo,
+		"block_time":       event.BlockTime,
+		"log_index":        event.LogIndex,
+		"removed":          event.Removed,
+		"item_id":          event.ItemId,
+		"token_id":         event.TokenId,
+		"title":            event.Title,
+		"explanation":      event.Explanation,
+		"image_url":        event.ImageUrl,
+		"category":         event.Category,
+		"seller":           event.Seller,
+		"buyer":            event.Buyer,
+		"created_at":       event.CreatedAt,
+		"updated_at":       event.UpdatedAt,
+	}
+	if event.Price != nil {
+		resp["price_wei"] = event.Price.String()
+	}
+	if event.OldPrice != nil {
+		resp["old_price_wei"] = event.OldPrice.String()
+	}
+	return resp
+}
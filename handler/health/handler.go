@@ -0,0 +1,69 @@
+package handler
+
+import (
+	"context"
+	"encoding/json"
+	"log"
+	"math/big"
+	"net/http"
+	"time"
+
+	"github.com/ethereum/go-ethereum/core/types"
+)
+
+// readinessTimeout はノード疎通確認のタイムアウト
+const readinessTimeout = 3 * time.Second
+
+// HeaderReader は最新ブロックヘッダーを取得できるノードクライアント（*ethclient.Client が満たす）
+type HeaderReader interface {
+	HeaderByNumber(ctx context.Context, number *big.Int) (*types.Header, error)
+}
+
+// EventSourceReporter は現在のイベント受信方式（WebSocket 購読・ポーリング）を返す
+type EventSourceReporter interface {
+	EventSourceMode() string
+}
+
+type HealthHandler struct {
+	client HeaderReader
+	events EventSourceReporter // nil の場合はイベント受信方式を返さない
+}
+
+func NewHealthHandler(client HeaderReader, events EventSourceReporter) *HealthHandler {
+	return &HealthHandler{client: client, events: events}
+}
+
+// HandleLiveness はプロセスが生きていることだけを返す
+func (h *HealthHandler) HandleLiveness(w http.ResponseWriter, r *http.Request) {
+	w.WriteHeader(http.StatusOK)
+	w.Write([]byte("OK"))
+}
+
+// HandleReadiness はEthereumノードに接続できるか確認し、できなければ503を返す
+// Cloud Run が接続の切れたインスタンスにトラフィックを流さないようにするため
+func (h *HealthHandler) HandleReadiness(w http.ResponseWriter, r *http.Request) {
+	ctx, cancel := context.WithTimeout(r.Context(), readinessTimeout)
+	defer cancel()
+
+	w.Header().Set("Content-Type", "application/json")
+
+	header, err := h.client.HeaderByNumber(ctx, nil)
+	if err != nil {
+		log.Printf("WARNING: Readiness check failed: %v", err)
+		w.WriteHeader(http.StatusServiceUnavailable)
+		json.NewEncoder(w).Encode(map[string]interface{}{
+			"status": "unavailable",
+			"error":  "ethereum node unreachable",
+		})
+		return
+	}
+
+	resp := map[string]interface{}{
+		"status":       "ok",
+		"latest_block": header.Number.Uint64(),
+	}
+	if h.events != nil {
+		resp["event_source"] = h.events.EventSourceMode()
+	}
+	json.NewEncoder(w).Encode(resp)
+}
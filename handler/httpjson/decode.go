@@ -0,0 +1,48 @@
+package httpjson
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+)
+
+// MaxBodyBytes はJSONリクエストボディの最大サイズ (1MB)
+const MaxBodyBytes = 1 << 20
+
+// DecodeStrict はリクエストボディを MaxBodyBytes までに制限し、未知のフィールドを許可せずにデコードする
+// 返すエラーのメッセージはそのまま400のレスポンスに使える
+func DecodeStrict(w http.ResponseWriter, r *http.Request, dst interface{}) error {
+	r.Body = http.MaxBytesReader(w, r.Body, MaxBodyBytes)
+
+	dec := json.NewDecoder(r.Body)
+	dec.DisallowUnknownFields()
+
+	if err := dec.Decode(dst); err != nil {
+		var maxBytesErr *http.MaxBytesError
+		var syntaxErr *json.SyntaxError
+		var typeErr *json.UnmarshalTypeError
+		switch {
+		case errors.As(err, &maxBytesErr):
+			return fmt.Errorf("request body too large (max %d bytes)", maxBytesErr.Limit)
+		case errors.Is(err, io.EOF):
+			return errors.New("request body is empty")
+		case errors.As(err, &syntaxErr):
+			return fmt.Errorf("malformed JSON at position %d", syntaxErr.Offset)
+		case errors.Is(err, io.ErrUnexpectedEOF):
+			return errors.New("malformed JSON: unexpected end of body")
+		case errors.As(err, &typeErr):
+			return fmt.Errorf("invalid type for field %q", typeErr.Field)
+		default:
+			// DisallowUnknownFields のエラーは `json: unknown field "xxx"` 形式
+			return fmt.Errorf("invalid request body: %v", err)
+		}
+	}
+
+	// 2つ目のJSON値が続く場合も不正なボディとして扱う
+	if err := dec.Decode(&struct{}{}); !errors.Is(err, io.EOF) {
+		return errors.New("request body must contain a single JSON object")
+	}
+	return nil
+}
@@ -0,0 +1,49 @@
+package httpjson
+
+import (
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+type decodeTarget struct {
+	TxHash string `json:"tx_hash"`
+	Amount int    `json:"amount"`
+}
+
+func TestDecodeStrict(t *testing.T) {
+	tests := []struct {
+		name    string
+		body    string
+		wantErr string // 空の場合は成功を期待する
+	}{
+		{"valid", `{"tx_hash":"0xabc","amount":1}`, ""},
+		{"too large", `{"tx_hash":"` + strings.Repeat("a", MaxBodyBytes) + `"}`, "request body too large"},
+		{"empty", ``, "request body is empty"},
+		{"malformed", `{"tx_hash":}`, "malformed JSON at position"},
+		{"truncated", `{"tx_hash":"0xabc"`, "unexpected end of body"},
+		{"wrong type", `{"amount":"one"}`, `invalid type for field "amount"`},
+		{"unknown field", `{"tx_hash":"0xabc","txHash":"0xabc"}`, `unknown field "txHash"`},
+		{"trailing value", `{"tx_hash":"0xabc"} {}`, "single JSON object"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := httptest.NewRequest("POST", "/", strings.NewReader(tt.body))
+			var dst decodeTarget
+			err := DecodeStrict(httptest.NewRecorder(), r, &dst)
+			if tt.wantErr == "" {
+				if err != nil {
+					t.Fatalf("err = %v, want nil", err)
+				}
+				if dst.TxHash != "0xabc" || dst.Amount != 1 {
+					t.Errorf("decoded %+v", dst)
+				}
+				return
+			}
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("err = %v, want containing %q", err, tt.wantErr)
+			}
+		})
+	}
+}
@@ -0,0 +1,64 @@
+package httpjson
+
+import (
+	"encoding/json"
+	"net/http"
+)
+
+// エラーレスポンスの code（フロントエンドはこのコードで案内を出し分ける）
+const (
+	// 共通
+	CodeInvalidRequest = "invalid_request"   // ボディ・必須項目の不備
+	CodeInvalidParam   = "invalid_parameter" // パス・クエリパラメータの不備
+	CodeInternal       = "internal_error"
+	CodeRequestTimeout = "request_timeout"
+	CodeRateLimited    = "rate_limited"
+	CodeUnauthorized   = "unauthorized"
+	CodeAdminDisabled  = "admin_disabled" // ADMIN_API_TOKEN が未設定
+
+	// コントラクト
+	CodeInvalidItemID       = "invalid_item_id"
+	CodeInvalidAddress      = "invalid_address"
+	CodeCallTimeout         = "call_timeout"
+	CodeContractUnavailable = "contract_unavailable"
+	CodeRelayerDisabled     = "relayer_disabled"
+	CodeItemNotForSale      = "item_not_for_sale"
+	CodeInvalidListing      = "invalid_listing"
+	CodeInvalidBlockRange   = "invalid_block_range"
+	CodeItemNotFound        = "item_not_found"
+	CodeTokenNotFound       = "token_not_found"
+	CodeInvalidEvent        = "invalid_event"
+	CodeNotifyFailed        = "notify_failed"
+
+	// 決済
+	CodeInvalidBuyerWallet   = "invalid_buyer_wallet"
+	CodeInvalidTxHash        = "invalid_tx_hash"
+	CodeTxNotFound           = "tx_not_found"
+	CodeTxPending            = "tx_pending"
+	CodeTxDropped            = "tx_dropped"
+	CodeTxReverted           = "tx_reverted"
+	CodeInsufficientAmount   = "insufficient_amount"
+	CodeWrongRecipient       = "wrong_recipient"
+	CodeWrongSender          = "wrong_sender"
+	CodeProductNotFound      = "product_not_found"
+	CodeVariantNotFound      = "variant_not_found"
+	CodeProductLookupTimeout = "product_lookup_timeout"
+	CodeOrderNotFound        = "order_not_found"
+	CodeOrderExpired         = "order_expired"
+	CodeOrderAlreadyPaid     = "order_already_paid"
+	CodeOrderStoreDisabled   = "order_store_disabled"
+	CodeSelfTestUnavailable  = "self_test_unavailable"
+	CodeNotTestnet           = "not_testnet"
+)
+
+// WriteError はエラーを {"error": メッセージ, "code": エラーコード} 形式のJSONで返す
+// 成功時のレスポンスと同じくJSONとして扱えるよう、http.Error（text/plain）の代わりに使う
+func WriteError(w http.ResponseWriter, status int, message, code string) {
+	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("X-Content-Type-Options", "nosniff")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(map[string]string{
+		"error": message,
+		"code":  code,
+	})
+}
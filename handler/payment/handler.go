@@ -2,9 +2,18 @@ package handler
 
 import (
 	"encoding/json"
+	"errors"
+	"log"
 	"net/http"
+	"strconv"
+	"time"
 
+	"uttc-hack-back-onchain/gateway/payment"
+	"uttc-hack-back-onchain/handler/httpjson"
+	"uttc-hack-back-onchain/model"
 	"uttc-hack-back-onchain/usecase/payment"
+
+	"github.com/gorilla/mux"
 )
 
 type PaymentHandler struct {
@@ -18,21 +27,34 @@ func NewPaymentHandler(uc usecase.PaymentUsecase) *PaymentHandler {
 // CreateOrderRequest は決済開始APIの入力
 type CreateOrderRequest struct {
 	ProductID   string `json:"product_id"`
+	Variant     string `json:"variant"` // 任意: 商品バリエーション（SKU）
 	BuyerWallet string `json:"buyer_wallet"`
 }
 
 // HandleCreatePaymentOrder は注文を作成し、必要な支払い情報を返す
 func (h *PaymentHandler) HandleCreatePaymentOrder(w http.ResponseWriter, r *http.Request) {
 	var req CreateOrderRequest
-	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
-		http.Error(w, "Invalid request body", http.StatusBadRequest)
+	if err := httpjson.DecodeStrict(w, r, &req); err != nil {
+		httpjson.WriteError(w, http.StatusBadRequest, err.Error(), httpjson.CodeInvalidRequest)
 		return
 	}
 
-	// Usecaseにビジネスロジックを委譲
-	order, err := h.paymentUC.CreatePaymentOrder(r.Context(), req.ProductID, req.BuyerWallet)
+	// Usecaseにビジネスロジックを委譲（Idempotency-Key があれば再送時に同じ注文を返す）
+	idempotencyKey := r.Header.Get("Idempotency-Key")
+	order, err := h.paymentUC.CreatePaymentOrder(r.Context(), req.ProductID, req.Variant, req.BuyerWallet, idempotencyKey)
 	if err != nil {
-		http.Error(w, err.Error(), http.StatusInternalServerError)
+		switch {
+		case errors.Is(err, usecase.ErrInvalidBuyerWallet):
+			httpjson.WriteError(w, http.StatusBadRequest, err.Error(), httpjson.CodeInvalidBuyerWallet)
+		case errors.Is(err, gateway.ErrProductNotFound):
+			httpjson.WriteError(w, http.StatusNotFound, err.Error(), httpjson.CodeProductNotFound)
+		case errors.Is(err, gateway.ErrVariantNotFound):
+			httpjson.WriteError(w, http.StatusNotFound, err.Error(), httpjson.CodeVariantNotFound)
+		case errors.Is(err, gateway.ErrProductLookupTimeout):
+			httpjson.WriteError(w, http.StatusGatewayTimeout, err.Error(), httpjson.CodeProductLookupTimeout)
+		default:
+			httpjson.WriteError(w, http.StatusInternalServerError, err.Error(), httpjson.CodeInternal)
+		}
 		return
 	}
 
@@ -40,28 +62,228 @@ func (h *PaymentHandler) HandleCreatePaymentOrder(w http.ResponseWriter, r *http
 	json.NewEncoder(w).Encode(order)
 }
 
+// HandleGetQuote は注文を作成せずに商品の価格と支払い金額を返す
+// product_id（必須）・variant クエリで指定する
+func (h *PaymentHandler) HandleGetQuote(w http.ResponseWriter, r *http.Request) {
+	query := r.URL.Query()
+	productID := query.Get("product_id")
+	if productID == "" {
+		httpjson.WriteError(w, http.StatusBadRequest, "product_id is required", httpjson.CodeInvalidParam)
+		return
+	}
+
+	quote, err := h.paymentUC.GetQuote(r.Context(), productID, query.Get("variant"))
+	if err != nil {
+		writeConfirmError(w, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(quote)
+}
+
 // ConfirmPaymentRequest は支払い確定APIの入力
 type ConfirmPaymentRequest struct {
-	OrderID   string `json:"order_id"`
-	ProductID string `json:"product_id"`
-	TxHash    string `json:"tx_hash"`
+	OrderID     string `json:"order_id"`
+	ProductID   string `json:"product_id"`
+	Variant     string `json:"variant"` // 注文ストアに注文が無い場合のフォールバック
+	TxHash      string `json:"tx_hash"`
+	BuyerWallet string `json:"buyer_wallet"` // 注文ストアに注文が無い場合のフォールバック
 }
 
 // HandleConfirmPayment はJPYC支払いトランザクションを検証する
 func (h *PaymentHandler) HandleConfirmPayment(w http.ResponseWriter, r *http.Request) {
 	var req ConfirmPaymentRequest
-	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
-		http.Error(w, "Invalid request body", http.StatusBadRequest)
+	if err := httpjson.DecodeStrict(w, r, &req); err != nil {
+		httpjson.WriteError(w, http.StatusBadRequest, err.Error(), httpjson.CodeInvalidRequest)
+		return
+	}
+
+	// ?dry_run=true の場合は検証レポートのみを返し、注文は確定しない
+	if r.URL.Query().Get("dry_run") == "true" {
+		report, err := h.paymentUC.DryRunConfirmPayment(r.Context(), req.OrderID, req.ProductID, req.Variant, req.TxHash, req.BuyerWallet)
+		if err != nil {
+			writeConfirmError(w, err)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(report)
 		return
 	}
 
 	// Usecaseにビジネスロジックを委譲
-	order, err := h.paymentUC.ConfirmPayment(r.Context(), req.OrderID, req.ProductID, req.TxHash)
+	// ?wait=true の場合は必要な確認数に達するまで（またはタイムアウトまで）待ってから確定する
+	confirm := h.paymentUC.ConfirmPayment
+	if r.URL.Query().Get("wait") == "true" {
+		confirm = h.paymentUC.ConfirmPaymentAndWait
+		// 待機はユースケース側のタイムアウトで打ち切られるため、サーバー全体の WriteTimeout はこのリクエストだけ解除する
+		if err := http.NewResponseController(w).SetWriteDeadline(time.Time{}); err != nil {
+			log.Printf("WARNING: Failed to disable write deadline for confirm wait: %v", err)
+		}
+	}
+	order, err := confirm(r.Context(), req.OrderID, req.ProductID, req.Variant, req.TxHash, req.BuyerWallet)
 	if err != nil {
-		http.Error(w, err.Error(), http.StatusInternalServerError)
+		writeConfirmError(w, err)
 		return
 	}
 
 	w.Header().Set("Content-Type", "application/json")
 	json.NewEncoder(w).Encode(order)
 }
+
+const (
+	// defaultListLimit は注文一覧の1ページあたりのデフォルト件数
+	defaultListLimit = 20
+	// maxListLimit は注文一覧の1ページあたりの最大件数
+	maxListLimit = 100
+)
+
+// HandleGetOrder は作成済みの注文を現在のステータスとともに返す（リロード後の状態確認用）
+func (h *PaymentHandler) HandleGetOrder(w http.ResponseWriter, r *http.Request) {
+	order, err := h.paymentUC.GetOrder(r.Context(), mux.Vars(r)["orderID"])
+	if err != nil {
+		switch {
+		case errors.Is(err, usecase.ErrOrderNotFound):
+			httpjson.WriteError(w, http.StatusNotFound, err.Error(), httpjson.CodeOrderNotFound)
+		case errors.Is(err, usecase.ErrOrderStoreDisabled):
+			httpjson.WriteError(w, http.StatusServiceUnavailable, err.Error(), httpjson.CodeOrderStoreDisabled)
+		default:
+			httpjson.WriteError(w, http.StatusInternalServerError, err.Error(), httpjson.CodeInternal)
+		}
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(order)
+}
+
+// HandleListOrders は注文を作成日時の新しい順にページングして返す
+// status・product_id・buyer_wallet クエリで絞り込める
+func (h *PaymentHandler) HandleListOrders(w http.ResponseWriter, r *http.Request) {
+	offset, err := parseIntQuery(r, "offset", 0)
+	if err != nil || offset < 0 {
+		httpjson.WriteError(w, http.StatusBadRequest, "Invalid offset", httpjson.CodeInvalidParam)
+		return
+	}
+	limit, err := parseIntQuery(r, "limit", defaultListLimit)
+	if err != nil || limit <= 0 {
+		httpjson.WriteError(w, http.StatusBadRequest, "Invalid limit", httpjson.CodeInvalidParam)
+		return
+	}
+	if limit > maxListLimit {
+		limit = maxListLimit
+	}
+
+	query := r.URL.Query()
+	filter := usecase.OrderFilter{
+		Status:      model.OrderStatus(query.Get("status")),
+		ProductID:   query.Get("product_id"),
+		BuyerWallet: query.Get("buyer_wallet"),
+	}
+	switch filter.Status {
+	case "", model.StatusPending, model.StatusPaid, model.StatusError:
+	default:
+		httpjson.WriteError(w, http.StatusBadRequest, "Invalid status", httpjson.CodeInvalidParam)
+		return
+	}
+
+	orders, total, err := h.paymentUC.ListOrders(r.Context(), filter, offset, limit)
+	if err != nil {
+		switch {
+		case errors.Is(err, usecase.ErrInvalidBuyerWallet):
+			httpjson.WriteError(w, http.StatusBadRequest, err.Error(), httpjson.CodeInvalidBuyerWallet)
+		case errors.Is(err, usecase.ErrOrderStoreDisabled):
+			httpjson.WriteError(w, http.StatusServiceUnavailable, err.Error(), httpjson.CodeOrderStoreDisabled)
+		default:
+			httpjson.WriteError(w, http.StatusInternalServerError, err.Error(), httpjson.CodeInternal)
+		}
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"orders": orders,
+		"total":  total,
+		"offset": offset,
+		"limit":  limit,
+	})
+}
+
+// parseIntQuery はクエリパラメータを整数として読み取る（未指定ならデフォルト値）
+func parseIntQuery(r *http.Request, key string, defaultValue int) (int, error) {
+	value := r.URL.Query().Get(key)
+	if value == "" {
+		return defaultValue, nil
+	}
+	return strconv.Atoi(value)
+}
+
+// confirmErrors は支払い検証の失敗理由ごとのHTTPステータスとエラーコード
+// フロントエンドはエラーコードでユーザーへの案内を出し分ける
+var confirmErrors = []struct {
+	err    error
+	status int
+	code   string
+}{
+	{gateway.ErrInvalidTxHash, http.StatusBadRequest, httpjson.CodeInvalidTxHash},
+	{gateway.ErrTxNotFound, http.StatusNotFound, httpjson.CodeTxNotFound},
+	{gateway.ErrTxPending, http.StatusConflict, httpjson.CodeTxPending},
+	{gateway.ErrTxDropped, http.StatusGone, httpjson.CodeTxDropped},
+	{gateway.ErrTxReverted, http.StatusUnprocessableEntity, httpjson.CodeTxReverted},
+	{gateway.ErrInsufficientAmount, http.StatusPaymentRequired, httpjson.CodeInsufficientAmount},
+	{gateway.ErrWrongRecipient, http.StatusUnprocessableEntity, httpjson.CodeWrongRecipient},
+	{gateway.ErrWrongSender, http.StatusUnprocessableEntity, httpjson.CodeWrongSender},
+	{gateway.ErrProductNotFound, http.StatusNotFound, httpjson.CodeProductNotFound},
+	{gateway.ErrVariantNotFound, http.StatusNotFound, httpjson.CodeVariantNotFound},
+	{gateway.ErrProductLookupTimeout, http.StatusGatewayTimeout, httpjson.CodeProductLookupTimeout},
+	{usecase.ErrOrderExpired, http.StatusGone, httpjson.CodeOrderExpired},
+	{usecase.ErrOrderAlreadyPaid, http.StatusConflict, httpjson.CodeOrderAlreadyPaid},
+}
+
+// writeConfirmError は支払い確定の失敗を失敗理由ごとのHTTPステータスとエラーコードで返す
+func writeConfirmError(w http.ResponseWriter, err error) {
+	status, code := http.StatusInternalServerError, httpjson.CodeInternal
+	for _, e := range confirmErrors {
+		if errors.Is(err, e.err) {
+			status, code = e.status, e.code
+			break
+		}
+	}
+	httpjson.WriteError(w, status, err.Error(), code)
+}
+
+// HandleGetCollectedBalance は集金用ウォレットの残高を Wei と ETH で返す（管理者用）
+func (h *PaymentHandler) HandleGetCollectedBalance(w http.ResponseWriter, r *http.Request) {
+	balance, err := h.paymentUC.GetCollectedBalance(r.Context())
+	if err != nil {
+		httpjson.WriteError(w, http.StatusInternalServerError, err.Error(), httpjson.CodeInternal)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(balance)
+}
+
+// HandleSelfTest はテストネット上で決済検証パイプラインのセルフテストを実行する
+func (h *PaymentHandler) HandleSelfTest(w http.ResponseWriter, r *http.Request) {
+	// 送金のマイニング待ちはユースケース側のタイムアウトで打ち切られるため、サーバー全体の WriteTimeout はこのリクエストだけ解除する
+	if err := http.NewResponseController(w).SetWriteDeadline(time.Time{}); err != nil {
+		log.Printf("WARNING: Failed to disable write deadline for payment self-test: %v", err)
+	}
+
+	result, err := h.paymentUC.RunSelfTest(r.Context())
+	if err != nil {
+		switch {
+		case errors.Is(err, usecase.ErrSelfTestUnavailable):
+			httpjson.WriteError(w, http.StatusServiceUnavailable, err.Error(), httpjson.CodeSelfTestUnavailable)
+		case errors.Is(err, usecase.ErrNotTestnet):
+			httpjson.WriteError(w, http.StatusForbidden, err.Error(), httpjson.CodeNotTestnet)
+		default:
+			httpjson.WriteError(w, http.StatusInternalServerError, err.Error(), httpjson.CodeInternal)
+		}
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(result)
+}
@@ -0,0 +1,195 @@
+package httpclient
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+)
+
+// EndpointHealth は RPC エンドポイントごとの状態（ステータス表示用、URL は API キーを除いたもの）
+type EndpointHealth struct {
+	URL                 string
+	Active              bool
+	ConsecutiveFailures int
+	LastError           string
+	LastFailureAt       time.Time
+}
+
+type endpointState struct {
+	url           *url.URL
+	failures      int
+	lastError     string
+	lastFailureAt time.Time
+}
+
+// FailoverTransport は複数の JSON-RPC エンドポイントを切り替えて送信する RoundTripper
+// 接続エラー・5xx・429 の場合は次のエンドポイントに切り替えて同じリクエストを再送する。
+// 切り替えたエンドポイントは失敗するまで使い続ける（元のエンドポイントには自動では戻らない）。
+// リクエストの URL は無視して現在のエンドポイントに送るため、rpc.DialOptions には先頭の URL を渡せばよい
+type FailoverTransport struct {
+	base http.RoundTripper
+
+	mu        sync.Mutex
+	endpoints []*endpointState
+	active    int
+}
+
+// NewFailoverTransport は rawURLs の順にエンドポイントを使う FailoverTransport を作成する
+// base は実際の送信に使うトランスポート（nil の場合は http.DefaultTransport）
+func NewFailoverTransport(rawURLs []string, base http.RoundTripper) (*FailoverTransport, error) {
+	if len(rawURLs) == 0 {
+		return nil, errors.New("no rpc endpoints configured")
+	}
+	if base == nil {
+		base = http.DefaultTransport
+	}
+
+	endpoints := make([]*endpointState, 0, len(rawURLs))
+	for _, raw := range rawURLs {
+		u, err := url.Parse(raw)
+		if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+			return nil, fmt.Errorf("invalid rpc endpoint %q", RedactURL(raw))
+		}
+		endpoints = append(endpoints, &endpointState{url: u})
+	}
+	return &FailoverTransport{base: base, endpoints: endpoints}, nil
+}
+
+// RoundTrip は現在のエンドポイントに送信し、失敗した場合は残りのエンドポイントを順に試す
+func (t *FailoverTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	// 再送のためにボディを読み込んでおく（JSON-RPC のリクエストは小さい）
+	var body []byte
+	if req.Body != nil {
+		b, err := io.ReadAll(req.Body)
+		req.Body.Close()
+		if err != nil {
+			return nil, err
+		}
+		body = b
+	}
+
+	var lastResp *http.Response
+	var lastErr error
+	for attempt := 0; attempt < len(t.endpoints); attempt++ {
+		index, endpoint := t.current()
+
+		outReq := req.Clone(req.Context())
+		outReq.URL = endpoint
+		outReq.Host = ""
+		if body != nil {
+			outReq.Body = io.NopCloser(bytes.NewReader(body))
+			outReq.ContentLength = int64(len(body))
+		}
+
+		resp, err := t.base.RoundTrip(outReq)
+		if err == nil && resp.StatusCode < 500 && resp.StatusCode != http.StatusTooManyRequests {
+			t.recordSuccess(index)
+			return resp, nil
+		}
+		// 呼び出し元のキャンセル・タイムアウトはエンドポイントの障害ではないため切り替えない
+		if req.Context().Err() != nil {
+			if err != nil {
+				return nil, err
+			}
+			return resp, nil
+		}
+
+		if err != nil {
+			t.recordFailure(index, err.Error())
+		} else {
+			t.recordFailure(index, fmt.Sprintf("status %d", resp.StatusCode))
+		}
+
+		if lastResp != nil {
+			lastResp.Body.Close()
+		}
+		lastResp, lastErr = resp, err
+	}
+
+	// すべて失敗した場合は最後の結果をそのまま返す
+	if lastErr != nil {
+		return nil, lastErr
+	}
+	return lastResp, nil
+}
+
+// ActiveEndpoint は現在使っているエンドポイント（API キーを除いた URL）を返す
+func (t *FailoverTransport) ActiveEndpoint() string {
+	_, endpoint := t.current()
+	return RedactURL(endpoint.String())
+}
+
+// Health はすべてのエンドポイントの状態を設定順に返す
+func (t *FailoverTransport) Health() []EndpointHealth {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	health := make([]EndpointHealth, 0, len(t.endpoints))
+	for i, e := range t.endpoints {
+		health = append(health, EndpointHealth{
+			URL:                 RedactURL(e.url.String()),
+			Active:              i == t.active,
+			ConsecutiveFailures: e.failures,
+			LastError:           e.lastError,
+			LastFailureAt:       e.lastFailureAt,
+		})
+	}
+	return health
+}
+
+func (t *FailoverTransport) current() (int, *url.URL) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	return t.active, t.endpoints[t.active].url
+}
+
+func (t *FailoverTransport) recordSuccess(index int) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.endpoints[index].failures = 0
+}
+
+// recordFailure は失敗を記録し、まだ切り替えていなければ次のエンドポイントに切り替える
+// 同じエンドポイントへの並行リクエストが同時に失敗しても、切り替えは1回だけにする
+func (t *FailoverTransport) recordFailure(index int, reason string) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	e := t.endpoints[index]
+	e.failures++
+	e.lastError = reason
+	e.lastFailureAt = time.Now()
+
+	if len(t.endpoints) > 1 && t.active == index {
+		t.active = (index + 1) % len(t.endpoints)
+		log.Printf("WARNING: RPC endpoint %s failed (%s), switching to %s",
+			RedactURL(e.url.String()), reason, RedactURL(t.endpoints[t.active].url.String()))
+	}
+}
+
+// RedactURL は URL からパス・クエリ（Infura の API キーなど）を除いてスキームとホストだけを返す
+func RedactURL(raw string) string {
+	u, err := url.Parse(raw)
+	if err != nil || u.Host == "" {
+		return "<invalid url>"
+	}
+	return u.Scheme + "://" + u.Host
+}
+
+// SplitURLs はカンマ区切りの URL のリストを分割する（空要素は無視する）
+func SplitURLs(value string) []string {
+	var urls []string
+	for _, raw := range strings.Split(value, ",") {
+		if raw = strings.TrimSpace(raw); raw != "" {
+			urls = append(urls, raw)
+		}
+	}
+	return urls
+}
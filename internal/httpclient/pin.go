@@ -0,0 +1,59 @@
+package httpclient
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"crypto/tls"
+	"crypto/x509"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+)
+
+// ErrCertPinMismatch はサーバー証明書が設定されたフィンガープリントのいずれとも一致しない
+var ErrCertPinMismatch = errors.New("server certificate does not match any pinned fingerprint")
+
+// ParseCertPins はカンマ区切りの SHA-256 フィンガープリント（16進数、":" 区切り可）を読み取る
+// `openssl x509 -noout -fingerprint -sha256` の出力をそのまま指定できる
+func ParseCertPins(value string) ([][]byte, error) {
+	var pins [][]byte
+	for _, raw := range strings.Split(value, ",") {
+		raw = strings.TrimSpace(raw)
+		if raw == "" {
+			continue
+		}
+		pin, err := hex.DecodeString(strings.ReplaceAll(raw, ":", ""))
+		if err != nil || len(pin) != sha256.Size {
+			return nil, fmt.Errorf("invalid SHA-256 fingerprint %q", raw)
+		}
+		pins = append(pins, pin)
+	}
+	return pins, nil
+}
+
+// WithCertPins は通常の証明書検証に加えて、サーバー証明書（リーフ）の SHA-256 が pins のいずれかと
+// 一致することを要求するトランスポートを返す。base は変更せず複製する
+//
+// 証明書を更新するとフィンガープリントが変わり、ピンを更新するまで接続がすべて失敗する。
+// 更新前に新しい証明書のフィンガープリントを追加し、切り替え後に古いものを外すこと
+func WithCertPins(base *http.Transport, pins [][]byte) *http.Transport {
+	transport := base.Clone()
+	if transport.TLSClientConfig == nil {
+		transport.TLSClientConfig = &tls.Config{}
+	}
+	transport.TLSClientConfig.VerifyPeerCertificate = func(rawCerts [][]byte, _ [][]*x509.Certificate) error {
+		if len(rawCerts) == 0 {
+			return ErrCertPinMismatch
+		}
+		sum := sha256.Sum256(rawCerts[0])
+		for _, pin := range pins {
+			if bytes.Equal(sum[:], pin) {
+				return nil
+			}
+		}
+		return fmt.Errorf("%w (got %s)", ErrCertPinMismatch, hex.EncodeToString(sum[:]))
+	}
+	return transport
+}
@@ -0,0 +1,89 @@
+package httpclient
+
+import (
+	"crypto/sha256"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+// newPinnedServer は自己署名証明書の TLS サーバーと、その証明書の SHA-256 を返す
+func newPinnedServer(t *testing.T) (*httptest.Server, []byte) {
+	t.Helper()
+	srv := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+	t.Cleanup(srv.Close)
+	sum := sha256.Sum256(srv.Certificate().Raw)
+	return srv, sum[:]
+}
+
+func get(transport http.RoundTripper, url string) error {
+	resp, err := (&http.Client{Transport: transport}).Get(url)
+	if err != nil {
+		return err
+	}
+	resp.Body.Close()
+	return nil
+}
+
+func TestWithCertPinsAcceptsPinnedCertificate(t *testing.T) {
+	srv, pin := newPinnedServer(t)
+	other := sha256.Sum256([]byte("previous certificate"))
+
+	// ローテーション中は新旧どちらのピンでも接続できる
+	transport := WithCertPins(srv.Client().Transport.(*http.Transport), [][]byte{other[:], pin})
+	if err := get(transport, srv.URL); err != nil {
+		t.Fatalf("request with matching pin failed: %v", err)
+	}
+}
+
+func TestWithCertPinsRejectsOtherCertificate(t *testing.T) {
+	srv, _ := newPinnedServer(t)
+	other := sha256.Sum256([]byte("another certificate"))
+
+	transport := WithCertPins(srv.Client().Transport.(*http.Transport), [][]byte{other[:]})
+	err := get(transport, srv.URL)
+	if !errors.Is(err, ErrCertPinMismatch) {
+		t.Fatalf("err = %v, want ErrCertPinMismatch", err)
+	}
+}
+
+func TestWithCertPinsKeepsChainVerification(t *testing.T) {
+	srv, pin := newPinnedServer(t)
+
+	// ピンが一致しても、信頼されていない証明書は通常の検証で拒否される
+	transport := WithCertPins(http.DefaultTransport.(*http.Transport), [][]byte{pin})
+	if err := get(transport, srv.URL); err == nil {
+		t.Fatal("request to an untrusted self-signed server succeeded")
+	}
+}
+
+func TestWithCertPinsDoesNotModifyBase(t *testing.T) {
+	srv, pin := newPinnedServer(t)
+	base := srv.Client().Transport.(*http.Transport)
+
+	WithCertPins(base, [][]byte{pin})
+	if base.TLSClientConfig.VerifyPeerCertificate != nil {
+		t.Error("base transport was modified")
+	}
+}
+
+func TestParseCertPins(t *testing.T) {
+	colon := strings.Repeat("AB:", sha256.Size-1) + "AB"
+	plain := strings.Repeat("cd", sha256.Size)
+
+	pins, err := ParseCertPins(colon + ", " + plain + ",")
+	if err != nil {
+		t.Fatalf("ParseCertPins: %v", err)
+	}
+	if len(pins) != 2 || pins[0][0] != 0xab || pins[1][0] != 0xcd {
+		t.Errorf("pins = %x", pins)
+	}
+
+	for _, value := range []string{"abcd", strings.Repeat("zz", sha256.Size), strings.Repeat("ab", sha256.Size+1)} {
+		if _, err := ParseCertPins(value); err == nil {
+			t.Errorf("ParseCertPins(%q) succeeded, want error", value)
+		}
+	}
+}
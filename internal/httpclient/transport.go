@@ -0,0 +1,46 @@
+package httpclient
+
+import (
+	"net"
+	"net/http"
+	"time"
+)
+
+// TransportConfig は共有 HTTP トランスポートのコネクションプール設定
+type TransportConfig struct {
+	// MaxIdleConns は全ホスト合計で保持するアイドル接続数の上限
+	MaxIdleConns int
+	// MaxIdleConnsPerHost はホスト（Infura・バックエンド）ごとに保持するアイドル接続数の上限
+	// イベントが集中した際の通知で接続を張り直さないよう、net/http のデフォルト（2）より大きくする
+	MaxIdleConnsPerHost int
+	// IdleConnTimeout はアイドル接続を閉じるまでの時間
+	IdleConnTimeout time.Duration
+}
+
+// DefaultTransportConfig はデフォルトのプール設定を返す
+func DefaultTransportConfig() TransportConfig {
+	return TransportConfig{
+		MaxIdleConns:        100,
+		MaxIdleConnsPerHost: 20,
+		IdleConnTimeout:     90 * time.Second,
+	}
+}
+
+// NewTransport はノード（Infura）とバックエンドへの呼び出しで共有するトランスポートを作成する
+// 接続を使い回して TLS ハンドシェイクを減らすため、呼び出しごとに http.Client を作る場合もこれを渡すこと。
+// リクエスト全体のタイムアウトは各 http.Client の Timeout（またはコンテキスト）で設定する
+func NewTransport(cfg TransportConfig) *http.Transport {
+	return &http.Transport{
+		Proxy: http.ProxyFromEnvironment,
+		DialContext: (&net.Dialer{
+			Timeout:   10 * time.Second,
+			KeepAlive: 30 * time.Second,
+		}).DialContext,
+		ForceAttemptHTTP2:     true,
+		MaxIdleConns:          cfg.MaxIdleConns,
+		MaxIdleConnsPerHost:   cfg.MaxIdleConnsPerHost,
+		IdleConnTimeout:       cfg.IdleConnTimeout,
+		TLSHandshakeTimeout:   10 * time.Second,
+		ExpectContinueTimeout: 1 * time.Second,
+	}
+}
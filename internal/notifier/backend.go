@@ -0,0 +1,96 @@
+package notifier
+
+import (
+	"context"
+	"log"
+	"net/http"
+	"time"
+)
+
+// DefaultTimeout はバックエンドへの1リクエストあたりのタイムアウトのデフォルト
+const DefaultTimeout = 10 * time.Second
+
+type traceIDKey struct{}
+
+// WithTraceID は通知に X-Trace-Id ヘッダーとして付与するトレースIDを ctx に設定する
+// バックエンド側のログとも相関できるようにするため
+func WithTraceID(ctx context.Context, traceID string) context.Context {
+	return context.WithValue(ctx, traceIDKey{}, traceID)
+}
+
+// BackendNotifier はメインバックエンドへの通知（JSON POST）を送信する
+// 決済とコントラクトの両ユースケースで共有し、リトライ・署名の挙動を揃える
+type BackendNotifier struct {
+	baseURL string
+	client  *http.Client
+	retry   RetryConfig
+	secret  []byte          // 署名用共有シークレット（空なら署名しない）
+	breaker *circuitBreaker // nil の場合はバックエンドの障害中も毎回送信を試みる
+}
+
+// NewBackendNotifier はバックエンド通知クライアントを作成
+// timeout が0以下の場合は DefaultTimeout を使う
+// transport は接続を使い回すための共有トランスポート（nil の場合は http.DefaultTransport）
+// breaker.Threshold が0以下の場合はサーキットブレーカーを無効化する
+func NewBackendNotifier(baseURL string, timeout time.Duration, retry RetryConfig, secret string, transport http.RoundTripper, breaker BreakerConfig) *BackendNotifier {
+	if timeout <= 0 {
+		timeout = DefaultTimeout
+	}
+	if retry.MaxAttempts <= 0 {
+		retry.MaxAttempts = DefaultMaxAttempts
+	}
+	if secret == "" {
+		log.Println("WARNING: Webhook secret not set. Backend notifications will NOT be signed.")
+	}
+
+	return &BackendNotifier{
+		baseURL: baseURL,
+		client:  &http.Client{Timeout: timeout, Transport: transport},
+		retry:   retry,
+		secret:  []byte(secret),
+		breaker: newCircuitBreaker(breaker),
+	}
+}
+
+// Transport はバックエンドへの接続に使うトランスポートを返す
+// 通知以外でバックエンドを呼び出す場合も同じコネクションプールを使うため
+func (n *BackendNotifier) Transport() http.RoundTripper {
+	return n.client.Transport
+}
+
+// BaseURL は通知先のベースURLを返す
+func (n *BackendNotifier) BaseURL() string {
+	return n.baseURL
+}
+
+// BreakerStats はサーキットブレーカーの状態を返す
+func (n *BackendNotifier) BreakerStats() BreakerStats {
+	if n.breaker == nil {
+		return BreakerStats{State: BreakerDisabled}
+	}
+	return n.breaker.stats()
+}
+
+// Post は baseURL + endpoint に payload をPOSTする（リトライの挙動は PostJSON を参照）
+// サーキットブレーカーが開いている間は送信せずに ErrCircuitOpen を返す
+func (n *BackendNotifier) Post(ctx context.Context, endpoint string, payload interface{}) error {
+	if n.breaker == nil {
+		return n.post(ctx, endpoint, payload)
+	}
+	if err := n.breaker.allow(); err != nil {
+		return err
+	}
+	err := n.post(ctx, endpoint, payload)
+	n.breaker.record(err)
+	return err
+}
+
+func (n *BackendNotifier) post(ctx context.Context, endpoint string, payload interface{}) error {
+	traceID, _ := ctx.Value(traceIDKey{}).(string)
+	return PostJSON(ctx, n.client, n.baseURL+endpoint, payload, n.retry, func(req *http.Request, body []byte) {
+		if traceID != "" {
+			req.Header.Set("X-Trace-Id", traceID)
+		}
+		SignRequest(req, body, n.secret)
+	})
+}
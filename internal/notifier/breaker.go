@@ -0,0 +1,156 @@
+package notifier
+
+import (
+	"context"
+	"errors"
+	"log"
+	"net/http"
+	"sync"
+	"time"
+)
+
+const (
+	// DefaultBreakerThreshold はサーキットブレーカーが開くまでの連続失敗回数のデフォルト
+	DefaultBreakerThreshold = 5
+	// DefaultBreakerCooldown はサーキットブレーカーが開いてから試行を再開するまでの時間のデフォルト
+	DefaultBreakerCooldown = 1 * time.Minute
+)
+
+// サーキットブレーカーの状態（BreakerStats.State の値）
+const (
+	BreakerDisabled = "disabled"
+	BreakerClosed   = "closed"    // 通常どおり送信する
+	BreakerOpen     = "open"      // 送信せずに ErrCircuitOpen を返す
+	BreakerHalfOpen = "half_open" // 復旧確認のため1件だけ送信する
+)
+
+// ErrCircuitOpen はバックエンドの障害が続いているため、通知を送信せずに破棄した
+var ErrCircuitOpen = errors.New("backend notifier circuit is open")
+
+// BreakerConfig はバックエンド通知のサーキットブレーカーの設定
+// Threshold が0以下の場合はブレーカーを無効化する
+type BreakerConfig struct {
+	Threshold int
+	Cooldown  time.Duration
+}
+
+// DefaultBreakerConfig はデフォルトのサーキットブレーカー設定を返す
+func DefaultBreakerConfig() BreakerConfig {
+	return BreakerConfig{
+		Threshold: DefaultBreakerThreshold,
+		Cooldown:  DefaultBreakerCooldown,
+	}
+}
+
+// BreakerStats はサーキットブレーカーの状態（ステータス表示用）
+type BreakerStats struct {
+	State               string
+	ConsecutiveFailures int
+	OpenedAt            time.Time // 最後に開いた時刻（一度も開いていなければゼロ値）
+	Rejected            uint64    // 開いている間に送信せずに破棄した通知数
+}
+
+// circuitBreaker はバックエンドの長時間の障害中に、通知ごとのリトライで待ち行列が詰まるのを防ぐ
+// Threshold 回連続で失敗すると開き、Cooldown の間は送信せずに失敗させる。
+// Cooldown 後は1件だけ試行し（half-open）、成功すれば閉じ、失敗すればもう一度開く
+type circuitBreaker struct {
+	config BreakerConfig
+
+	mu       sync.Mutex
+	state    string
+	failures int
+	openedAt time.Time
+	probing  bool
+	rejected uint64
+}
+
+func newCircuitBreaker(config BreakerConfig) *circuitBreaker {
+	if config.Threshold <= 0 {
+		return nil
+	}
+	if config.Cooldown <= 0 {
+		config.Cooldown = DefaultBreakerCooldown
+	}
+	return &circuitBreaker{config: config, state: BreakerClosed}
+}
+
+// allow は送信してよいか判定する（開いている間は ErrCircuitOpen）
+func (b *circuitBreaker) allow() error {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if b.state == BreakerOpen && time.Since(b.openedAt) >= b.config.Cooldown {
+		b.state = BreakerHalfOpen
+		b.probing = false
+	}
+
+	switch b.state {
+	case BreakerOpen:
+		b.rejected++
+		return ErrCircuitOpen
+	case BreakerHalfOpen:
+		// 復旧確認の送信中は他の通知を通さない
+		if b.probing {
+			b.rejected++
+			return ErrCircuitOpen
+		}
+		b.probing = true
+	}
+	return nil
+}
+
+// record は送信結果を記録する
+func (b *circuitBreaker) record(err error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if !countsAsFailure(err) {
+		if b.state != BreakerClosed {
+			log.Printf("Backend notifier circuit closed (backend recovered)")
+		}
+		b.state = BreakerClosed
+		b.failures = 0
+		b.probing = false
+		return
+	}
+
+	b.failures++
+	if b.state == BreakerHalfOpen || b.failures >= b.config.Threshold {
+		if b.state != BreakerOpen {
+			log.Printf("WARNING: Backend notifier circuit opened after %d consecutive failures, pausing notifications for %v: %v", b.failures, b.config.Cooldown, err)
+		}
+		b.state = BreakerOpen
+		b.openedAt = time.Now()
+		b.probing = false
+	}
+}
+
+func (b *circuitBreaker) stats() BreakerStats {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	state := b.state
+	if state == BreakerOpen && time.Since(b.openedAt) >= b.config.Cooldown {
+		state = BreakerHalfOpen
+	}
+	return BreakerStats{
+		State:               state,
+		ConsecutiveFailures: b.failures,
+		OpenedAt:            b.openedAt,
+		Rejected:            b.rejected,
+	}
+}
+
+// countsAsFailure はバックエンドの障害とみなす失敗かどうかを返す
+// 4xx（429を除く）はバックエンド自体は応答しており、停止時の ctx のキャンセルも障害ではないため数えない
+func countsAsFailure(err error) bool {
+	if err == nil || errors.Is(err, context.Canceled) {
+		return false
+	}
+	var statusErr *StatusError
+	if errors.As(err, &statusErr) {
+		code := statusErr.StatusCode
+		return code >= 500 || code == http.StatusTooManyRequests
+	}
+	return true
+}
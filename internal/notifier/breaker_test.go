@@ -0,0 +1,92 @@
+package notifier
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestBreakerOpensAfterConsecutiveFailures(t *testing.T) {
+	srv, calls := statusSequence(t, http.StatusInternalServerError)
+	retry := RetryConfig{MaxAttempts: 1}
+	n := NewBackendNotifier(srv.URL, time.Second, retry, "", nil, BreakerConfig{Threshold: 2, Cooldown: time.Hour})
+
+	for i := 0; i < 2; i++ {
+		if err := n.Post(context.Background(), "/hook", map[string]string{}); err == nil || errors.Is(err, ErrCircuitOpen) {
+			t.Fatalf("attempt %d: err = %v, want backend error", i+1, err)
+		}
+	}
+	if err := n.Post(context.Background(), "/hook", map[string]string{}); !errors.Is(err, ErrCircuitOpen) {
+		t.Fatalf("err = %v, want ErrCircuitOpen", err)
+	}
+	if got := atomic.LoadInt32(calls); got != 2 {
+		t.Errorf("calls = %d, want 2 (no request while open)", got)
+	}
+
+	stats := n.BreakerStats()
+	if stats.State != BreakerOpen || stats.Rejected != 1 {
+		t.Errorf("stats = %+v, want open with 1 rejected", stats)
+	}
+}
+
+func TestBreakerIgnoresClientErrors(t *testing.T) {
+	srv, _ := statusSequence(t, http.StatusBadRequest)
+	n := NewBackendNotifier(srv.URL, time.Second, RetryConfig{MaxAttempts: 1}, "", nil, BreakerConfig{Threshold: 1, Cooldown: time.Hour})
+
+	for i := 0; i < 3; i++ {
+		if err := n.Post(context.Background(), "/hook", map[string]string{}); errors.Is(err, ErrCircuitOpen) {
+			t.Fatalf("attempt %d: circuit opened on 4xx", i+1)
+		}
+	}
+	if state := n.BreakerStats().State; state != BreakerClosed {
+		t.Errorf("state = %s, want closed", state)
+	}
+}
+
+func TestBreakerHalfOpenProbe(t *testing.T) {
+	var healthy atomic.Bool
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if !healthy.Load() {
+			w.WriteHeader(http.StatusInternalServerError)
+		}
+	}))
+	defer srv.Close()
+
+	cooldown := 20 * time.Millisecond
+	n := NewBackendNotifier(srv.URL, time.Second, RetryConfig{MaxAttempts: 1}, "", nil, BreakerConfig{Threshold: 1, Cooldown: cooldown})
+
+	n.Post(context.Background(), "/hook", map[string]string{})
+	if state := n.BreakerStats().State; state != BreakerOpen {
+		t.Fatalf("state = %s, want open", state)
+	}
+
+	// クールダウン後の試行が失敗すると、もう一度開く
+	time.Sleep(cooldown + 10*time.Millisecond)
+	if err := n.Post(context.Background(), "/hook", map[string]string{}); err == nil || errors.Is(err, ErrCircuitOpen) {
+		t.Fatalf("probe err = %v, want backend error", err)
+	}
+	if state := n.BreakerStats().State; state != BreakerOpen {
+		t.Fatalf("state after failed probe = %s, want open", state)
+	}
+
+	// 復旧後の試行が成功すると閉じる
+	healthy.Store(true)
+	time.Sleep(cooldown + 10*time.Millisecond)
+	if err := n.Post(context.Background(), "/hook", map[string]string{}); err != nil {
+		t.Fatalf("probe err = %v, want nil", err)
+	}
+	if state := n.BreakerStats().State; state != BreakerClosed {
+		t.Errorf("state after successful probe = %s, want closed", state)
+	}
+}
+
+func TestBreakerDisabled(t *testing.T) {
+	n := NewBackendNotifier("http://127.0.0.1", time.Second, RetryConfig{}, "", nil, BreakerConfig{})
+	if state := n.BreakerStats().State; state != BreakerDisabled {
+		t.Errorf("state = %s, want disabled", state)
+	}
+}
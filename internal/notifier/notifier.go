@@ -0,0 +1,167 @@
+package notifier
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"math/rand/v2"
+	"net/http"
+	"strconv"
+	"time"
+)
+
+const (
+	// DefaultMaxAttempts はバックエンド通知の最大試行回数のデフォルト
+	DefaultMaxAttempts = 3
+	// DefaultBaseDelay はリトライ間隔の基準値のデフォルト
+	DefaultBaseDelay = 1 * time.Second
+	// DefaultMaxDelay はリトライ間隔（Retry-After を含む）の上限
+	DefaultMaxDelay = 30 * time.Second
+)
+
+// StatusError はバックエンドが2xx以外のステータスを返した
+type StatusError struct {
+	StatusCode int
+	Body       string
+}
+
+func (e *StatusError) Error() string {
+	return fmt.Sprintf("backend returned status %d: %s", e.StatusCode, e.Body)
+}
+
+// RetryConfig はバックエンド通知のリトライ設定
+type RetryConfig struct {
+	MaxAttempts int
+	BaseDelay   time.Duration
+	MaxDelay    time.Duration
+}
+
+// DefaultRetryConfig はデフォルトのリトライ設定を返す
+func DefaultRetryConfig() RetryConfig {
+	return RetryConfig{
+		MaxAttempts: DefaultMaxAttempts,
+		BaseDelay:   DefaultBaseDelay,
+		MaxDelay:    DefaultMaxDelay,
+	}
+}
+
+// PostJSON は payload をJSONでPOSTし、失敗時は full jitter 付きの指数バックオフでリトライする
+// （多数の通知が同時に再送されないように）。5xx・通信エラー・429 はリトライし、それ以外の4xxはリトライしない。
+// prepare は送信前にヘッダー（署名・トレースIDなど）を設定するために毎回呼ばれる（nil 可）。
+// ctx はリトライ間の待機にのみ使い、送信中のリクエストは中断しない
+func PostJSON(ctx context.Context, client *http.Client, url string, payload interface{}, retry RetryConfig, prepare func(req *http.Request, body []byte)) error {
+	jsonData, err := json.Marshal(payload)
+	if err != nil {
+		return fmt.Errorf("failed to marshal payload: %w", err)
+	}
+
+	var lastErr error
+	var retryAfter time.Duration
+
+	for i := 0; i < retry.MaxAttempts; i++ {
+		if i > 0 {
+			delay := retryAfter
+			if delay <= 0 {
+				delay = backoffDelay(retry, i)
+			}
+			select {
+			case <-ctx.Done():
+				return fmt.Errorf("retry aborted after %d attempts: %w (last error: %v)", i, ctx.Err(), lastErr)
+			case <-time.After(delay):
+			}
+		}
+		retryAfter = 0
+
+		req, err := http.NewRequest(http.MethodPost, url, bytes.NewBuffer(jsonData))
+		if err != nil {
+			return fmt.Errorf("failed to create request: %w", err)
+		}
+		req.Header.Set("Content-Type", "application/json")
+		if prepare != nil {
+			prepare(req, jsonData)
+		}
+
+		resp, err := client.Do(req)
+		if err != nil {
+			lastErr = fmt.Errorf("failed to send request: %w", err)
+			continue
+		}
+
+		// エラー表示用に先頭だけ読み、残りは読み捨ててから閉じる（keep-alive で接続を再利用するため）
+		bodyBytes, _ := io.ReadAll(io.LimitReader(resp.Body, 1024))
+		io.Copy(io.Discard, resp.Body)
+		resp.Body.Close()
+		bodyStr := string(bodyBytes)
+
+		if resp.StatusCode >= 200 && resp.StatusCode < 300 {
+			return nil
+		}
+
+		lastErr = &StatusError{StatusCode: resp.StatusCode, Body: bodyStr}
+		// 429 はバックエンドの指定（Retry-After）があればそれに従ってリトライする
+		if resp.StatusCode == http.StatusTooManyRequests {
+			retryAfter = parseRetryAfter(resp.Header.Get("Retry-After"), retry.MaxDelay)
+			continue
+		}
+		// それ以外の4xxエラーはリトライしない
+		if resp.StatusCode >= 400 && resp.StatusCode < 500 {
+			return lastErr
+		}
+	}
+
+	return fmt.Errorf("failed after %d retries: %w", retry.MaxAttempts, lastErr)
+}
+
+// SignRequest はリクエストボディにHMAC-SHA256署名を付与する（secret が空なら何もしない）
+// 署名対象は "<timestamp>.<body>" で、バックエンド側はタイムスタンプの鮮度も検証することでリプレイを防ぐ
+func SignRequest(req *http.Request, body []byte, secret []byte) {
+	if len(secret) == 0 {
+		return
+	}
+
+	timestamp := strconv.FormatInt(time.Now().Unix(), 10)
+	mac := hmac.New(sha256.New, secret)
+	mac.Write([]byte(timestamp))
+	mac.Write([]byte("."))
+	mac.Write(body)
+
+	req.Header.Set("X-Timestamp", timestamp)
+	req.Header.Set("X-Signature", hex.EncodeToString(mac.Sum(nil)))
+}
+
+// backoffDelay は attempt 回目のリトライ前の待機時間を返す
+// base * 2^(attempt-1) を上限 MaxDelay で抑え、0からその値までの一様乱数にする（full jitter）
+func backoffDelay(retry RetryConfig, attempt int) time.Duration {
+	ceiling := retry.MaxDelay
+	if shift := attempt - 1; shift < 32 {
+		if d := retry.BaseDelay << shift; d > 0 && d < ceiling {
+			ceiling = d
+		}
+	}
+	return time.Duration(rand.Int64N(int64(ceiling) + 1))
+}
+
+// parseRetryAfter は Retry-After ヘッダー（秒数またはHTTP日付）を待機時間に変換する
+// 未指定・不正値の場合は0（通常のバックオフを使う）、maxDelay を超える値は maxDelay に丸める
+func parseRetryAfter(value string, maxDelay time.Duration) time.Duration {
+	if value == "" {
+		return 0
+	}
+
+	var delay time.Duration
+	if seconds, err := strconv.Atoi(value); err == nil {
+		delay = time.Duration(seconds) * time.Second
+	} else if t, err := http.ParseTime(value); err == nil {
+		delay = time.Until(t)
+	}
+
+	if delay <= 0 {
+		return 0
+	}
+	return min(delay, maxDelay)
+}
@@ -0,0 +1,168 @@
+package notifier
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+// fastRetry はテスト用にリトライ間隔を短くした設定
+var fastRetry = RetryConfig{MaxAttempts: 3, BaseDelay: time.Millisecond, MaxDelay: 5 * time.Millisecond}
+
+// statusSequence は呼び出しごとに statuses の順にステータスを返すサーバーを起動する（使い切った後は最後の値）
+func statusSequence(t *testing.T, statuses ...int) (*httptest.Server, *int32) {
+	t.Helper()
+	var calls int32
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		i := int(atomic.AddInt32(&calls, 1)) - 1
+		if i >= len(statuses) {
+			i = len(statuses) - 1
+		}
+		w.WriteHeader(statuses[i])
+		io.WriteString(w, `{"message":"response body"}`)
+	}))
+	t.Cleanup(srv.Close)
+	return srv, &calls
+}
+
+// trackingTransport はレスポンスボディが閉じられたかを記録する
+type trackingTransport struct {
+	base http.RoundTripper
+
+	mu     sync.Mutex
+	bodies []*trackedBody
+}
+
+type trackedBody struct {
+	io.ReadCloser
+	closed atomic.Bool
+}
+
+func (b *trackedBody) Close() error {
+	b.closed.Store(true)
+	return b.ReadCloser.Close()
+}
+
+func (t *trackingTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	resp, err := t.base.RoundTrip(req)
+	if err != nil {
+		return nil, err
+	}
+	body := &trackedBody{ReadCloser: resp.Body}
+	resp.Body = body
+	t.mu.Lock()
+	t.bodies = append(t.bodies, body)
+	t.mu.Unlock()
+	return resp, nil
+}
+
+func TestPostJSONRetriesOn5xx(t *testing.T) {
+	srv, calls := statusSequence(t, http.StatusInternalServerError, http.StatusBadGateway, http.StatusOK)
+
+	err := PostJSON(context.Background(), srv.Client(), srv.URL, map[string]string{"k": "v"}, fastRetry, nil)
+	if err != nil {
+		t.Fatalf("PostJSON: %v", err)
+	}
+	if got := atomic.LoadInt32(calls); got != 3 {
+		t.Errorf("calls = %d, want 3", got)
+	}
+}
+
+func TestPostJSONGivesUpAfterMaxAttempts(t *testing.T) {
+	srv, calls := statusSequence(t, http.StatusServiceUnavailable)
+
+	err := PostJSON(context.Background(), srv.Client(), srv.URL, map[string]string{}, fastRetry, nil)
+	var statusErr *StatusError
+	if !errors.As(err, &statusErr) || statusErr.StatusCode != http.StatusServiceUnavailable {
+		t.Fatalf("err = %v, want StatusError 503", err)
+	}
+	if got := atomic.LoadInt32(calls); got != int32(fastRetry.MaxAttempts) {
+		t.Errorf("calls = %d, want %d", got, fastRetry.MaxAttempts)
+	}
+}
+
+func TestPostJSONDoesNotRetryOn4xx(t *testing.T) {
+	srv, calls := statusSequence(t, http.StatusBadRequest, http.StatusOK)
+
+	err := PostJSON(context.Background(), srv.Client(), srv.URL, map[string]string{}, fastRetry, nil)
+	var statusErr *StatusError
+	if !errors.As(err, &statusErr) || statusErr.StatusCode != http.StatusBadRequest {
+		t.Fatalf("err = %v, want StatusError 400", err)
+	}
+	if got := atomic.LoadInt32(calls); got != 1 {
+		t.Errorf("calls = %d, want 1", got)
+	}
+}
+
+func TestPostJSONRetriesOn429(t *testing.T) {
+	srv, calls := statusSequence(t, http.StatusTooManyRequests, http.StatusOK)
+
+	if err := PostJSON(context.Background(), srv.Client(), srv.URL, map[string]string{}, fastRetry, nil); err != nil {
+		t.Fatalf("PostJSON: %v", err)
+	}
+	if got := atomic.LoadInt32(calls); got != 2 {
+		t.Errorf("calls = %d, want 2", got)
+	}
+}
+
+func TestPostJSONClosesBodyOnEveryAttempt(t *testing.T) {
+	srv, _ := statusSequence(t, http.StatusInternalServerError, http.StatusInternalServerError, http.StatusOK)
+	transport := &trackingTransport{base: srv.Client().Transport}
+	client := &http.Client{Transport: transport}
+
+	if err := PostJSON(context.Background(), client, srv.URL, map[string]string{}, fastRetry, nil); err != nil {
+		t.Fatalf("PostJSON: %v", err)
+	}
+
+	transport.mu.Lock()
+	defer transport.mu.Unlock()
+	if len(transport.bodies) != 3 {
+		t.Fatalf("responses = %d, want 3", len(transport.bodies))
+	}
+	for i, body := range transport.bodies {
+		if !body.closed.Load() {
+			t.Errorf("response body of attempt %d was not closed", i+1)
+		}
+	}
+}
+
+func TestPostJSONAbortsRetryOnContextCancel(t *testing.T) {
+	srv, calls := statusSequence(t, http.StatusInternalServerError)
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	slowRetry := RetryConfig{MaxAttempts: 3, BaseDelay: time.Hour, MaxDelay: time.Hour}
+	err := PostJSON(ctx, srv.Client(), srv.URL, map[string]string{}, slowRetry, nil)
+	if !errors.Is(err, context.Canceled) {
+		t.Fatalf("err = %v, want context.Canceled", err)
+	}
+	if got := atomic.LoadInt32(calls); got != 1 {
+		t.Errorf("calls = %d, want 1", got)
+	}
+}
+
+func TestBackendNotifierSignsAndTracesRequests(t *testing.T) {
+	var signature, traceID string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		signature = r.Header.Get("X-Signature")
+		traceID = r.Header.Get("X-Trace-Id")
+	}))
+	defer srv.Close()
+
+	n := NewBackendNotifier(srv.URL, time.Second, fastRetry, "secret", nil, BreakerConfig{})
+	if err := n.Post(WithTraceID(context.Background(), "trace-1"), "/hook", map[string]string{}); err != nil {
+		t.Fatalf("Post: %v", err)
+	}
+	if signature == "" {
+		t.Error("X-Signature was not set")
+	}
+	if traceID != "trace-1" {
+		t.Errorf("X-Trace-Id = %q, want trace-1", traceID)
+	}
+}
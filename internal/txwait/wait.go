@@ -0,0 +1,105 @@
+package txwait
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+)
+
+// 確認間隔（テストで短くできるよう変数にしている）
+var (
+	// initialPollDelay はレシートを確認する間隔の初期値（確認ごとに2倍）
+	initialPollDelay = 1 * time.Second
+	// maxPollDelay は確認間隔の上限（Sepolia のブロック間隔）
+	maxPollDelay = 12 * time.Second
+)
+
+const (
+	// droppedAfterMisses は一度見えたトランザクションが、レシートの無いままノードから消えたと判定するまでの連続確認回数
+	// Infura はリクエストごとに別のノードに振り分けられることがあるため、1回の未検出では判定しない
+	droppedAfterMisses = 3
+)
+
+// ErrTxDropped はトランザクションがマイニングされないままメモリプールから消えた（破棄・置き換え）
+var ErrTxDropped = errors.New("transaction was dropped or replaced before being mined")
+
+// Client は WaitForReceipt が使うノードの操作（*ethclient.Client が満たす）
+type Client interface {
+	TransactionReceipt(ctx context.Context, txHash common.Hash) (*types.Receipt, error)
+	TransactionByHash(ctx context.Context, hash common.Hash) (*types.Transaction, bool, error)
+	BlockNumber(ctx context.Context) (uint64, error)
+}
+
+// WaitForReceipt はトランザクションのレシートが confirmations 件の確認を得るまで間隔を広げながらポーリングし、レシートを返す
+// confirmations が 0 の場合は 1（マイニング済み）として扱う。ctx が終了した場合は ctx.Err() を返す。
+// 一度ノードで見えたトランザクションがレシートの無いまま見えなくなった場合は ErrTxDropped を返す
+// （ノードにまだ伝播していないトランザクションは、見えるようになるまで待ち続ける）
+func WaitForReceipt(ctx context.Context, client Client, txHash common.Hash, confirmations uint64) (*types.Receipt, error) {
+	if confirmations == 0 {
+		confirmations = 1
+	}
+
+	seen := false
+	misses := 0
+	delay := initialPollDelay
+	for {
+		receipt, err := client.TransactionReceipt(ctx, txHash)
+		switch {
+		case err == nil:
+			seen, misses = true, 0
+			if confirmations == 1 {
+				return receipt, nil
+			}
+			latest, err := client.BlockNumber(ctx)
+			if err != nil {
+				return nil, wrapErr(ctx, "failed to get latest block", err)
+			}
+			if mined := receipt.BlockNumber.Uint64(); latest >= mined && latest-mined+1 >= confirmations {
+				return receipt, nil
+			}
+
+		case errors.Is(err, ethereum.NotFound):
+			// 未マイニング。メモリプールにまだあるかを確認する（リオルグで取り消された場合もここに来る）
+			_, _, txErr := client.TransactionByHash(ctx, txHash)
+			switch {
+			case txErr == nil:
+				seen, misses = true, 0
+			case errors.Is(txErr, ethereum.NotFound):
+				if seen {
+					misses++
+					if misses >= droppedAfterMisses {
+						return nil, ErrTxDropped
+					}
+				}
+			default:
+				return nil, wrapErr(ctx, "failed to get transaction", txErr)
+			}
+
+		default:
+			return nil, wrapErr(ctx, "failed to get transaction receipt", err)
+		}
+
+		select {
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		case <-time.After(delay):
+		}
+		delay *= 2
+		if delay > maxPollDelay {
+			delay = maxPollDelay
+		}
+	}
+}
+
+// wrapErr は ctx の終了による失敗であれば ctx.Err() を、それ以外はノードのエラーを返す
+func wrapErr(ctx context.Context, msg string, err error) error {
+	if ctx.Err() != nil {
+		return ctx.Err()
+	}
+	return fmt.Errorf("%s: %w", msg, err)
+}
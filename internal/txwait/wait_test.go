@@ -0,0 +1,162 @@
+package txwait
+
+import (
+	"context"
+	"errors"
+	"math/big"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+)
+
+var testHash = common.HexToHash("0x5c504ed432cb51138bcf09aa5e8a410dd4a1e204ef84bfed1be16dfba1b22060")
+
+func TestMain(m *testing.M) {
+	initialPollDelay = time.Millisecond
+	maxPollDelay = 2 * time.Millisecond
+	m.Run()
+}
+
+// pollResult はモックが1回の確認で返す結果
+type pollResult struct {
+	receiptBlock uint64 // 0 の場合はレシート無し（NotFound）
+	txVisible    bool   // レシートが無い場合に TransactionByHash で見えるか
+	latest       uint64 // BlockNumber が返す最新ブロック
+}
+
+// mockClient は確認のたびに results を順に返す（使い切った後は最後の値を返し続ける）
+type mockClient struct {
+	mu      sync.Mutex
+	results []pollResult
+	polls   int
+	err     error
+}
+
+func (c *mockClient) current() pollResult {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.results[min(c.polls, len(c.results)-1)]
+}
+
+func (c *mockClient) TransactionReceipt(ctx context.Context, txHash common.Hash) (*types.Receipt, error) {
+	if c.err != nil {
+		return nil, c.err
+	}
+	r := c.current()
+	if r.receiptBlock == 0 {
+		return nil, ethereum.NotFound
+	}
+	if r.latest == 0 {
+		// 確認数を問わない場合は BlockNumber が呼ばれないため、ここで次の確認に進める
+		c.advance()
+	}
+	return &types.Receipt{TxHash: txHash, BlockNumber: new(big.Int).SetUint64(r.receiptBlock)}, nil
+}
+
+func (c *mockClient) TransactionByHash(ctx context.Context, hash common.Hash) (*types.Transaction, bool, error) {
+	r := c.current()
+	c.advance()
+	if !r.txVisible {
+		return nil, false, ethereum.NotFound
+	}
+	return types.NewTx(&types.LegacyTx{}), true, nil
+}
+
+func (c *mockClient) BlockNumber(ctx context.Context) (uint64, error) {
+	r := c.current()
+	c.advance()
+	return r.latest, nil
+}
+
+func (c *mockClient) advance() {
+	c.mu.Lock()
+	c.polls++
+	c.mu.Unlock()
+}
+
+func TestWaitForReceiptReturnsMinedReceipt(t *testing.T) {
+	client := &mockClient{results: []pollResult{
+		{txVisible: true},
+		{txVisible: true},
+		{receiptBlock: 10},
+	}}
+
+	receipt, err := WaitForReceipt(context.Background(), client, testHash, 0)
+	if err != nil {
+		t.Fatalf("WaitForReceipt: %v", err)
+	}
+	if receipt.BlockNumber.Uint64() != 10 {
+		t.Errorf("receipt block = %d, want 10", receipt.BlockNumber)
+	}
+}
+
+func TestWaitForReceiptWaitsForConfirmations(t *testing.T) {
+	client := &mockClient{results: []pollResult{
+		{receiptBlock: 10, latest: 10},
+		{receiptBlock: 10, latest: 11},
+		{receiptBlock: 10, latest: 12},
+	}}
+
+	if _, err := WaitForReceipt(context.Background(), client, testHash, 3); err != nil {
+		t.Fatalf("WaitForReceipt: %v", err)
+	}
+	if client.polls != 3 {
+		t.Errorf("polls = %d, want 3 (block 12 gives 3 confirmations)", client.polls)
+	}
+}
+
+func TestWaitForReceiptDetectsDroppedTransaction(t *testing.T) {
+	client := &mockClient{results: []pollResult{
+		{txVisible: true},
+		{txVisible: false},
+	}}
+
+	_, err := WaitForReceipt(context.Background(), client, testHash, 1)
+	if !errors.Is(err, ErrTxDropped) {
+		t.Fatalf("err = %v, want ErrTxDropped", err)
+	}
+	if client.polls != 1+droppedAfterMisses {
+		t.Errorf("polls = %d, want %d", client.polls, 1+droppedAfterMisses)
+	}
+}
+
+func TestWaitForReceiptToleratesSingleMiss(t *testing.T) {
+	// 別のノードに振り分けられて1回見えなくなっても、破棄とは判定しない
+	client := &mockClient{results: []pollResult{
+		{txVisible: true},
+		{txVisible: false},
+		{txVisible: true},
+		{txVisible: false},
+		{receiptBlock: 5},
+	}}
+
+	if _, err := WaitForReceipt(context.Background(), client, testHash, 1); err != nil {
+		t.Fatalf("WaitForReceipt: %v", err)
+	}
+}
+
+func TestWaitForReceiptKeepsWaitingForUnpropagatedTransaction(t *testing.T) {
+	// 一度も見えていないトランザクションは破棄と判定せず、ctx の終了まで待つ
+	client := &mockClient{results: []pollResult{{txVisible: false}}}
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+
+	_, err := WaitForReceipt(ctx, client, testHash, 1)
+	if !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("err = %v, want context.DeadlineExceeded", err)
+	}
+}
+
+func TestWaitForReceiptReturnsNodeError(t *testing.T) {
+	nodeErr := errors.New("connection refused")
+	client := &mockClient{results: []pollResult{{}}, err: nodeErr}
+
+	_, err := WaitForReceipt(context.Background(), client, testHash, 1)
+	if !errors.Is(err, nodeErr) {
+		t.Fatalf("err = %v, want node error", err)
+	}
+}
@@ -0,0 +1,51 @@
+package logger
+
+import (
+	"bytes"
+	"log"
+	"log/slog"
+	"sync"
+)
+
+// CaptureBuffer はキャプチャしたログを保持するスレッドセーフなバッファ
+type CaptureBuffer struct {
+	mu  sync.Mutex
+	buf bytes.Buffer
+}
+
+func (b *CaptureBuffer) Write(p []byte) (int, error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.buf.Write(p)
+}
+
+// String はこれまでにキャプチャしたログ（JSON Lines）を返す
+func (b *CaptureBuffer) String() string {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.buf.String()
+}
+
+// Capture はデフォルトロガーの出力をバッファに切り替える（テストでのログ検証用）
+// 返り値の restore を呼ぶと元の出力先に戻る
+//
+//	buf, restore := logger.Capture()
+//	defer restore()
+//	... // テスト対象を実行
+//	strings.Contains(buf.String(), "uid not found")
+func Capture() (*CaptureBuffer, func()) {
+	prevLogger := slog.Default()
+	prevWriter := log.Writer()
+	prevFlags := log.Flags()
+
+	buf := &CaptureBuffer{}
+	Init(buf)
+
+	restore := func() {
+		slog.SetDefault(prevLogger)
+		// slog.SetDefault は元のデフォルトハンドラーに戻しても log パッケージの出力先を戻さないため明示的に戻す
+		log.SetOutput(prevWriter)
+		log.SetFlags(prevFlags)
+	}
+	return buf, restore
+}
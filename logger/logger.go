@@ -0,0 +1,51 @@
+package logger
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"io"
+	"log/slog"
+
+	"uttc-hack-back-onchain/model"
+)
+
+// Init はJSON形式のロガーを作成し、デフォルトロガーとして設定する
+// slog.SetDefault により既存の log.Printf の出力も同じJSON形式になる
+// Cloud Logging が解釈できるよう level/msg をそれぞれ severity/message として出力する
+func Init(w io.Writer) *slog.Logger {
+	handler := slog.NewJSONHandler(w, &slog.HandlerOptions{
+		ReplaceAttr: func(groups []string, a slog.Attr) slog.Attr {
+			if len(groups) > 0 {
+				return a
+			}
+			switch a.Key {
+			case slog.LevelKey:
+				a.Key = "severity"
+			case slog.MessageKey:
+				a.Key = "message"
+			}
+			return a
+		},
+	})
+
+	l := slog.New(handler)
+	slog.SetDefault(l)
+	return l
+}
+
+// NewTraceID はイベント1件の処理を追跡するためのIDを生成する
+func NewTraceID() string {
+	b := make([]byte, 8)
+	rand.Read(b)
+	return hex.EncodeToString(b)
+}
+
+// ForEvent はイベントの識別情報（種類・商品ID・Tx・トレースID）を付与したロガーを返す
+func ForEvent(event *model.ContractEvent) *slog.Logger {
+	return slog.With(
+		"event_type", event.Type,
+		"item_id", event.ItemId,
+		"tx_hash", event.TxHash,
+		"trace_id", event.TraceID,
+	)
+}
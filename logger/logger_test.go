@@ -0,0 +1,119 @@
+package logger
+
+import (
+	"encoding/json"
+	"log"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"uttc-hack-back-onchain/model"
+)
+
+// lines はキャプチャした JSON Lines を1行ずつデコードする
+func lines(t *testing.T, out string) []map[string]interface{} {
+	t.Helper()
+	var records []map[string]interface{}
+	for _, line := range strings.Split(strings.TrimSpace(out), "\n") {
+		if line == "" {
+			continue
+		}
+		var record map[string]interface{}
+		if err := json.Unmarshal([]byte(line), &record); err != nil {
+			t.Fatalf("log line is not JSON: %q", line)
+		}
+		records = append(records, record)
+	}
+	return records
+}
+
+func TestCaptureCollectsLogAndSlogOutput(t *testing.T) {
+	buf, restore := Capture()
+	defer restore()
+
+	log.Printf("WARNING: uid not found in ItemListed event")
+	slog.Error("Failed to notify", "status", 500)
+
+	records := lines(t, buf.String())
+	if len(records) != 2 {
+		t.Fatalf("captured %d records, want 2: %s", len(records), buf.String())
+	}
+	if records[0]["message"] != "WARNING: uid not found in ItemListed event" || records[0]["severity"] != "INFO" {
+		t.Errorf("log.Printf record = %v", records[0])
+	}
+	if records[1]["message"] != "Failed to notify" || records[1]["severity"] != "ERROR" || records[1]["status"] != float64(500) {
+		t.Errorf("slog record = %v", records[1])
+	}
+}
+
+func TestCaptureRestoresPreviousOutput(t *testing.T) {
+	// テスト終了後にデフォルトロガーを戻すため、外側でもキャプチャしておく
+	outer, restoreOuter := Capture()
+	defer restoreOuter()
+
+	buf, restore := Capture()
+	log.Printf("inside capture")
+	restore()
+	log.Printf("after restore")
+
+	if strings.Contains(buf.String(), "after restore") {
+		t.Error("log written after restore went to the capture buffer")
+	}
+	if !strings.Contains(outer.String(), "after restore") || strings.Contains(outer.String(), "inside capture") {
+		t.Errorf("previous output = %q", outer.String())
+	}
+}
+
+func TestForEventAddsEventAttributes(t *testing.T) {
+	buf, restore := Capture()
+	defer restore()
+
+	event := &model.ContractEvent{Type: model.EventItemPurchased, ItemId: 7, TxHash: "0xabc", TraceID: "trace-1"}
+	ForEvent(event).Warn("Processing event")
+
+	records := lines(t, buf.String())
+	if len(records) != 1 {
+		t.Fatalf("captured %d records, want 1", len(records))
+	}
+	r := records[0]
+	if r["event_type"] != "ItemPurchased" || r["item_id"] != float64(7) || r["tx_hash"] != "0xabc" || r["trace_id"] != "trace-1" || r["severity"] != "WARN" {
+		t.Errorf("record = %v", r)
+	}
+}
+
+func TestOpenOutput(t *testing.T) {
+	for dest, want := range map[string]*os.File{"": os.Stdout, "stdout": os.Stdout, "stderr": os.Stderr} {
+		w, closeFn, err := OpenOutput(dest)
+		if err != nil || w != want {
+			t.Errorf("OpenOutput(%q) = %v, %v", dest, w, err)
+			continue
+		}
+		closeFn()
+	}
+
+	_, restore := Capture()
+	defer restore()
+
+	path := filepath.Join(t.TempDir(), "app.log")
+	w, closeFn, err := OpenOutput(path)
+	if err != nil {
+		t.Fatalf("OpenOutput(file): %v", err)
+	}
+	Init(w).Info("written to file")
+	if err := closeFn(); err != nil {
+		t.Fatalf("close: %v", err)
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil || !strings.Contains(string(data), "written to file") {
+		t.Errorf("file contents = %q, %v", data, err)
+	}
+}
+
+func TestOpenOutputFailsForMissingDirectory(t *testing.T) {
+	if _, _, err := OpenOutput(filepath.Join(t.TempDir(), "missing", "app.log")); err == nil {
+		t.Fatal("OpenOutput succeeded for a path in a missing directory")
+	}
+}
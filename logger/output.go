@@ -0,0 +1,27 @@
+package logger
+
+import (
+	"fmt"
+	"io"
+	"os"
+)
+
+// OpenOutput はログの出力先を開く
+// dest には "stdout"（デフォルト）、"stderr"、またはファイルパスを指定する
+// 返り値の close はファイルを開いた場合のみ実際にクローズする
+func OpenOutput(dest string) (io.Writer, func() error, error) {
+	noop := func() error { return nil }
+
+	switch dest {
+	case "", "stdout":
+		return os.Stdout, noop, nil
+	case "stderr":
+		return os.Stderr, noop, nil
+	}
+
+	f, err := os.OpenFile(dest, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0o644)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to open log output %s: %w", dest, err)
+	}
+	return f, f.Close, nil
+}
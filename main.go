@@ -2,32 +2,88 @@ package main
 
 import (
 	"context"
+	"errors"
+	"expvar"
 	"fmt"
 	"log"
 	"net/http"
+	"net/url"
 	"os"
+	"os/signal"
+	"strconv"
 	"strings"
+	"syscall"
 	"time"
 
+	chainGateway "uttc-hack-back-onchain/gateway/chain"
 	contractGateway "uttc-hack-back-onchain/gateway/contract"
 	paymentGateway "uttc-hack-back-onchain/gateway/payment"
+	signerGateway "uttc-hack-back-onchain/gateway/signer"
+	chainHandler "uttc-hack-back-onchain/handler/chain"
 	contractHandler "uttc-hack-back-onchain/handler/contract"
+	healthHandler "uttc-hack-back-onchain/handler/health"
 	paymentHandler "uttc-hack-back-onchain/handler/payment"
+	"uttc-hack-back-onchain/internal/httpclient"
+	"uttc-hack-back-onchain/internal/notifier"
+	"uttc-hack-back-onchain/logger"
+	"uttc-hack-back-onchain/middleware"
+	"uttc-hack-back-onchain/model"
+	chainUsecase "uttc-hack-back-onchain/usecase/chain"
 	contractUsecase "uttc-hack-back-onchain/usecase/contract"
 	paymentUsecase "uttc-hack-back-onchain/usecase/payment"
 
 	"github.com/ethereum/go-ethereum/ethclient"
+	"github.com/ethereum/go-ethereum/rpc"
 	"github.com/gorilla/mux"
 	"github.com/rs/cors"
 )
 
+// isLongRunningRequest は意図的に長く待つリクエスト（SSE・再通知・トランザクション送信・確定待ち）か判定する
+// 対象はハンドラーが WriteTimeout を解除し、ユースケース側で個別の上限（SSE はクライアントの切断）を設けているものに限る。
+// ルートを追加する場合も、両方を満たさない限りリクエストタイムアウトの対象のままにする
+func isLongRunningRequest(r *http.Request) bool {
+	path := r.URL.Path
+	switch {
+	case path == "/api/v1/contract/events/stream",
+		path == "/api/v1/contract/replay",
+		path == "/api/v1/payment/self-test",
+		path == "/api/v1/admin/relay/list-item",
+		strings.HasPrefix(path, "/api/v1/admin/resync-item-events/"):
+		return true
+	case path == "/api/v1/payment/confirm":
+		return r.URL.Query().Get("wait") == "true"
+	case path == "/api/v1/contract/verify-tx":
+		wait := r.URL.Query().Get("wait_seconds")
+		return wait != "" && wait != "0"
+	}
+	return false
+}
+
+// logRoutes は登録済みのエンドポイントを起動ログに出力する
+// 手書きの一覧は追加したルートと食い違うため、ルーターから直接列挙する（認証の有無はルートの登録箇所を参照）
+func logRoutes(router *mux.Router) {
+	log.Println("Available endpoints:")
+	router.Walk(func(route *mux.Route, _ *mux.Router, _ []*mux.Route) error {
+		path, err := route.GetPathTemplate()
+		if err != nil {
+			return nil
+		}
+		methods, err := route.GetMethods()
+		if err != nil {
+			methods = []string{"ANY"}
+		}
+		log.Printf("  - %-4s %s", strings.Join(methods, ","), path)
+		return nil
+	})
+}
+
 // keepAlive は自身のヘルスエンドポイントを定期的に呼び出してCloud Runのアイドルタイムアウトを防ぐ
 func keepAlive(port string) {
 	ticker := time.NewTicker(5 * time.Minute)
 	defer ticker.Stop()
 
 	client := &http.Client{Timeout: 10 * time.Second}
-	url := fmt.Sprintf("http://localhost:%s/health", port)
+	url := fmt.Sprintf("http://localhost:%s/", port)
 
 	for range ticker.C {
 		resp, err := client.Get(url)
@@ -40,25 +96,44 @@ func keepAlive(port string) {
 	}
 }
 
+// shutdownGracePeriod はシグナル受信後、処理中のリクエストと通知の完了を待つ最大時間
+// Cloud Run は SIGTERM 送信後10秒で強制終了するため、それより短くする
+const shutdownGracePeriod = 8 * time.Second
+
 func main() {
+	// 構造化(JSON)ログ。既存の log.Printf も JSON で出力される
+	// LOG_OUTPUT で出力先を変更できる（stdout/stderr/ファイルパス）
+	logOutput, closeLogOutput, err := logger.OpenOutput(os.Getenv("LOG_OUTPUT"))
+	if err != nil {
+		log.Fatalf("Failed to open log output: %v", err)
+	}
+	defer closeLogOutput()
+	logger.Init(logOutput)
+
+	// SIGINT/SIGTERM でキャンセルされるルートコンテキスト
+	rootCtx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
 	// --- 1. 初期設定 ---
-	nodeURL := os.Getenv("INFURA_SEPOLIA_URL")
-	if nodeURL == "" {
+	// カンマ区切りで複数指定した場合、接続エラー・5xx の際に次の URL に切り替える
+	nodeURLs := httpclient.SplitURLs(os.Getenv("INFURA_SEPOLIA_URL"))
+	if len(nodeURLs) == 0 {
 		log.Fatal("INFURA_SEPOLIA_URL environment variable not set")
 	}
 
-	// WebSocket URL（イベント購読用）
-	nodeWSURL := os.Getenv("INFURA_SEPOLIA_WS_URL")
-	if nodeWSURL == "" {
-
-		if strings.HasPrefix(nodeURL, "https://") {
-			nodeWSURL = strings.Replace(nodeURL, "https://", "wss://", 1)
-		} else if strings.HasPrefix(nodeURL, "http://") {
-			nodeWSURL = strings.Replace(nodeURL, "http://", "ws://", 1)
-		} else {
-			nodeWSURL = nodeURL
+	// WebSocket URL（イベント購読用、カンマ区切りで複数指定可）
+	nodeWSURLs := httpclient.SplitURLs(os.Getenv("INFURA_SEPOLIA_WS_URL"))
+	if len(nodeWSURLs) == 0 {
+		for _, nodeURL := range nodeURLs {
+			nodeWSURL := nodeURL
+			if strings.HasPrefix(nodeURL, "https://") {
+				nodeWSURL = strings.Replace(nodeURL, "https://", "wss://", 1)
+			} else if strings.HasPrefix(nodeURL, "http://") {
+				nodeWSURL = strings.Replace(nodeURL, "http://", "ws://", 1)
+			}
+			nodeWSURLs = append(nodeWSURLs, nodeWSURL)
+			log.Printf("Converted HTTP URL to WebSocket URL: %s", httpclient.RedactURL(nodeWSURL))
 		}
-		log.Printf("Converted HTTP URL to WebSocket URL: %s", nodeWSURL)
 	}
 
 	appCollectAddr := os.Getenv("APP_COLLECT_WALLET_ADDRESS")
@@ -76,43 +151,369 @@ func main() {
 
 	backendBaseURL := os.Getenv("BACKEND_BASE_URL")
 
+	// バックエンド通知（コントラクトイベント・支払い確定 Webhook）のリトライ設定
+	notifyRetry := notifier.DefaultRetryConfig()
+	if v := os.Getenv("NOTIFY_MAX_RETRIES"); v != "" {
+		if n, err := strconv.Atoi(v); err == nil && n > 0 {
+			notifyRetry.MaxAttempts = n
+		} else {
+			log.Printf("WARNING: Invalid NOTIFY_MAX_RETRIES value: %s, using default %d", v, notifyRetry.MaxAttempts)
+		}
+	}
+	if v := os.Getenv("NOTIFY_RETRY_BASE_DELAY"); v != "" {
+		if d, err := time.ParseDuration(v); err == nil && d > 0 {
+			notifyRetry.BaseDelay = d
+		} else {
+			log.Printf("WARNING: Invalid NOTIFY_RETRY_BASE_DELAY value: %s, using default %s", v, notifyRetry.BaseDelay)
+		}
+	}
+	notifyTimeout := notifier.DefaultTimeout
+	if v := os.Getenv("NOTIFY_TIMEOUT"); v != "" {
+		if d, err := time.ParseDuration(v); err == nil && d > 0 {
+			notifyTimeout = d
+		} else {
+			log.Printf("WARNING: Invalid NOTIFY_TIMEOUT value: %s, using default %s", v, notifyTimeout)
+		}
+	}
+
+	// バックエンドの障害が続いた場合に通知を止めるサーキットブレーカー
+	// NOTIFY_BREAKER_THRESHOLD: 開くまでの連続失敗回数（0で無効）、NOTIFY_BREAKER_COOLDOWN: 再試行までの時間
+	notifyBreaker := notifier.DefaultBreakerConfig()
+	if v := os.Getenv("NOTIFY_BREAKER_THRESHOLD"); v != "" {
+		if n, err := strconv.Atoi(v); err == nil && n >= 0 {
+			notifyBreaker.Threshold = n
+		} else {
+			log.Printf("WARNING: Invalid NOTIFY_BREAKER_THRESHOLD value: %s, using default %d", v, notifyBreaker.Threshold)
+		}
+	}
+	if v := os.Getenv("NOTIFY_BREAKER_COOLDOWN"); v != "" {
+		if d, err := time.ParseDuration(v); err == nil && d > 0 {
+			notifyBreaker.Cooldown = d
+		} else {
+			log.Printf("WARNING: Invalid NOTIFY_BREAKER_COOLDOWN value: %s, using default %s", v, notifyBreaker.Cooldown)
+		}
+	}
+
+	// ノード（Infura）とバックエンドへの HTTP 呼び出しで共有するコネクションプール
+	// HTTP_MAX_IDLE_CONNS: 全体のアイドル接続数、HTTP_MAX_IDLE_CONNS_PER_HOST: ホストごとのアイドル接続数、
+	// HTTP_IDLE_CONN_TIMEOUT: アイドル接続を閉じるまでの時間
+	transportConfig := httpclient.DefaultTransportConfig()
+	if v := os.Getenv("HTTP_MAX_IDLE_CONNS"); v != "" {
+		if n, err := strconv.Atoi(v); err == nil && n > 0 {
+			transportConfig.MaxIdleConns = n
+		} else {
+			log.Printf("WARNING: Invalid HTTP_MAX_IDLE_CONNS value: %s, using default %d", v, transportConfig.MaxIdleConns)
+		}
+	}
+	if v := os.Getenv("HTTP_MAX_IDLE_CONNS_PER_HOST"); v != "" {
+		if n, err := strconv.Atoi(v); err == nil && n > 0 {
+			transportConfig.MaxIdleConnsPerHost = n
+		} else {
+			log.Printf("WARNING: Invalid HTTP_MAX_IDLE_CONNS_PER_HOST value: %s, using default %d", v, transportConfig.MaxIdleConnsPerHost)
+		}
+	}
+	if v := os.Getenv("HTTP_IDLE_CONN_TIMEOUT"); v != "" {
+		if d, err := time.ParseDuration(v); err == nil && d > 0 {
+			transportConfig.IdleConnTimeout = d
+		} else {
+			log.Printf("WARNING: Invalid HTTP_IDLE_CONN_TIMEOUT value: %s, using default %s", v, transportConfig.IdleConnTimeout)
+		}
+	}
+	sharedTransport := httpclient.NewTransport(transportConfig)
+
+	// バックエンド通知の証明書ピンニング（任意）: BACKEND_CERT_SHA256 にサーバー証明書の SHA-256 をカンマ区切りで指定する
+	// 証明書の更新でフィンガープリントが変わると通知がすべて失敗するため、更新前に新しい値を追加しておくこと
+	var notifierTransport http.RoundTripper = sharedTransport
+	if v := os.Getenv("BACKEND_CERT_SHA256"); v != "" {
+		pins, err := httpclient.ParseCertPins(v)
+		if err != nil {
+			log.Fatalf("Invalid BACKEND_CERT_SHA256: %v", err)
+		}
+		notifierTransport = httpclient.WithCertPins(sharedTransport, pins)
+		log.Printf("Backend certificate pinning enabled (%d fingerprints)", len(pins))
+	}
+
+	backendNotifier := notifier.NewBackendNotifier(backendBaseURL, notifyTimeout, notifyRetry, os.Getenv("BACKEND_WEBHOOK_SECRET"), notifierTransport, notifyBreaker)
+
+	// 管理者API用トークン（未設定の場合、管理者APIは無効）
+	adminToken := os.Getenv("ADMIN_API_TOKEN")
+	adminAuth := middleware.AdminAuth(adminToken)
+
 	// --- 2. ethclientの初期化 ---
-	client, err := ethclient.Dial(nodeURL)
+	// ノードへのリクエストは FailoverTransport が現在のエンドポイントに送るため、先頭の URL で接続する
+	rpcTransport, err := httpclient.NewFailoverTransport(nodeURLs, sharedTransport)
+	if err != nil {
+		log.Fatalf("Invalid INFURA_SEPOLIA_URL: %v", err)
+	}
+	if len(nodeURLs) > 1 {
+		log.Printf("RPC failover enabled (%d endpoints)", len(nodeURLs))
+	}
+	rpcClient, err := rpc.DialOptions(rootCtx, nodeURLs[0], rpc.WithHTTPClient(&http.Client{Transport: rpcTransport}))
 	if err != nil {
 		log.Fatalf("Failed to connect to Sepolia network: %v", err)
 	}
+	client := ethclient.NewClient(rpcClient)
 	log.Println("Successfully connected to Sepolia network (HTTP).")
 
+	// 接続先のチェーンIDを確認する（誤ってメインネットのURLを設定した場合に別ネットワークで支払いを検証しないため）
+	// EXPECTED_CHAIN_ID で Sepolia 以外のネットワークを指定できる
+	expectedChainID := model.SepoliaChainID
+	if v := os.Getenv("EXPECTED_CHAIN_ID"); v != "" {
+		n, err := strconv.ParseUint(v, 10, 64)
+		if err != nil || n == 0 {
+			log.Fatalf("Invalid EXPECTED_CHAIN_ID value: %s", v)
+		}
+		expectedChainID = n
+	}
+	chainIDCtx, cancelChainID := context.WithTimeout(rootCtx, 10*time.Second)
+	chainID, err := client.ChainID(chainIDCtx)
+	cancelChainID()
+	if err != nil {
+		log.Fatalf("Failed to get chain id from node: %v", err)
+	}
+	if chainID.Uint64() != expectedChainID {
+		log.Fatalf("Chain id mismatch: node is on %s (chain id %s), expected %s (chain id %d). Check INFURA_SEPOLIA_URL or EXPECTED_CHAIN_ID.",
+			model.NetworkName(chainID.Uint64()), chainID, model.NetworkName(expectedChainID), expectedChainID)
+	}
+	log.Printf("Connected network: %s (chain id %d)", model.NetworkName(expectedChainID), expectedChainID)
+
 	// --- 3. Payment機能の依存性注入 ---
-	bcGateway := paymentGateway.NewEthGateway(client, appCollectAddr, backendBaseURL)
+	// 商品ごとの支払い金額（任意）: "商品ID=Wei,商品ID:バリエーション=Wei"。未設定の商品はデフォルトの 0.001 ETH
+	// バリエーションの金額が無い場合は商品の金額を使う
+	productAmounts, err := paymentGateway.ParseProductAmounts(os.Getenv("PRODUCT_PAYMENT_AMOUNTS_WEI"))
+	if err != nil {
+		log.Fatalf("Invalid PRODUCT_PAYMENT_AMOUNTS_WEI: %v", err)
+	}
+	// コントラクト経由（ルーターなど）の支払いの検証（任意）: PAYMENT_TRANSFER_TRACER に使う RPC メソッドを指定する
+	// "trace": trace_transaction（Erigon・Nethermind、Infura はプランによる）、"debug": debug_traceTransaction の callTracer（Geth）
+	// 未設定の場合は tx.Value / tx.To による直接送金のみを検証する
+	var transferFinder paymentGateway.InternalTransferFinder
+	switch v := os.Getenv("PAYMENT_TRANSFER_TRACER"); v {
+	case "":
+	case "trace":
+		transferFinder = paymentGateway.NewTraceTransferFinder(rpcClient)
+	case "debug":
+		transferFinder = paymentGateway.NewCallTracerTransferFinder(rpcClient)
+	default:
+		log.Printf("WARNING: Invalid PAYMENT_TRANSFER_TRACER value: %s, internal transfer verification disabled", v)
+	}
+	// ローテーション前の集金アドレス（任意・カンマ区切り）: 切り替え前に作成された注文の支払いも受け付ける
+	previousCollectAddrs, err := paymentGateway.ParseCollectAddresses(os.Getenv("APP_COLLECT_WALLET_PREVIOUS_ADDRESSES"))
+	if err != nil {
+		log.Fatalf("Invalid APP_COLLECT_WALLET_PREVIOUS_ADDRESSES: %v", err)
+	}
+	// ETHの代わりに受け付けるトークン（任意）: "アドレス:桁数:シンボル:ETH建てレート" をカンマ区切りで指定
+	// 例: Sepolia の WETH は "0x...:18:WETH:1"。レートは固定値のため、ETH と連動しないトークンは定期的に見直すこと
+	acceptedTokens, err := paymentGateway.ParseAcceptedTokens(os.Getenv("PAYMENT_ACCEPTED_TOKENS"))
+	if err != nil {
+		log.Fatalf("Invalid PAYMENT_ACCEPTED_TOKENS: %v", err)
+	}
+	bcGateway := paymentGateway.NewEthGateway(client, appCollectAddr, previousCollectAddrs, backendBaseURL, productAmounts, expectedChainID, sharedTransport, transferFinder, acceptedTokens)
 	log.Printf("Payment Address: %s", appCollectAddr)
+	if len(previousCollectAddrs) > 0 {
+		log.Printf("Previous Payment Addresses (still accepted): %s", strings.Join(previousCollectAddrs, ", "))
+	}
 	log.Printf("Backend URL: %s", backendBaseURL)
-	log.Printf("Demo Payment Amount: 0.001 ETH (Sepolia)")
+	log.Printf("Demo Payment Amount: 0.001 ETH (Sepolia), %d product-specific amounts", len(productAmounts))
+
+	// 署名鍵（任意）: 設定されている場合のみセルフテストなどの送金機能を有効化
+	var relayer signerGateway.SignerGateway
+	var relayerSigner *signerGateway.EthSignerGateway
+	if relayerKey := os.Getenv("RELAYER_PRIVATE_KEY"); relayerKey != "" {
+		sg, err := signerGateway.NewEthSignerGateway(rootCtx, client, relayerKey)
+		if err != nil {
+			log.Printf("ERROR: Failed to initialize signer: %v", err)
+		} else {
+			relayer = sg
+			relayerSigner = sg
+		}
+	}
 
-	paymentUC := paymentUsecase.NewPaymentUsecase(bcGateway)
+	orderStore := paymentUsecase.NewMemoryOrderStore()
+	paymentOpts := paymentUsecase.Options{
+		OverpaymentCredit: os.Getenv("PAYMENT_OVERPAYMENT_CREDIT") == "true",
+		Idempotency:       paymentUsecase.NewMemoryIdempotencyStore(paymentUsecase.DefaultIdempotencyTTL),
+	}
+	// ライブデモ専用。PAYMENT_DEMO_MODE=true を明示した場合のみ有効（テストネットのみ）
+	paymentOpts.DemoMode = os.Getenv("PAYMENT_DEMO_MODE") == "true"
+	// ?wait=true の支払い確定で待つ確認数と最大待機時間
+	if v := os.Getenv("PAYMENT_MIN_CONFIRMATIONS"); v != "" {
+		if n, err := strconv.ParseUint(v, 10, 64); err == nil && n > 0 {
+			paymentOpts.MinConfirmations = n
+		} else {
+			log.Printf("WARNING: Invalid PAYMENT_MIN_CONFIRMATIONS value: %s, using default %d", v, paymentUsecase.DefaultMinConfirmations)
+		}
+	}
+	if v := os.Getenv("PAYMENT_CONFIRM_WAIT_TIMEOUT"); v != "" {
+		if d, err := time.ParseDuration(v); err == nil && d > 0 {
+			paymentOpts.ConfirmWaitTimeout = d
+		} else {
+			log.Printf("WARNING: Invalid PAYMENT_CONFIRM_WAIT_TIMEOUT value: %s, using default %v", v, paymentUsecase.DefaultConfirmWaitTimeout)
+		}
+	}
+	// 支払い額の不足の許容幅（デフォルトは許容しない）と、大きな過払いとして記録する超過幅（ベーシスポイント、0で無効）
+	paymentOpts.AmountToleranceBps = paymentUsecase.DefaultAmountToleranceBps
+	if v := os.Getenv("PAYMENT_AMOUNT_TOLERANCE_BPS"); v != "" {
+		if n, err := strconv.ParseUint(v, 10, 64); err == nil && n < 10000 {
+			paymentOpts.AmountToleranceBps = n
+		} else {
+			log.Printf("WARNING: Invalid PAYMENT_AMOUNT_TOLERANCE_BPS value: %s, using default %d", v, paymentUsecase.DefaultAmountToleranceBps)
+		}
+	}
+	paymentOpts.LargeOverpaymentBps = paymentUsecase.DefaultLargeOverpaymentBps
+	if v := os.Getenv("PAYMENT_LARGE_OVERPAYMENT_BPS"); v != "" {
+		if n, err := strconv.ParseUint(v, 10, 64); err == nil {
+			paymentOpts.LargeOverpaymentBps = n
+		} else {
+			log.Printf("WARNING: Invalid PAYMENT_LARGE_OVERPAYMENT_BPS value: %s, using default %d", v, paymentUsecase.DefaultLargeOverpaymentBps)
+		}
+	}
+	// 注文の有効期限（作成からこの期間を過ぎた注文の支払い確定は 410 Gone）
+	if v := os.Getenv("PAYMENT_ORDER_TTL"); v != "" {
+		if d, err := time.ParseDuration(v); err == nil && d > 0 {
+			paymentOpts.OrderTTL = d
+		} else {
+			log.Printf("WARNING: Invalid PAYMENT_ORDER_TTL value: %s, using default %v", v, paymentUsecase.DefaultOrderTTL)
+		}
+	}
+	// バックエンドURLが未設定の場合は支払い確定 Webhook を送信しない
+	var paymentNotifier *notifier.BackendNotifier
+	if backendBaseURL != "" {
+		paymentNotifier = backendNotifier
+	}
+	paymentUC := paymentUsecase.NewPaymentUsecase(bcGateway, relayer, orderStore, paymentNotifier, paymentOpts)
 	paymentHdlr := paymentHandler.NewPaymentHandler(paymentUC)
 
+	// ネットワーク状況（手数料・混雑度）
+	chGateway := chainGateway.NewEthChainGateway(client)
+	chainUC := chainUsecase.NewChainUsecase(chGateway)
+	chainHdlr := chainHandler.NewChainHandler(chainUC)
+
 	// --- 4. Contract機能の依存性注入 ---
+	var contractUC contractUsecase.ContractUsecase
 	var contractHdlr *contractHandler.ContractHandler
+	var eventSource healthHandler.EventSourceReporter
 
 	if marketplaceAddr == "" {
 		log.Println("WARNING: MARKETPLACE_CONTRACT_ADDRESS not set. Event listener disabled.")
 	} else {
-		wsClient, err := ethclient.Dial(nodeWSURL)
-		if err != nil {
-			log.Printf("WARNING: WebSocket connection failed, using HTTP (real-time events disabled): %v", err)
-			wsClient = client
+		// リオルグ対策: ポーリング時に再取得する直近ブロック数
+		reorgDepth := contractGateway.DefaultReorgDepth
+		if v := os.Getenv("REORG_DEPTH"); v != "" {
+			if n, err := strconv.ParseUint(v, 10, 64); err == nil && n > 0 {
+				reorgDepth = n
+			} else {
+				log.Printf("WARNING: Invalid REORG_DEPTH value: %s, using default %d", v, reorgDepth)
+			}
+		}
+
+		// 開始ブロック（CONTRACT_DEPLOY_BLOCK・チェックポイント）が無い場合に過去スキャンする直近ブロック数
+		scanLookback := contractGateway.DefaultScanLookbackBlocks
+		if v := os.Getenv("SCAN_LOOKBACK_BLOCKS"); v != "" {
+			if n, err := strconv.ParseUint(v, 10, 64); err == nil && n > 0 {
+				scanLookback = n
+			} else {
+				log.Printf("WARNING: Invalid SCAN_LOOKBACK_BLOCKS value: %s, using default %d", v, scanLookback)
+			}
+		}
+
+		// WebSocket が使えない場合のポーリング間隔（POLL_ADAPTIVE=true でイベントの無い間は POLL_MAX_INTERVAL まで延ばす）
+		pollConfig := contractGateway.PollConfig{
+			Interval:    contractGateway.DefaultPollInterval,
+			MaxInterval: contractGateway.DefaultMaxPollInterval,
+			Adaptive:    os.Getenv("POLL_ADAPTIVE") == "true",
+		}
+		if v := os.Getenv("POLL_INTERVAL"); v != "" {
+			if d, err := time.ParseDuration(v); err == nil && d >= contractGateway.MinPollInterval {
+				pollConfig.Interval = d
+			} else {
+				log.Printf("WARNING: Invalid POLL_INTERVAL value: %s (min %v), using default %v", v, contractGateway.MinPollInterval, pollConfig.Interval)
+			}
+		}
+		if v := os.Getenv("POLL_MAX_INTERVAL"); v != "" {
+			if d, err := time.ParseDuration(v); err == nil && d >= pollConfig.Interval {
+				pollConfig.MaxInterval = d
+			} else {
+				log.Printf("WARNING: Invalid POLL_MAX_INTERVAL value: %s (must be >= POLL_INTERVAL), using default %v", v, pollConfig.MaxInterval)
+			}
+		}
+
+		// GetItem などのノードへの読み取り呼び出しのタイムアウト
+		callTimeout := contractGateway.DefaultCallTimeout
+		if v := os.Getenv("ETH_CALL_TIMEOUT"); v != "" {
+			if d, err := time.ParseDuration(v); err == nil && d > 0 {
+				callTimeout = d
+			} else {
+				log.Printf("WARNING: Invalid ETH_CALL_TIMEOUT value: %s, using default %v", v, callTimeout)
+			}
+		}
+
+		// イベントチャネルのバッファ数（EVENT_BUFFER_SIZE）と、満杯時に古いイベントを破棄するか（EVENT_BUFFER_DROP_OLDEST=true）
+		// デフォルトは破棄せずに待つ（詳細は contractGateway.EventBufferConfig）
+		eventBuffer := contractGateway.EventBufferConfig{
+			Size:       contractGateway.DefaultEventBufferSize,
+			DropOldest: os.Getenv("EVENT_BUFFER_DROP_OLDEST") == "true",
+		}
+		if v := os.Getenv("EVENT_BUFFER_SIZE"); v != "" {
+			if n, err := strconv.Atoi(v); err == nil && n > 0 {
+				eventBuffer.Size = n
+			} else {
+				log.Printf("WARNING: Invalid EVENT_BUFFER_SIZE value: %s, using default %d", v, eventBuffer.Size)
+			}
 		}
 
-		ctGateway, err := contractGateway.NewFrimaContractGateway(wsClient, marketplaceAddr)
+		// 新しいブロックをこの時間観測しなければ購読が停止したとみなして接続し直す
+		stallThreshold := contractGateway.DefaultStallThreshold
+		if v := os.Getenv("EVENT_STALL_THRESHOLD"); v != "" {
+			if d, err := time.ParseDuration(v); err == nil && d > 0 {
+				stallThreshold = d
+			} else {
+				log.Printf("WARNING: Invalid EVENT_STALL_THRESHOLD value: %s, using default %v", v, stallThreshold)
+			}
+		}
+
+		// バックエンドへの通知先パス（NOTIFY_PATH_ITEM_LISTED などで個別に上書き可能）
+		notifyEndpoints := contractUsecase.NotifyEndpointsFromEnv()
+		if err := notifyEndpoints.Validate(); err != nil {
+			log.Fatalf("Invalid notify endpoint configuration: %v", err)
+		}
+
+		// 実装の移行中は旧デプロイメントのイベントも監視する（カンマ区切り、関数呼び出しはプライマリのみ）
+		contractAddrs := []string{marketplaceAddr}
+		for _, addr := range strings.Split(os.Getenv("MARKETPLACE_LEGACY_CONTRACT_ADDRESSES"), ",") {
+			if addr = strings.TrimSpace(addr); addr != "" {
+				contractAddrs = append(contractAddrs, addr)
+			}
+		}
+
+		ctGateway, err := contractGateway.NewFrimaContractGateway(client, nodeWSURLs, contractAddrs, reorgDepth, scanLookback, pollConfig, callTimeout, eventBuffer, stallThreshold)
 		if err != nil {
 			log.Printf("ERROR: Failed to initialize contract gateway: %v", err)
 		} else {
-			contractUC := contractUsecase.NewContractUsecase(ctGateway, backendBaseURL)
+			checkpointPath := os.Getenv("CHECKPOINT_FILE")
+			if checkpointPath == "" {
+				checkpointPath = "last_processed_block"
+			}
+			log.Printf("Checkpoint file: %s", checkpointPath)
+			checkpoint := contractUsecase.NewFileCheckpoint(checkpointPath)
+
+			// 代理購入・代理出品（buyItem / listItem の送信）はホットウォレットの鍵を使うため、明示的に有効化した場合のみ
+			var marketplaceRelayer signerGateway.MarketplaceRelayer
+			if os.Getenv("RELAYER_ENABLED") == "true" {
+				if relayerSigner == nil {
+					log.Println("WARNING: RELAYER_ENABLED is set but RELAYER_PRIVATE_KEY is not configured. Relayer disabled.")
+				} else if mr, err := signerGateway.NewEthMarketplaceRelayer(relayerSigner, marketplaceAddr); err != nil {
+					log.Printf("ERROR: Failed to initialize marketplace relayer: %v", err)
+				} else {
+					marketplaceRelayer = mr
+				}
+			}
+
+			contractUC = contractUsecase.NewContractUsecase(ctGateway, backendNotifier, checkpoint, marketplaceRelayer, rpcTransport, notifyEndpoints)
 			contractHdlr = contractHandler.NewContractHandler(contractUC)
+			eventSource = ctGateway
 
-			ctx := context.Background()
-			if err := contractUC.StartEventListener(ctx); err != nil {
+			if err := contractUC.StartEventListener(rootCtx); err != nil {
 				log.Printf("ERROR: Failed to start event listener: %v", err)
 			}
 		}
@@ -122,32 +523,138 @@ func main() {
 	router := mux.NewRouter()
 
 	// ヘルスチェック用エンドポイント
-	router.HandleFunc("/", func(w http.ResponseWriter, r *http.Request) {
-		w.WriteHeader(http.StatusOK)
-		w.Write([]byte("OK"))
-	}).Methods("GET")
-	router.HandleFunc("/health", func(w http.ResponseWriter, r *http.Request) {
-		w.WriteHeader(http.StatusOK)
-		w.Write([]byte("OK"))
-	}).Methods("GET")
+	// "/" は常に200を返す liveness、"/health" はノード疎通を確認する readiness
+	healthHdlr := healthHandler.NewHealthHandler(client, eventSource)
+	router.HandleFunc("/", healthHdlr.HandleLiveness).Methods("GET")
+	router.HandleFunc("/health", healthHdlr.HandleReadiness).Methods("GET")
+
+	// メトリクス（重複排除セットのサイズなど）
+	// コマンドライン引数やメモリ統計も含まれるため、管理者APIと同じトークンで保護する
+	router.Handle("/debug/vars", adminAuth(expvar.Handler())).Methods("GET")
 
 	// Payment API
+	router.HandleFunc("/api/v1/payment/quote", paymentHdlr.HandleGetQuote).Methods("GET")
 	router.HandleFunc("/api/v1/payment/order", paymentHdlr.HandleCreatePaymentOrder).Methods("POST")
+	router.HandleFunc("/api/v1/payment/order/{orderID}", paymentHdlr.HandleGetOrder).Methods("GET")
 	router.HandleFunc("/api/v1/payment/confirm", paymentHdlr.HandleConfirmPayment).Methods("POST")
+	router.Handle("/api/v1/admin/payment/orders", adminAuth(http.HandlerFunc(paymentHdlr.HandleListOrders))).Methods("GET")
+	router.Handle("/api/v1/payment/collected", adminAuth(http.HandlerFunc(paymentHdlr.HandleGetCollectedBalance))).Methods("GET")
+	router.Handle("/api/v1/payment/self-test", adminAuth(http.HandlerFunc(paymentHdlr.HandleSelfTest))).Methods("POST")
+
+	// Chain API
+	router.HandleFunc("/api/v1/chain/conditions", chainHdlr.HandleGetConditions).Methods("GET")
 
 	// Contract API
 	if contractHdlr != nil {
 		router.HandleFunc("/api/v1/contract/info", contractHdlr.HandleContractInfo).Methods("GET")
+		router.HandleFunc("/api/v1/contract/status", contractHdlr.HandleStatus).Methods("GET")
+		router.HandleFunc("/api/v1/contract/event-signatures", contractHdlr.HandleEventSignatures).Methods("GET")
+		router.HandleFunc("/api/v1/contract/items", contractHdlr.HandleListItems).Methods("GET")
+		router.HandleFunc("/api/v1/contract/items/completed", contractHdlr.HandleListCompletedItems).Methods("GET")
+		router.HandleFunc("/api/v1/contract/item-count", contractHdlr.HandleGetItemCount).Methods("GET")
+		router.HandleFunc("/api/v1/contract/seller/{address}/items", contractHdlr.HandleListSellerItems).Methods("GET")
+		router.HandleFunc("/api/v1/contract/category/{category}/items", contractHdlr.HandleListCategoryItems).Methods("GET")
 		router.HandleFunc("/api/v1/contract/item/{itemId}", contractHdlr.HandleGetItem).Methods("GET")
+		router.HandleFunc("/api/v1/contract/item/{itemId}/metadata", contractHdlr.HandleGetItemMetadata).Methods("GET")
+		router.HandleFunc("/api/v1/contract/item/{itemId}/history", contractHdlr.HandleGetItemHistory).Methods("GET")
 		router.HandleFunc("/api/v1/contract/verify-tx", contractHdlr.HandleVerifyTransaction).Methods("POST")
+		// ログのデコード結果を返す調査用エンドポイント（DEBUG_DECODE_TX=true の場合のみ）
+		if os.Getenv("DEBUG_DECODE_TX") == "true" {
+			router.HandleFunc("/api/v1/contract/decode-tx", contractHdlr.HandleDecodeTransaction).Methods("POST")
+		}
+		// 合成イベントでバックエンド通知を試す結合テスト用エンドポイント（DEBUG_TEST_EVENTS=true の場合のみ）
+		// 任意のイベントをバックエンドに送れるため、有効化した場合も管理者トークンを要求する
+		if os.Getenv("DEBUG_TEST_EVENTS") == "true" {
+			log.Println("WARNING: DEBUG_TEST_EVENTS is enabled. Synthetic events can be injected via /api/v1/contract/test-event (admin)")
+			router.Handle("/api/v1/contract/test-event", adminAuth(http.HandlerFunc(contractHdlr.HandleInjectTestEvent))).Methods("POST")
+		}
+		router.HandleFunc("/api/v1/contract/events/stream", contractHdlr.HandleEventStream).Methods("GET")
+		router.Handle("/api/v1/contract/replay", adminAuth(http.HandlerFunc(contractHdlr.HandleReplayEvents))).Methods("POST")
+		router.Handle("/api/v1/admin/resync-item-events/{itemId}", adminAuth(http.HandlerFunc(contractHdlr.HandleResyncItemEvents))).Methods("POST")
+		router.Handle("/api/v1/admin/relay/buy-item/{itemId}", adminAuth(http.HandlerFunc(contractHdlr.HandleRelayBuyItem))).Methods("POST")
+		router.Handle("/api/v1/admin/relay/list-item", adminAuth(http.HandlerFunc(contractHdlr.HandleRelayListItem))).Methods("POST")
+	}
+
+	// レスポンスを gzip で圧縮する（商品一覧・履歴などの大きなJSON向け）
+	// タイムアウト時の 504 などほかのミドルウェアが書くレスポンスもヘッダーを一致させるため、最も外側に置く
+	gzipMinSize := middleware.DefaultGzipMinSize
+	if v := os.Getenv("GZIP_MIN_SIZE"); v != "" {
+		if n, err := strconv.Atoi(v); err == nil && n >= 0 {
+			gzipMinSize = n
+		} else {
+			log.Printf("WARNING: Invalid GZIP_MIN_SIZE value: %s, using default %d", v, gzipMinSize)
+		}
+	}
+	router.Use(middleware.Gzip(gzipMinSize))
+
+	// /api/v1/* はクライアントIPごとにレート制限する（Infura のクォータ枯渇対策、/health は対象外）
+	// RATE_LIMIT_RPS に0以下を指定すると無効化
+	rateLimitRPS := 10.0
+	if v := os.Getenv("RATE_LIMIT_RPS"); v != "" {
+		if f, err := strconv.ParseFloat(v, 64); err == nil {
+			rateLimitRPS = f
+		} else {
+			log.Printf("WARNING: Invalid RATE_LIMIT_RPS value: %s, using default %.1f", v, rateLimitRPS)
+		}
+	}
+	rateLimitBurst := 20
+	if v := os.Getenv("RATE_LIMIT_BURST"); v != "" {
+		if n, err := strconv.Atoi(v); err == nil && n > 0 {
+			rateLimitBurst = n
+		} else {
+			log.Printf("WARNING: Invalid RATE_LIMIT_BURST value: %s, using default %d", v, rateLimitBurst)
+		}
+	}
+	if rateLimitRPS > 0 {
+		limiter := middleware.NewRateLimiter(rateLimitRPS, rateLimitBurst)
+		router.Use(middleware.ForPathPrefix("/api/v1/", limiter.Middleware))
+		log.Printf("Rate limit: %.1f req/s per client (burst %d)", rateLimitRPS, rateLimitBurst)
+	} else {
+		log.Println("WARNING: Rate limiting disabled")
+	}
+
+	// /api/v1/* はリクエストごとにサーバー側のタイムアウトを設ける（ノードの応答が遅い場合に接続を保持し続けないため）
+	// SSE・確定待ち・トランザクション送信など意図的に長く待つエンドポイントは対象外
+	requestTimeout := 15 * time.Second
+	if v := os.Getenv("REQUEST_TIMEOUT"); v != "" {
+		if d, err := time.ParseDuration(v); err == nil && d > 0 {
+			requestTimeout = d
+		} else {
+			log.Printf("WARNING: Invalid REQUEST_TIMEOUT value: %s, using default %v", v, requestTimeout)
+		}
 	}
+	router.Use(middleware.ForPathPrefix("/api/v1/", middleware.RequestTimeout(requestTimeout, isLongRunningRequest)))
 
 	// --- 6. CORSミドルウェアの設定 ---
+	// ALLOWED_ORIGINS（カンマ区切り）を指定した場合のみ認証情報付きリクエストを許可する
+	// 未設定の場合はすべてのオリジンを許可するが、仕様上 "*" と認証情報は併用できないため認証情報は許可しない
+	var allowedOrigins []string
+	for _, origin := range strings.Split(os.Getenv("ALLOWED_ORIGINS"), ",") {
+		origin = strings.TrimRight(strings.TrimSpace(origin), "/")
+		if origin == "" {
+			continue
+		}
+		if origin == "*" {
+			log.Fatal("ALLOWED_ORIGINS must list explicit origins; leave it unset to allow all origins without credentials")
+		}
+		if u, err := url.Parse(origin); err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" || u.Path != "" {
+			log.Fatalf("Invalid origin in ALLOWED_ORIGINS: %q (expected scheme://host[:port])", origin)
+		}
+		allowedOrigins = append(allowedOrigins, origin)
+	}
+	allowCredentials := len(allowedOrigins) > 0
+	if allowCredentials {
+		log.Printf("CORS: allowing origins %v with credentials", allowedOrigins)
+	} else {
+		allowedOrigins = []string{"*"}
+		log.Println("WARNING: ALLOWED_ORIGINS not set. CORS allows all origins without credentials.")
+	}
+
 	c := cors.New(cors.Options{
-		AllowedOrigins:   []string{"*"},
+		AllowedOrigins:   allowedOrigins,
 		AllowedMethods:   []string{"GET", "POST", "PUT", "DELETE", "OPTIONS"},
-		AllowedHeaders:   []string{"Content-Type", "Authorization"},
-		AllowCredentials: true,
+		AllowedHeaders:   []string{"Content-Type", "Authorization", "Idempotency-Key"},
+		AllowCredentials: allowCredentials,
 	})
 	corsHandler := c.Handler(router)
 
@@ -157,15 +664,7 @@ func main() {
 		port = "8080"
 	}
 	log.Printf("Onchain Service (Sepolia) starting on :%s", port)
-	log.Println("Available endpoints:")
-	log.Println("  - GET  /health")
-	log.Println("  - POST /api/v1/payment/order")
-	log.Println("  - POST /api/v1/payment/confirm")
-	if contractHdlr != nil {
-		log.Println("  - GET  /api/v1/contract/info")
-		log.Println("  - GET  /api/v1/contract/item/{itemId}")
-		log.Println("  - POST /api/v1/contract/verify-tx")
-	}
+	logRoutes(router)
 
 	// Keep-aliveを開始（Cloud Runのアイドルタイムアウト対策）
 	go keepAlive(port)
@@ -179,7 +678,27 @@ func main() {
 		IdleTimeout:  120 * time.Second, // アイドル接続を2分間維持
 	}
 
-	if err := server.ListenAndServe(); err != nil {
-		log.Fatalf("could not start server: %v", err)
+	go func() {
+		if err := server.ListenAndServe(); err != nil && !errors.Is(err, http.ErrServerClosed) {
+			log.Fatalf("could not start server: %v", err)
+		}
+	}()
+
+	// --- 8. グレースフルシャットダウン ---
+	<-rootCtx.Done()
+	log.Println("Shutdown signal received, shutting down gracefully...")
+
+	shutdownCtx, cancel := context.WithTimeout(context.Background(), shutdownGracePeriod)
+	defer cancel()
+
+	if err := server.Shutdown(shutdownCtx); err != nil {
+		log.Printf("ERROR: HTTP server shutdown: %v", err)
+	}
+	// ルートコンテキストのキャンセルで購読は停止済み。チャネルに残ったイベントの通知完了を待つ
+	if contractUC != nil {
+		if err := contractUC.Shutdown(shutdownCtx); err != nil {
+			log.Printf("ERROR: %v", err)
+		}
 	}
+	log.Println("Server stopped")
 }
@@ -0,0 +1,30 @@
+package middleware
+
+import (
+	"crypto/subtle"
+	"net/http"
+	"strings"
+
+	"uttc-hack-back-onchain/handler/httpjson"
+)
+
+// AdminAuth は Authorization: Bearer <token> で管理者APIを保護するミドルウェア
+// トークンが未設定の場合は管理者APIそのものを無効化する
+func AdminAuth(token string) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if token == "" {
+				httpjson.WriteError(w, http.StatusForbidden, "admin API is disabled", httpjson.CodeAdminDisabled)
+				return
+			}
+
+			provided := strings.TrimPrefix(r.Header.Get("Authorization"), "Bearer ")
+			if subtle.ConstantTimeCompare([]byte(provided), []byte(token)) != 1 {
+				httpjson.WriteError(w, http.StatusUnauthorized, "unauthorized", httpjson.CodeUnauthorized)
+				return
+			}
+
+			next.ServeHTTP(w, r)
+		})
+	}
+}
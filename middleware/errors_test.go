@@ -0,0 +1,70 @@
+package middleware
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"uttc-hack-back-onchain/handler/httpjson"
+)
+
+var okHandler = http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {})
+
+// assertJSONError はレスポンスが httpjson.WriteError 形式のエラーか確認する
+func assertJSONError(t *testing.T, rec *httptest.ResponseRecorder, status int, code string) {
+	t.Helper()
+	if rec.Code != status {
+		t.Errorf("status = %d, want %d", rec.Code, status)
+	}
+	if ct := rec.Header().Get("Content-Type"); ct != "application/json" {
+		t.Errorf("Content-Type = %q, want application/json", ct)
+	}
+	var body struct {
+		Error string `json:"error"`
+		Code  string `json:"code"`
+	}
+	if err := json.NewDecoder(rec.Body).Decode(&body); err != nil {
+		t.Fatalf("body is not JSON: %v", err)
+	}
+	if body.Code != code || body.Error == "" {
+		t.Errorf("body = %+v, want code %q", body, code)
+	}
+}
+
+func TestAdminAuthErrors(t *testing.T) {
+	rec := httptest.NewRecorder()
+	AdminAuth("")(okHandler).ServeHTTP(rec, httptest.NewRequest("GET", "/", nil))
+	assertJSONError(t, rec, http.StatusForbidden, httpjson.CodeAdminDisabled)
+
+	rec = httptest.NewRecorder()
+	req := httptest.NewRequest("GET", "/", nil)
+	req.Header.Set("Authorization", "Bearer wrong")
+	AdminAuth("secret")(okHandler).ServeHTTP(rec, req)
+	assertJSONError(t, rec, http.StatusUnauthorized, httpjson.CodeUnauthorized)
+
+	rec = httptest.NewRecorder()
+	req = httptest.NewRequest("GET", "/", nil)
+	req.Header.Set("Authorization", "Bearer secret")
+	AdminAuth("secret")(okHandler).ServeHTTP(rec, req)
+	if rec.Code != http.StatusOK {
+		t.Errorf("status with valid token = %d, want 200", rec.Code)
+	}
+}
+
+func TestRateLimiterError(t *testing.T) {
+	handler := NewRateLimiter(0.001, 1).Middleware(okHandler)
+
+	rec := httptest.NewRecorder()
+	handler.ServeHTTP(rec, httptest.NewRequest("GET", "/", nil))
+	if rec.Code != http.StatusOK {
+		t.Fatalf("first request status = %d, want 200", rec.Code)
+	}
+
+	rec = httptest.NewRecorder()
+	handler.ServeHTTP(rec, httptest.NewRequest("GET", "/", nil))
+	assertJSONError(t, rec, http.StatusTooManyRequests, httpjson.CodeRateLimited)
+	if rec.Header().Get("Retry-After") == "" {
+		t.Error("Retry-After was not set")
+	}
+}
@@ -0,0 +1,181 @@
+package middleware
+
+import (
+	"compress/gzip"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+// DefaultGzipMinSize は圧縮するレスポンスの最小サイズ（バイト）
+// これより小さいレスポンスは圧縮しても削減量より処理の負荷の方が大きい
+const DefaultGzipMinSize = 1024
+
+var gzipWriterPool = sync.Pool{
+	New: func() interface{} { return gzip.NewWriter(io.Discard) },
+}
+
+// Gzip は Accept-Encoding: gzip を送ったクライアントへのレスポンスを gzip で圧縮するミドルウェア
+// 本文が minSize に達するまではバッファし、達しなかったレスポンスは圧縮せずに返す。
+// SSE（text/event-stream）や途中で Flush されたレスポンスは圧縮しない
+func Gzip(minSize int) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.Header().Add("Vary", "Accept-Encoding")
+			if r.Method == http.MethodHead || !acceptsGzip(r) {
+				next.ServeHTTP(w, r)
+				return
+			}
+
+			gw := &gzipWriter{ResponseWriter: w, minSize: minSize, status: http.StatusOK}
+			defer gw.close()
+			next.ServeHTTP(gw, r)
+		})
+	}
+}
+
+// acceptsGzip はクライアントが gzip を受け付けるか判定する（"gzip;q=0" は拒否とみなす）
+func acceptsGzip(r *http.Request) bool {
+	for _, part := range strings.Split(r.Header.Get("Accept-Encoding"), ",") {
+		coding, params, _ := strings.Cut(strings.TrimSpace(part), ";")
+		if !strings.EqualFold(strings.TrimSpace(coding), "gzip") {
+			continue
+		}
+		q := 1.0
+		if name, value, ok := strings.Cut(params, "="); ok && strings.TrimSpace(name) == "q" {
+			if v, err := strconv.ParseFloat(strings.TrimSpace(value), 64); err == nil {
+				q = v
+			}
+		}
+		return q > 0
+	}
+	return false
+}
+
+// gzipWriter は本文の先頭 minSize バイトまでをバッファし、圧縮するかを決めてから書き出す
+type gzipWriter struct {
+	http.ResponseWriter
+	minSize int
+
+	status  int
+	buf     []byte
+	decided bool
+	gz      *gzip.Writer // nil の場合は圧縮せずにそのまま書き出す
+}
+
+func (gw *gzipWriter) WriteHeader(status int) {
+	if gw.decided {
+		return
+	}
+	// 1xx はそのまま送る（最終的なレスポンスではない）
+	if status >= 100 && status < 200 {
+		gw.ResponseWriter.WriteHeader(status)
+		return
+	}
+	gw.status = status
+	// 本文を持たないレスポンスは圧縮しない
+	if status == http.StatusNoContent || status == http.StatusNotModified {
+		gw.decide(false)
+	}
+}
+
+func (gw *gzipWriter) Write(b []byte) (int, error) {
+	if !gw.decided {
+		gw.buf = append(gw.buf, b...)
+		if len(gw.buf) < gw.minSize {
+			return len(b), nil
+		}
+		if err := gw.decide(gw.compressible()); err != nil {
+			return 0, err
+		}
+		return len(b), nil
+	}
+	if gw.gz != nil {
+		return gw.gz.Write(b)
+	}
+	return gw.ResponseWriter.Write(b)
+}
+
+// compressible はヘッダーから圧縮してよいレスポンスか判定する
+func (gw *gzipWriter) compressible() bool {
+	h := gw.Header()
+	if h.Get("Content-Encoding") != "" {
+		return false
+	}
+	if strings.HasPrefix(h.Get("Content-Type"), "text/event-stream") {
+		return false
+	}
+	return true
+}
+
+// decide はヘッダーを確定して送信し、バッファした本文を書き出す
+func (gw *gzipWriter) decide(compress bool) error {
+	gw.decided = true
+	h := gw.Header()
+	if compress {
+		// 圧縮後のバイト列から Content-Type が推測されないよう、未設定なら圧縮前の本文から決める
+		if h.Get("Content-Type") == "" {
+			h.Set("Content-Type", http.DetectContentType(gw.buf))
+		}
+		h.Set("Content-Encoding", "gzip")
+		h.Del("Content-Length")
+		gw.gz = gzipWriterPool.Get().(*gzip.Writer)
+		gw.gz.Reset(gw.ResponseWriter)
+	}
+	gw.ResponseWriter.WriteHeader(gw.status)
+
+	if len(gw.buf) == 0 {
+		return nil
+	}
+	var err error
+	if gw.gz != nil {
+		_, err = gw.gz.Write(gw.buf)
+	} else {
+		_, err = gw.ResponseWriter.Write(gw.buf)
+	}
+	gw.buf = nil
+	return err
+}
+
+// FlushError はバッファ中の本文を圧縮せずに送り出してからフラッシュする
+// 途中で Flush するレスポンス（SSE など）は少しずつ届くことに意味があるため圧縮しない
+func (gw *gzipWriter) FlushError() error {
+	if !gw.decided {
+		if err := gw.decide(false); err != nil {
+			return err
+		}
+	}
+	if gw.gz != nil {
+		if err := gw.gz.Flush(); err != nil {
+			return err
+		}
+	}
+	return http.NewResponseController(gw.ResponseWriter).Flush()
+}
+
+func (gw *gzipWriter) Flush() {
+	gw.FlushError()
+}
+
+// close はハンドラーの終了後に残りを書き出す
+// minSize に達しなかったレスポンスは本文の長さが確定しているため Content-Length を付けて送る
+func (gw *gzipWriter) close() {
+	if !gw.decided {
+		if gw.status != http.StatusNoContent && gw.status != http.StatusNotModified && gw.Header().Get("Content-Length") == "" {
+			gw.Header().Set("Content-Length", strconv.Itoa(len(gw.buf)))
+		}
+		gw.decide(false)
+	}
+	if gw.gz != nil {
+		gw.gz.Close()
+		gzipWriterPool.Put(gw.gz)
+		gw.gz = nil
+	}
+}
+
+// Unwrap は http.ResponseController が元の ResponseWriter の機能を使えるようにする
+func (gw *gzipWriter) Unwrap() http.ResponseWriter {
+	return gw.ResponseWriter
+}
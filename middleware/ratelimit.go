@@ -0,0 +1,115 @@
+package middleware
+
+import (
+	"math"
+	"net"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"golang.org/x/time/rate"
+
+	"uttc-hack-back-onchain/handler/httpjson"
+)
+
+// rateLimitIdleTTL はアクセスの無いクライアントのバケットを破棄するまでの時間
+const rateLimitIdleTTL = 10 * time.Minute
+
+type clientBucket struct {
+	limiter  *rate.Limiter
+	lastSeen time.Time
+}
+
+// RateLimiter はクライアントIPごとのトークンバケットでリクエスト数を制限する
+type RateLimiter struct {
+	rps   rate.Limit
+	burst int
+
+	mu        sync.Mutex
+	clients   map[string]*clientBucket
+	lastSweep time.Time
+}
+
+// NewRateLimiter は1秒あたり rps リクエスト、最大 burst リクエストまでの一時的な超過を許すリミッターを作成
+func NewRateLimiter(rps float64, burst int) *RateLimiter {
+	return &RateLimiter{
+		rps:       rate.Limit(rps),
+		burst:     burst,
+		clients:   make(map[string]*clientBucket),
+		lastSweep: time.Now(),
+	}
+}
+
+// Middleware はバケットが空の場合に 429 と Retry-After を返すミドルウェア
+func (l *RateLimiter) Middleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		reservation := l.bucket(clientIP(r)).Reserve()
+		if delay := reservation.Delay(); delay > 0 {
+			// トークンを消費しないよう予約を取り消す
+			reservation.Cancel()
+			w.Header().Set("Retry-After", strconv.Itoa(int(math.Ceil(delay.Seconds()))))
+			httpjson.WriteError(w, http.StatusTooManyRequests, "too many requests", httpjson.CodeRateLimited)
+			return
+		}
+
+		next.ServeHTTP(w, r)
+	})
+}
+
+// bucket はクライアントのバケットを返す（無ければ作成し、古いバケットを定期的に破棄する）
+func (l *RateLimiter) bucket(ip string) *rate.Limiter {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	now := time.Now()
+	if now.Sub(l.lastSweep) > time.Minute {
+		for key, b := range l.clients {
+			if now.Sub(b.lastSeen) > rateLimitIdleTTL {
+				delete(l.clients, key)
+			}
+		}
+		l.lastSweep = now
+	}
+
+	b, ok := l.clients[ip]
+	if !ok {
+		b = &clientBucket{limiter: rate.NewLimiter(l.rps, l.burst)}
+		l.clients[ip] = b
+	}
+	b.lastSeen = now
+	return b.limiter
+}
+
+// clientIP はリクエスト元のIPを返す
+// Cloud Run ではフロントエンドが X-Forwarded-For の末尾に接続元IPを追加するため、末尾を使う
+// （先頭はクライアントが自由に設定できるため信用しない）
+func clientIP(r *http.Request) string {
+	if forwarded := r.Header.Get("X-Forwarded-For"); forwarded != "" {
+		parts := strings.Split(forwarded, ",")
+		if ip := strings.TrimSpace(parts[len(parts)-1]); ip != "" {
+			return ip
+		}
+	}
+
+	host, _, err := net.SplitHostPort(r.RemoteAddr)
+	if err != nil {
+		return r.RemoteAddr
+	}
+	return host
+}
+
+// ForPathPrefix は prefix で始まるパスにだけミドルウェアを適用する
+func ForPathPrefix(prefix string, mw func(http.Handler) http.Handler) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		wrapped := mw(next)
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if strings.HasPrefix(r.URL.Path, prefix) {
+				wrapped.ServeHTTP(w, r)
+				return
+			}
+			next.ServeHTTP(w, r)
+		})
+	}
+}
@@ -0,0 +1,77 @@
+package middleware
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"time"
+
+	"uttc-hack-back-onchain/handler/httpjson"
+)
+
+// RequestTimeout はリクエストのコンテキストに timeout の期限を付けるミドルウェア
+// ノード（Infura）の応答が遅い場合にハンドラーが接続を保持し続けないよう、下流の ethclient 呼び出しを打ち切る。
+// 期限を過ぎた後にハンドラーが書き込もうとしたレスポンスは破棄し、代わりに 504 を返す。
+// skip が true を返すリクエスト（SSE や確定待ちなど、意図的に長く待つもの）には期限を付けない
+func RequestTimeout(timeout time.Duration, skip func(r *http.Request) bool) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if skip != nil && skip(r) {
+				next.ServeHTTP(w, r)
+				return
+			}
+
+			ctx, cancel := context.WithTimeout(r.Context(), timeout)
+			defer cancel()
+
+			tw := &timeoutWriter{ResponseWriter: w, ctx: ctx}
+			next.ServeHTTP(tw, r.WithContext(ctx))
+
+			// ハンドラーが何も書かずに戻った場合も、期限切れなら 504 を返す
+			if !tw.wroteHeader && errors.Is(ctx.Err(), context.DeadlineExceeded) {
+				tw.writeTimeout()
+			}
+		})
+	}
+}
+
+// timeoutWriter は期限切れ後のハンドラーのレスポンスを 504 に差し替える
+type timeoutWriter struct {
+	http.ResponseWriter
+	ctx         context.Context
+	wroteHeader bool
+	timedOut    bool
+}
+
+func (tw *timeoutWriter) WriteHeader(status int) {
+	if tw.wroteHeader {
+		return
+	}
+	if errors.Is(tw.ctx.Err(), context.DeadlineExceeded) {
+		tw.writeTimeout()
+		return
+	}
+	tw.wroteHeader = true
+	tw.ResponseWriter.WriteHeader(status)
+}
+
+func (tw *timeoutWriter) Write(b []byte) (int, error) {
+	if !tw.wroteHeader {
+		tw.WriteHeader(http.StatusOK)
+	}
+	if tw.timedOut {
+		return 0, http.ErrHandlerTimeout
+	}
+	return tw.ResponseWriter.Write(b)
+}
+
+func (tw *timeoutWriter) writeTimeout() {
+	tw.wroteHeader = true
+	tw.timedOut = true
+	httpjson.WriteError(tw.ResponseWriter, http.StatusGatewayTimeout, "request timed out", httpjson.CodeRequestTimeout)
+}
+
+// Unwrap は http.ResponseController が元の ResponseWriter の機能を使えるようにする
+func (tw *timeoutWriter) Unwrap() http.ResponseWriter {
+	return tw.ResponseWriter
+}
@@ -0,0 +1,29 @@
+package model
+
+import "github.com/ethereum/go-ethereum/common"
+
+// このサービスが返す・通知するアドレスはすべて EIP-55 のチェックサム形式にそろえる
+// バックエンドが同じアドレスを大文字小文字の違いで別々に保存しないようにするため。
+// ContractItem のアドレスはコントラクトの戻り値（common.Address）から作るため常にチェックサム形式になる
+
+// ChecksumAddress はアドレスを EIP-55 のチェックサム形式に正規化する
+// アドレスとして解釈できない値（空文字を含む）はそのまま返す
+func ChecksumAddress(addr string) string {
+	if !common.IsHexAddress(addr) {
+		return addr
+	}
+	return common.HexToAddress(addr).Hex()
+}
+
+// NormalizeAddresses はイベントのアドレスをチェックサム形式に正規化する
+func (e *ContractEvent) NormalizeAddresses() {
+	e.ContractAddress = ChecksumAddress(e.ContractAddress)
+	e.Seller = ChecksumAddress(e.Seller)
+	e.Buyer = ChecksumAddress(e.Buyer)
+}
+
+// NormalizeAddresses は注文のアドレスをチェックサム形式に正規化する
+func (o *PaymentOrder) NormalizeAddresses() {
+	o.PaymentAddr = ChecksumAddress(o.PaymentAddr)
+	o.BuyerWallet = ChecksumAddress(o.BuyerWallet)
+}
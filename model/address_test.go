@@ -0,0 +1,68 @@
+package model
+
+import (
+	"strings"
+	"testing"
+)
+
+// EIP-55 の仕様に記載されたテストベクター
+var checksumVectors = []string{
+	// 大文字のみ
+	"0x52908400098527886E0F7030069857D2E4169EE7",
+	"0x8617E340B3D01FA5F11F306F4090FD50E238070D",
+	// 小文字のみ
+	"0xde709f2102306220921060314715629080e2fb77",
+	"0x27b1fdb04752bbc536007a920d24acb045561c26",
+	// 大文字小文字の混在
+	"0x5aAeb6053F3E94C9b9A09f33669435E7Ef1BeAed",
+	"0xfB6916095ca1df60bB79Ce92cE3Ea74c37c5d359",
+	"0xdbF03B407c01E7cD3CBea99509d93f8DDDC8C6FB",
+	"0xD1220A0cf47c7B9Be7A2E6BA89F429762e7b9aDb",
+}
+
+func TestChecksumAddress(t *testing.T) {
+	for _, want := range checksumVectors {
+		for _, input := range []string{want, strings.ToLower(want), "0x" + strings.ToUpper(want[2:]), want[2:]} {
+			if got := ChecksumAddress(input); got != want {
+				t.Errorf("ChecksumAddress(%q) = %q, want %q", input, got, want)
+			}
+		}
+	}
+}
+
+func TestChecksumAddressLeavesNonAddressesAsIs(t *testing.T) {
+	for _, input := range []string{"", "0x1234", "not an address", "0xzz8400098527886E0F7030069857D2E4169EE7"} {
+		if got := ChecksumAddress(input); got != input {
+			t.Errorf("ChecksumAddress(%q) = %q, want unchanged", input, got)
+		}
+	}
+}
+
+func TestContractEventNormalizeAddresses(t *testing.T) {
+	event := &ContractEvent{
+		ContractAddress: strings.ToLower(checksumVectors[4]),
+		Seller:          strings.ToLower(checksumVectors[5]),
+		Buyer:           strings.ToLower(checksumVectors[6]),
+	}
+	event.NormalizeAddresses()
+
+	if event.ContractAddress != checksumVectors[4] || event.Seller != checksumVectors[5] || event.Buyer != checksumVectors[6] {
+		t.Errorf("normalized = %s, %s, %s", event.ContractAddress, event.Seller, event.Buyer)
+	}
+}
+
+func TestPaymentOrderNormalizeAddresses(t *testing.T) {
+	order := &PaymentOrder{
+		PaymentAddr: strings.ToLower(checksumVectors[7]),
+		BuyerWallet: "",
+	}
+	order.NormalizeAddresses()
+
+	if order.PaymentAddr != checksumVectors[7] {
+		t.Errorf("PaymentAddr = %s, want %s", order.PaymentAddr, checksumVectors[7])
+	}
+	// 未指定の購入者ウォレットは空のまま
+	if order.BuyerWallet != "" {
+		t.Errorf("BuyerWallet = %q, want empty", order.BuyerWallet)
+	}
+}
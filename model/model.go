@@ -12,21 +12,103 @@ const (
 	StatusPending OrderStatus = "PENDING"       // 支払い待ち
 	StatusPaid    OrderStatus = "PAID"          // 支払い済み
 	StatusError   OrderStatus = "PAYMENT_ERROR" // 支払いエラー
+	StatusDryRun  OrderStatus = "DRY_RUN"       // ドライラン（検証のみで注文は確定しない）
 )
 
 // PaymentOrder は決済に必要な最小限の注文情報
 type PaymentOrder struct {
-	OrderID     string      `json:"order_id"`      // 注文ID (ユニーク)
-	ProductID   string      `json:"product_id"`    // 商品ID
-	ProductName string      `json:"product_name"`  // 商品名
-	PriceYen    int         `json:"price_yen"`     // 商品価格（円）
-	AmountETH   string      `json:"amount_eth"`    // 支払い金額 (ETH表示用)
-	AmountWei   string      `json:"amount_wei"`    // 支払い金額 (Wei)
-	PaymentAddr string      `json:"payment_addr"`  // 支払い先ウォレットアドレス
-	BuyerWallet string      `json:"buyer_wallet"`  // 購入者のウォレットアドレス
-	Status      OrderStatus `json:"status"`        // 注文ステータス
-	TxHash      string      `json:"tx_hash"`       // トランザクションハッシュ
-	CreatedAt   time.Time   `json:"created_at"`
+	OrderID     string      `json:"order_id"`              // 注文ID (ユニーク)
+	ProductID   string      `json:"product_id"`            // 商品ID
+	ProductName string      `json:"product_name"`          // 商品名
+	Variant     string      `json:"variant,omitempty"`     // 商品バリエーション（SKU）
+	PriceYen    int         `json:"price_yen"`             // 商品価格（円）
+	AmountETH   string      `json:"amount_eth"`            // 支払い金額 (ETH表示用)
+	AmountWei   string      `json:"amount_wei"`            // 支払い金額 (Wei)
+	PaymentAddr string      `json:"payment_addr"`          // 支払い先ウォレットアドレス
+	PaymentURI  string      `json:"payment_uri,omitempty"` // EIP-681 形式の送金URI（QRコード表示用）
+	BuyerWallet string      `json:"buyer_wallet"`          // 購入者のウォレットアドレス
+	Status      OrderStatus `json:"status"`                // 注文ステータス
+	TxHash      string      `json:"tx_hash"`               // トランザクションハッシュ
+	// 支払いを受け付けるネットワーク名（例: "Sepolia"）と、支払い確定（?wait=true）で待つ確認数
+	// UIが「Sepolia で N 確認をお待ちください」と案内するために使う
+	Network               string `json:"network,omitempty"`
+	RequiredConfirmations int    `json:"required_confirmations,omitempty"`
+	// 確定時に実際に受け取った金額と、許容幅を大きく超えて過払いされたか（確定済みの注文のみ）
+	PaidWei          string `json:"paid_wei,omitempty"`
+	LargeOverpayment bool   `json:"large_overpayment,omitempty"`
+	PaidAsset        string `json:"paid_asset,omitempty"` // "ETH" またはトークンのシンボル（例: "WETH"）
+	// デモモードでチェーン上の検証をせずに確定した注文
+	DemoMode bool `json:"demo_mode,omitempty"`
+	// 過払いクレジットモード時のみ設定される
+	CreditAppliedWei string    `json:"credit_applied_wei,omitempty"` // この注文に充当したクレジット
+	CreditBalanceWei string    `json:"credit_balance_wei,omitempty"` // 確定後のクレジット残高
+	CreatedAt        time.Time `json:"created_at"`
+	// 支払いを確定できる期限（期限の設定前に作成された注文では nil）
+	ExpiresAt *time.Time `json:"expires_at,omitempty"`
+}
+
+// PaymentQuote は注文を作成せずに見積もった商品の支払い金額
+// 支払い金額は商品ごとの設定値（レート連動ではない）のため、為替レートは含まない
+type PaymentQuote struct {
+	ProductID   string `json:"product_id"`
+	Variant     string `json:"variant,omitempty"`
+	PriceYen    int    `json:"price_yen"`
+	AmountETH   string `json:"amount_eth"`
+	AmountWei   string `json:"amount_wei"`
+	PaymentAddr string `json:"payment_addr"`
+	Network     string `json:"network"`
+}
+
+// PaymentCheck は支払いトランザクションの検証結果
+type PaymentCheck struct {
+	Status  OrderStatus // 検証後の注文ステータス
+	PaidWei *big.Int    // 実際に送金された金額（検証成功時のみ、トークンの場合は Wei 相当）
+	Asset   string      // 支払いに使われたアセット（"ETH" またはトークンのシンボル、検証成功時のみ）
+}
+
+// PaymentCheckItem は支払い検証の個々のチェック結果
+type PaymentCheckItem struct {
+	Name    string `json:"name"`
+	Passed  bool   `json:"passed"`
+	Skipped bool   `json:"skipped,omitempty"` // 前提となるチェックの失敗や未指定で実行しなかった
+	Detail  string `json:"detail,omitempty"`
+}
+
+// PaymentReport はドライランでの支払い検証レポート
+// Status は常に DRY_RUN で、WouldBeStatus が実際に確定した場合のステータス
+type PaymentReport struct {
+	OrderID       string             `json:"order_id,omitempty"`
+	TxHash        string             `json:"tx_hash"`
+	ExpectedWei   string             `json:"expected_wei"`
+	PaidWei       string             `json:"paid_wei,omitempty"`
+	Recipient     string             `json:"recipient,omitempty"`
+	Sender        string             `json:"sender,omitempty"`
+	BlockNumber   uint64             `json:"block_number,omitempty"`
+	Confirmations uint64             `json:"confirmations"`
+	Checks        []PaymentCheckItem `json:"checks"`
+	WouldBeStatus OrderStatus        `json:"would_be_status"`
+	Status        OrderStatus        `json:"status"`
+	// トークン（WETH など）で支払われた場合のシンボル（ETHの場合は空）
+	Asset string `json:"asset,omitempty"`
+}
+
+// SelfTestResult は決済検証パイプラインのセルフテスト結果
+type SelfTestResult struct {
+	TxHash    string      `json:"tx_hash"`
+	From      string      `json:"from"`
+	To        string      `json:"to"`
+	AmountWei string      `json:"amount_wei"`
+	ChainID   string      `json:"chain_id"`
+	Status    OrderStatus `json:"status"`
+	Error     string      `json:"error,omitempty"`
+}
+
+// CollectedBalance は集金用ウォレットの残高
+type CollectedBalance struct {
+	Address    string `json:"address"`
+	BalanceWei string `json:"balance_wei"`
+	BalanceEth string `json:"balance_eth"`
+	Network    string `json:"network"`
 }
 
 // ===============================================
@@ -42,28 +124,48 @@ const (
 	EventItemUpdated      EventType = "ItemUpdated"
 	EventItemCancelled    EventType = "ItemCancelled"
 	EventReceiptConfirmed EventType = "ReceiptConfirmed"
+	EventPriceReduced     EventType = "PriceReduced"
+	EventUnknown          EventType = "Unknown" // ABIに無いイベント（匿名イベントを含む）
 )
 
 // ContractEvent はコントラクトイベントを表す
 type ContractEvent struct {
-	Type        EventType `json:"type"`
-	TxHash      string    `json:"tx_hash"`
-	BlockNo     uint64    `json:"block_number"`
-	ItemId      uint64    `json:"item_id"`
-	TokenId     uint64    `json:"token_id,omitempty"`
-	Title       string    `json:"title,omitempty"`
-	Price       *big.Int  `json:"price,omitempty"`
-	Explanation string    `json:"explanation,omitempty"`
-	ImageUrl    string    `json:"image_url,omitempty"`
-	Uid         string    `json:"uid,omitempty"`
-	Category    string    `json:"category,omitempty"`
-	Seller      string    `json:"seller,omitempty"`
-	Buyer       string    `json:"buyer,omitempty"`
-	BuyerUid    string    `json:"buyer_uid,omitempty"`
-	CreatedAt   uint64    `json:"created_at,omitempty"`
-	UpdatedAt   uint64    `json:"updated_at,omitempty"`
+	Type            EventType `json:"type"`
+	ContractAddress string    `json:"contract_address"` // イベントを発行したコントラクト
+	TxHash          string    `json:"tx_hash"`
+	BlockNo         uint64    `json:"block_number"`
+	BlockHash       string    `json:"block_hash"`
+	BlockTime       uint64    `json:"block_time,omitempty"` // ブロックのUNIX時刻（取得できない場合は0）
+	LogIndex        uint      `json:"log_index"`
+	Removed         bool      `json:"removed,omitempty"`  // リオルグで置き換えられたイベント
+	TraceID         string    `json:"trace_id,omitempty"` // ログ相関用のID（parseLog で採番）
+	ItemId          uint64    `json:"item_id"`
+	TokenId         uint64    `json:"token_id,omitempty"`
+	Title           string    `json:"title,omitempty"`
+	Price           *big.Int  `json:"price,omitempty"`
+	OldPrice        *big.Int  `json:"old_price,omitempty"` // PriceReduced の値下げ前の価格（Price が値下げ後）
+	Explanation     string    `json:"explanation,omitempty"`
+	ImageUrl        string    `json:"image_url,omitempty"`
+	Uid             string    `json:"uid,omitempty"`
+	Category        string    `json:"category,omitempty"`
+	Seller          string    `json:"seller,omitempty"`
+	Buyer           string    `json:"buyer,omitempty"`
+	BuyerUid        string    `json:"buyer_uid,omitempty"`
+	CreatedAt       uint64    `json:"created_at,omitempty"`
+	UpdatedAt       uint64    `json:"updated_at,omitempty"`
+	// EventUnknown の場合のみ設定される生のログ（調査用）
+	RawTopics []string `json:"raw_topics,omitempty"`
+	RawData   string   `json:"raw_data,omitempty"`
 }
 
+// コントラクト上の商品ステータス（ContractItem.Status）
+const (
+	ItemStatusListed    uint8 = 0
+	ItemStatusPurchased uint8 = 1
+	ItemStatusCompleted uint8 = 2
+	ItemStatusCancelled uint8 = 3
+)
+
 // ContractItem はコントラクトの商品情報
 type ContractItem struct {
 	ItemId      uint64   `json:"item_id"`
@@ -83,6 +185,119 @@ type ContractItem struct {
 	Status      uint8    `json:"status"` // 0: Listed, 1: Purchased, 2: Completed, 3: Cancelled
 }
 
+// StatusString は Status を表示用の文字列に変換する（未知の値は "unknown"）
+func (item *ContractItem) StatusString() string {
+	switch item.Status {
+	case ItemStatusListed:
+		return "listed"
+	case ItemStatusPurchased:
+		return "purchased"
+	case ItemStatusCompleted:
+		return "completed"
+	case ItemStatusCancelled:
+		return "cancelled"
+	default:
+		return "unknown"
+	}
+}
+
+// NFTMetadata は商品のNFTのメタデータ
+type NFTMetadata struct {
+	ItemId      uint64 `json:"item_id"`
+	TokenId     uint64 `json:"token_id"`
+	NFTContract string `json:"nft_contract"`
+	TokenURI    string `json:"token_uri"`
+	Name        string `json:"name,omitempty"`
+	Description string `json:"description,omitempty"`
+	Image       string `json:"image,omitempty"` // ipfs:// はゲートウェイのURLに変換済み
+	Error       string `json:"error,omitempty"` // メタデータを取得・解析できなかった理由（部分的な結果の場合のみ）
+}
+
+// EventResyncResult はイベント再通知の結果
+type EventResyncResult struct {
+	Type     EventType `json:"type"`
+	TxHash   string    `json:"tx_hash"`
+	BlockNo  uint64    `json:"block_number"`
+	LogIndex uint      `json:"log_index"`
+	Notified bool      `json:"notified"`
+	Error    string    `json:"error,omitempty"`
+}
+
+// EventReplaySummary はブロック範囲のイベント再通知の結果
+type EventReplaySummary struct {
+	FromBlock uint64            `json:"from_block"`
+	ToBlock   uint64            `json:"to_block"`
+	Notified  map[EventType]int `json:"notified"` // イベント種類ごとの通知に成功した件数
+	Failed    map[EventType]int `json:"failed"`   // イベント種類ごとの通知に失敗した件数
+}
+
+// ContractInfo は接続先のコントラクトとネットワークの情報
+type ContractInfo struct {
+	ContractAddress string `json:"contract_address"`
+	ChainID         uint64 `json:"chain_id"`
+	LatestBlock     uint64 `json:"latest_block"`
+	NFTContract     string `json:"nft_contract"`
+}
+
+// ListenerStatus はイベントリスナーの状態（/api/v1/contract/status のレスポンス）
+type ListenerStatus struct {
+	ContractAddress      string            `json:"contract_address"`
+	EventSource          string            `json:"event_source"` // idle / subscription / polling
+	WSReconnects         uint64            `json:"ws_reconnects"`
+	LastProcessedBlock   uint64            `json:"last_processed_block"`
+	CheckpointBlock      uint64            `json:"checkpoint_block"` // 永続化済みのブロック（起動後に保存していなければ0）
+	PastScanDone         bool              `json:"past_scan_done"`
+	EventsProcessed      map[string]uint64 `json:"events_processed"` // 起動後に通知したイベント数（種類ごと）
+	TotalEventsProcessed uint64            `json:"total_events_processed"`
+	NotifyFailures       uint64            `json:"notify_failures"`
+	LastNotifyError      string            `json:"last_notify_error,omitempty"`
+	LastNotifyErrorAt    int64             `json:"last_notify_error_at,omitempty"`
+	StartedAt            int64             `json:"started_at"`
+	UptimeSeconds        int64             `json:"uptime_seconds"`
+
+	// バックエンド通知のサーキットブレーカー（disabled / closed / open / half_open）
+	NotifierBreaker         string `json:"notifier_breaker"`
+	NotifierBreakerOpenedAt int64  `json:"notifier_breaker_opened_at,omitempty"`
+	NotifierRejected        uint64 `json:"notifier_rejected"` // ブレーカーが開いている間に送信せずに破棄した通知数
+
+	// 現在使っているノードのエンドポイント（API キーを除いた URL）と、HTTP RPC のエンドポイントごとの状態
+	RPCEndpoint  string              `json:"rpc_endpoint,omitempty"`
+	WSEndpoint   string              `json:"ws_endpoint,omitempty"`
+	RPCEndpoints []RPCEndpointHealth `json:"rpc_endpoints,omitempty"`
+
+	// 最後に観測したブロックとその時刻（購読が止まっていないかの確認用）
+	LastBlockSeen   uint64 `json:"last_block_seen"`
+	LastBlockSeenAt int64  `json:"last_block_seen_at,omitempty"`
+}
+
+// RPCEndpointHealth は HTTP RPC のエンドポイントの状態
+type RPCEndpointHealth struct {
+	URL                 string `json:"url"`
+	Active              bool   `json:"active"`
+	ConsecutiveFailures int    `json:"consecutive_failures"`
+	LastError           string `json:"last_error,omitempty"`
+	LastFailureAt       int64  `json:"last_failure_at,omitempty"`
+}
+
+// RelayedBuyItem はリレイヤーが代理送信した buyItem トランザクション
+type RelayedBuyItem struct {
+	ItemId   uint64 `json:"item_id"`
+	TxHash   string `json:"tx_hash"`
+	From     string `json:"from"`
+	ValueWei string `json:"value_wei"`
+	BuyerUid string `json:"buyer_uid"`
+}
+
+// RelayedListItem はリレイヤーが代理送信した listItem トランザクション
+type RelayedListItem struct {
+	ItemId   uint64 `json:"item_id"` // Pending の場合は0
+	TxHash   string `json:"tx_hash"`
+	From     string `json:"from"`
+	PriceWei string `json:"price_wei"`
+	// 待機時間内にマイニングを確認できなかった（ItemListed イベントで後から通知される）
+	Pending bool `json:"pending,omitempty"`
+}
+
 // TxVerification はトランザクション検証結果
 type TxVerification struct {
 	TxHash         string `json:"tx_hash"`
@@ -91,4 +306,54 @@ type TxVerification struct {
 	GasUsed        uint64 `json:"gas_used,omitempty"`
 	Success        bool   `json:"success"`
 	IsContractCall bool   `json:"is_contract_call"`
+	// 手数料の照合用（マイニング済みの場合のみ）
+	TxType            uint8  `json:"tx_type"`                       // 0: legacy, 1: access list, 2: EIP-1559
+	EffectiveGasPrice string `json:"effective_gas_price,omitempty"` // 実際に支払ったガス単価 (Wei)
+	FeeWei            string `json:"fee_wei,omitempty"`             // gasUsed * effectiveGasPrice
+	BlockTimestamp    uint64 `json:"block_timestamp,omitempty"`     // ブロックのUNIX時刻
+	// マーケットプレイスコントラクトの呼び出しの場合のみ（ETHの単純送金では空）
+	MethodName string                 `json:"method_name,omitempty"` // 例: "buyItem", "cancelListing"
+	MethodArgs map[string]interface{} `json:"method_args,omitempty"` // 引数名 → 値（uint256 は10進数文字列）
+	// コントラクト呼び出しの gasUsed が設定された下限（MIN_CONTRACT_CALL_GAS）を下回った
+	// fallback など実質何もしない呼び出しを不正レビューで見つけるための目安であり、検証の成否には影響しない
+	SuspiciousLowGas bool `json:"suspicious_low_gas"`
+	// 失敗したトランザクションのリバート理由（同じブロックで再実行して復元。復元できない場合は空）
+	// Error(string) はその文字列、Panic は "panic: 説明 (コード)"、カスタムエラーは "名前(引数...)"
+	RevertReason string `json:"revert_reason,omitempty"`
+}
+
+// DecodedTxLogs はトランザクションのログを parseLog でデコードした結果（ABI不一致の調査用）
+type DecodedTxLogs struct {
+	TxHash    string           `json:"tx_hash"`
+	Events    []*ContractEvent `json:"events"`    // ABIのイベントとしてデコードできたログ
+	Unmatched []RawLog         `json:"unmatched"` // 監視対象外のコントラクトのログ、またはABIに無いイベント
+}
+
+// RawLog はデコードできなかったログ
+type RawLog struct {
+	Address  string   `json:"address"`
+	LogIndex uint     `json:"log_index"`
+	Topics   []string `json:"topics"`
+	Data     string   `json:"data"`
+}
+
+// ===============================================
+// ネットワーク状況関連のモデル
+// ===============================================
+
+// BlockFee はブロックごとのベースフィー
+type BlockFee struct {
+	BlockNumber uint64
+	BaseFee     *big.Int
+}
+
+// ChainConditions はネットワークの手数料状況のスナップショット
+type ChainConditions struct {
+	BlockNumber          uint64    `json:"block_number"`
+	BaseFeeWei           string    `json:"base_fee_wei"`
+	SuggestedTipWei      string    `json:"suggested_tip_wei"`
+	SuggestedGasPriceWei string    `json:"suggested_gas_price_wei"`
+	BaseFeeTrend         string    `json:"base_fee_trend"` // "rising", "falling", "stable"
+	Congestion           string    `json:"congestion"`     // "low", "normal", "high"
+	UpdatedAt            time.Time `json:"updated_at"`
 }
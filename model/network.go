@@ -0,0 +1,21 @@
+package model
+
+// SepoliaChainID は Sepolia テストネットのチェーンID（このサービスの既定の接続先）
+const SepoliaChainID uint64 = 11155111
+
+// networkNames はチェーンIDごとのネットワーク名
+var networkNames = map[uint64]string{
+	1:              "Mainnet",
+	SepoliaChainID: "Sepolia",
+	17000:          "Holesky",
+	1337:           "Local",
+	31337:          "Local",
+}
+
+// NetworkName はチェーンIDに対応するネットワーク名を返す（未知のIDは "Unknown"）
+func NetworkName(chainID uint64) string {
+	if name, ok := networkNames[chainID]; ok {
+		return name
+	}
+	return "Unknown"
+}
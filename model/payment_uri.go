@@ -0,0 +1,14 @@
+package model
+
+// EthPaymentURI は EIP-681 形式の送金URI（ethereum:<addr>?value=<wei>）を返す
+// ウォレットアプリでQRコードを読み取ると、支払い先と金額が入力された状態で送金画面が開く
+// 支払いの検証はネイティブ送金（tx.Value）のみ対応しているため、ERC-20 の transfer 形式は生成しない
+func EthPaymentURI(addr, amountWei string) string {
+	if addr == "" {
+		return ""
+	}
+	if amountWei == "" {
+		return "ethereum:" + addr
+	}
+	return "ethereum:" + addr + "?value=" + amountWei
+}
@@ -0,0 +1,38 @@
+package model
+
+import (
+	"math/big"
+	"strings"
+)
+
+// weiPerEth は 1 ETH あたりの Wei (10^18)
+var weiPerEth = new(big.Int).Exp(big.NewInt(10), big.NewInt(18), nil)
+
+// WeiToEthString は Wei をETH単位の10進数文字列に変換する（18桁まで正確、末尾の0は省く）
+// nil の場合は空文字を返す
+func WeiToEthString(wei *big.Int) string {
+	if wei == nil {
+		return ""
+	}
+	eth := new(big.Rat).SetFrac(wei, weiPerEth).FloatString(18)
+	if strings.Contains(eth, ".") {
+		eth = strings.TrimRight(eth, "0")
+		eth = strings.TrimSuffix(eth, ".")
+	}
+	return eth
+}
+
+// FormatWeiJPY は Wei を 1 ETH あたり rate 円のレートで円に換算し、整数（四捨五入）の文字列で返す
+// nil の場合は空文字を返す
+func FormatWeiJPY(wei *big.Int, rate float64) string {
+	if wei == nil {
+		return ""
+	}
+	rateRat := new(big.Rat)
+	if rateRat.SetFloat64(rate) == nil {
+		return ""
+	}
+	yen := new(big.Rat).SetFrac(wei, weiPerEth)
+	yen.Mul(yen, rateRat)
+	return yen.FloatString(0)
+}
@@ -0,0 +1,69 @@
+package model
+
+import (
+	"math"
+	"math/big"
+	"testing"
+)
+
+func mustBig(t *testing.T, s string) *big.Int {
+	t.Helper()
+	v, ok := new(big.Int).SetString(s, 10)
+	if !ok {
+		t.Fatalf("invalid integer %q", s)
+	}
+	return v
+}
+
+func TestWeiToEthString(t *testing.T) {
+	tests := []struct {
+		wei  string
+		want string
+	}{
+		{"0", "0"},
+		{"1", "0.000000000000000001"}, // 1 Wei（ダスト）も丸めずに表す
+		{"10", "0.00000000000000001"},
+		{"1000000000000000000", "1"},
+		{"1500000000000000000", "1.5"},
+		{"123456789012345678", "0.123456789012345678"},
+		{"1000000000000000000000000000000", "1000000000000"}, // float64 では桁落ちする大きさ
+		{"1000000000000000000000000000001", "1000000000000.000000000000000001"},
+		{"-2500000000000000000", "-2.5"},
+	}
+	for _, tt := range tests {
+		if got := WeiToEthString(mustBig(t, tt.wei)); got != tt.want {
+			t.Errorf("WeiToEthString(%s) = %q, want %q", tt.wei, got, tt.want)
+		}
+	}
+
+	if got := WeiToEthString(nil); got != "" {
+		t.Errorf("WeiToEthString(nil) = %q, want empty", got)
+	}
+}
+
+func TestFormatWeiJPY(t *testing.T) {
+	tests := []struct {
+		wei  string
+		rate float64
+		want string
+	}{
+		{"1000000000000000000", 500000, "500000"},
+		{"1500000000000000000", 500000, "750000"},
+		{"1", 500000, "0"},             // ダストは0円
+		{"1000000000000000", 500, "1"}, // 0.5 円は切り上げ
+		{"999999999999999", 500, "0"},  // 0.5 円未満は切り捨て
+		{"1000000000000000000000000", 500000, "500000000000"},
+	}
+	for _, tt := range tests {
+		if got := FormatWeiJPY(mustBig(t, tt.wei), tt.rate); got != tt.want {
+			t.Errorf("FormatWeiJPY(%s, %v) = %q, want %q", tt.wei, tt.rate, got, tt.want)
+		}
+	}
+
+	if got := FormatWeiJPY(nil, 500000); got != "" {
+		t.Errorf("FormatWeiJPY(nil) = %q, want empty", got)
+	}
+	if got := FormatWeiJPY(big.NewInt(1), math.Inf(1)); got != "" {
+		t.Errorf("FormatWeiJPY with infinite rate = %q, want empty", got)
+	}
+}
@@ -0,0 +1,115 @@
+package usecase
+
+import (
+	"context"
+	"math/big"
+	"sync"
+	"time"
+
+	"uttc-hack-back-onchain/gateway/chain"
+	"uttc-hack-back-onchain/model"
+)
+
+const (
+	// conditionsCacheTTL はネットワーク状況スナップショットのキャッシュ期間
+	conditionsCacheTTL = 10 * time.Second
+
+	// trendBlockCount はベースフィーの傾向を判定するために参照するブロック数
+	trendBlockCount = 5
+
+	// trendThresholdPercent は傾向を「上昇/下降」と判定するベースフィー変化率（%）
+	trendThresholdPercent = 10
+)
+
+// ChainUsecase はネットワーク状況に関するビジネスロジック
+type ChainUsecase interface {
+	// GetConditions は現在の手数料状況と混雑度を返す
+	GetConditions(ctx context.Context) (*model.ChainConditions, error)
+}
+
+type chainUsecase struct {
+	gateway chain.ChainGateway
+
+	mu       sync.Mutex
+	cached   *model.ChainConditions
+	cachedAt time.Time
+}
+
+func NewChainUsecase(gw chain.ChainGateway) *chainUsecase {
+	return &chainUsecase{gateway: gw}
+}
+
+// GetConditions はキャッシュが有効ならそれを返し、期限切れならノードから再取得する
+func (uc *chainUsecase) GetConditions(ctx context.Context) (*model.ChainConditions, error) {
+	uc.mu.Lock()
+	defer uc.mu.Unlock()
+
+	if uc.cached != nil && time.Since(uc.cachedAt) < conditionsCacheTTL {
+		return uc.cached, nil
+	}
+
+	fees, err := uc.gateway.GetRecentBaseFees(ctx, trendBlockCount)
+	if err != nil {
+		return nil, err
+	}
+	tip, err := uc.gateway.SuggestGasTipCap(ctx)
+	if err != nil {
+		return nil, err
+	}
+	gasPrice, err := uc.gateway.SuggestGasPrice(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	latest := fees[len(fees)-1]
+	trend := baseFeeTrend(fees)
+	conditions := &model.ChainConditions{
+		BlockNumber:          latest.BlockNumber,
+		BaseFeeWei:           latest.BaseFee.String(),
+		SuggestedTipWei:      tip.String(),
+		SuggestedGasPriceWei: gasPrice.String(),
+		BaseFeeTrend:         trend,
+		Congestion:           congestionFromTrend(trend),
+		UpdatedAt:            time.Now(),
+	}
+
+	uc.cached = conditions
+	uc.cachedAt = time.Now()
+	return conditions, nil
+}
+
+// baseFeeTrend は最古と最新のベースフィーの変化率から傾向を判定する
+func baseFeeTrend(fees []model.BlockFee) string {
+	oldest := fees[0].BaseFee
+	latest := fees[len(fees)-1].BaseFee
+	if oldest.Sign() == 0 {
+		return "stable"
+	}
+
+	// 変化率(%) = (latest - oldest) * 100 / oldest
+	change := new(big.Int).Sub(latest, oldest)
+	change.Mul(change, big.NewInt(100))
+	change.Quo(change, oldest)
+
+	switch {
+	case change.Cmp(big.NewInt(trendThresholdPercent)) >= 0:
+		return "rising"
+	case change.Cmp(big.NewInt(-trendThresholdPercent)) <= 0:
+		return "falling"
+	default:
+		return "stable"
+	}
+}
+
+// congestionFromTrend はベースフィーの傾向から混雑度を導出する
+// EIP-1559ではブロックが半分以上埋まるとベースフィーが上昇するため、上昇傾向を混雑とみなす
+func congestionFromTrend(trend string) string {
+	switch trend {
+	case "rising":
+		return "high"
+	case "falling":
+		return "low"
+	default:
+		return "normal"
+	}
+}
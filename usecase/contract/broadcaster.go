@@ -0,0 +1,107 @@
+package usecase
+
+import (
+	"log"
+	"sync"
+
+	"uttc-hack-back-onchain/model"
+)
+
+const (
+	// streamBufferSize はストリーム購読者ごとのチャネルのバッファ数
+	streamBufferSize = 64
+	// streamHistorySize は再接続時の再送用に保持する直近イベント数
+	streamHistorySize = 256
+)
+
+// eventBroadcaster は処理済みイベントを複数のストリーム購読者に配信する
+// 購読者のバッファが埋まった場合は、その購読者を切断して他の購読者やイベント処理を止めない
+// （切断されたクライアントは Last-Event-ID で再接続すれば履歴から再送を受けられる）
+type eventBroadcaster struct {
+	mu          sync.Mutex
+	subscribers map[chan *model.ContractEvent]struct{}
+	history     []*model.ContractEvent // 配信順（過去スキャンと並行するためブロック順とは限らない）
+	closed      bool
+}
+
+func newEventBroadcaster() *eventBroadcaster {
+	return &eventBroadcaster{
+		subscribers: make(map[chan *model.ContractEvent]struct{}),
+	}
+}
+
+// subscribe は購読者を登録し、afterBlock より後のブロックの履歴イベントを先に積んだチャネルを返す
+// 返り値の関数で購読を解除する。チャネルは解除・切断・クローズ時に閉じられる
+func (b *eventBroadcaster) subscribe(afterBlock uint64) (<-chan *model.ContractEvent, func()) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	var replay []*model.ContractEvent
+	if afterBlock > 0 {
+		for _, event := range b.history {
+			if event.BlockNo > afterBlock {
+				replay = append(replay, event)
+			}
+		}
+	}
+
+	ch := make(chan *model.ContractEvent, streamBufferSize+len(replay))
+	for _, event := range replay {
+		ch <- event
+	}
+
+	if b.closed {
+		close(ch)
+		return ch, func() {}
+	}
+	b.subscribers[ch] = struct{}{}
+
+	return ch, func() {
+		b.mu.Lock()
+		defer b.mu.Unlock()
+		b.removeLocked(ch)
+	}
+}
+
+// publish はイベントを履歴に追加し、全購読者に配信する
+func (b *eventBroadcaster) publish(event *model.ContractEvent) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if b.closed {
+		return
+	}
+
+	b.history = append(b.history, event)
+	if len(b.history) > streamHistorySize {
+		b.history = b.history[len(b.history)-streamHistorySize:]
+	}
+
+	for ch := range b.subscribers {
+		select {
+		case ch <- event:
+		default:
+			log.Printf("WARNING: Event stream subscriber is too slow, disconnecting")
+			b.removeLocked(ch)
+		}
+	}
+}
+
+// close は全購読者のチャネルを閉じ、以降の購読を受け付けない
+func (b *eventBroadcaster) close() {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	b.closed = true
+	for ch := range b.subscribers {
+		b.removeLocked(ch)
+	}
+}
+
+func (b *eventBroadcaster) removeLocked(ch chan *model.ContractEvent) {
+	if _, ok := b.subscribers[ch]; !ok {
+		return
+	}
+	delete(b.subscribers, ch)
+	close(ch)
+}
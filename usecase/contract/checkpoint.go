@@ -0,0 +1,91 @@
+package usecase
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+// Checkpoint は処理済みブロック番号を永続化するストア
+// 再起動時に過去イベントの再通知を防ぐために使用する
+type Checkpoint interface {
+	// LoadLastBlock は最後に処理したブロック番号を返す（未保存の場合は0）
+	LoadLastBlock() (uint64, error)
+
+	// SaveLastBlock は処理済みブロック番号を保存する
+	SaveLastBlock(block uint64) error
+}
+
+// FileCheckpoint はブロック番号をファイルに保存するデフォルト実装
+type FileCheckpoint struct {
+	mu   sync.Mutex
+	path string
+}
+
+// NewFileCheckpoint はファイルベースのチェックポイントを作成
+func NewFileCheckpoint(path string) *FileCheckpoint {
+	return &FileCheckpoint{path: path}
+}
+
+// LoadLastBlock はファイルからブロック番号を読み込む
+func (c *FileCheckpoint) LoadLastBlock() (uint64, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	data, err := os.ReadFile(c.path)
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return 0, nil
+		}
+		return 0, fmt.Errorf("failed to read checkpoint: %w", err)
+	}
+
+	block, err := strconv.ParseUint(strings.TrimSpace(string(data)), 10, 64)
+	if err != nil {
+		return 0, fmt.Errorf("invalid checkpoint value: %w", err)
+	}
+	return block, nil
+}
+
+// SaveLastBlock はブロック番号をファイルに書き込む
+// 一時ファイルに書いてからリネームし、書き込み途中のクラッシュでファイルが壊れないようにする
+func (c *FileCheckpoint) SaveLastBlock(block uint64) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	tmpPath := c.path + ".tmp"
+	if err := os.WriteFile(tmpPath, []byte(strconv.FormatUint(block, 10)), 0o644); err != nil {
+		return fmt.Errorf("failed to write checkpoint: %w", err)
+	}
+	if err := os.Rename(tmpPath, c.path); err != nil {
+		return fmt.Errorf("failed to replace checkpoint: %w", err)
+	}
+	return nil
+}
+
+// MemoryCheckpoint はメモリ上にブロック番号を保持する実装（テストや永続化不要な環境向け）
+type MemoryCheckpoint struct {
+	mu    sync.Mutex
+	block uint64
+}
+
+// NewMemoryCheckpoint はメモリ上のチェックポイントを作成
+func NewMemoryCheckpoint() *MemoryCheckpoint {
+	return &MemoryCheckpoint{}
+}
+
+func (c *MemoryCheckpoint) LoadLastBlock() (uint64, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.block, nil
+}
+
+func (c *MemoryCheckpoint) SaveLastBlock(block uint64) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.block = block
+	return nil
+}
@@ -0,0 +1,84 @@
+package usecase
+
+import (
+	"context"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"uttc-hack-back-onchain/internal/notifier"
+	"uttc-hack-back-onchain/model"
+)
+
+func TestFileCheckpoint(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "checkpoint")
+	c := NewFileCheckpoint(path)
+
+	// 未保存の場合は0
+	if block, err := c.LoadLastBlock(); err != nil || block != 0 {
+		t.Fatalf("LoadLastBlock = %d, %v; want 0, nil", block, err)
+	}
+
+	if err := c.SaveLastBlock(12345); err != nil {
+		t.Fatalf("SaveLastBlock: %v", err)
+	}
+	// 別のインスタンス（再起動後）からも読めること
+	if block, err := NewFileCheckpoint(path).LoadLastBlock(); err != nil || block != 12345 {
+		t.Fatalf("LoadLastBlock = %d, %v; want 12345, nil", block, err)
+	}
+	if _, err := os.Stat(path + ".tmp"); !os.IsNotExist(err) {
+		t.Errorf("temporary file was left behind: %v", err)
+	}
+}
+
+func TestFileCheckpointRejectsCorruptFile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "checkpoint")
+	if err := os.WriteFile(path, []byte("not a block"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := NewFileCheckpoint(path).LoadLastBlock(); err == nil {
+		t.Fatal("LoadLastBlock succeeded on a corrupt file")
+	}
+}
+
+func TestEventListenerResumesFromCheckpoint(t *testing.T) {
+	srv := httptest.NewServer(&notifyCounter{})
+	defer srv.Close()
+
+	checkpoint := NewMemoryCheckpoint()
+	checkpoint.SaveLastBlock(41)
+
+	gw := &overlapGateway{
+		scan:     make(chan *model.ContractEvent),
+		realtime: make(chan *model.ContractEvent),
+	}
+	n := notifier.NewBackendNotifier(srv.URL, time.Second, notifier.RetryConfig{MaxAttempts: 1}, "", nil, notifier.BreakerConfig{})
+	uc := NewContractUsecase(gw, n, checkpoint, nil, nil, DefaultNotifyEndpoints())
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	if err := uc.StartEventListener(ctx); err != nil {
+		t.Fatalf("StartEventListener: %v", err)
+	}
+
+	gw.scan <- cancelledEvent(42, 0)
+	gw.scan <- cancelledEvent(43, 0)
+	if from := gw.scanFrom.Load(); from != 42 {
+		t.Errorf("scan started from block %d, want 42", from)
+	}
+	close(gw.scan)
+
+	lastBlock := func(want uint64) func() bool {
+		return func() bool {
+			block, _ := checkpoint.LoadLastBlock()
+			return block == want
+		}
+	}
+	waitFor(t, "checkpoint to reach the scanned block", lastBlock(43))
+
+	// スキャン完了後はリアルタイムのイベントを通知するたびに進む
+	gw.realtime <- cancelledEvent(44, 0)
+	waitFor(t, "checkpoint to reach the realtime block", lastBlock(44))
+}
@@ -0,0 +1,114 @@
+package usecase
+
+import (
+	"container/list"
+	"expvar"
+	"fmt"
+	"log"
+	"sync"
+	"time"
+
+	"uttc-hack-back-onchain/model"
+)
+
+const (
+	defaultDedupTTL     = time.Hour
+	defaultDedupMaxSize = 10000
+
+	// dedupReorgWindow は重複排除エントリを保護するブロック数
+	// 最新ブロックからこの範囲内のエントリは TTL やサイズ上限を超えても削除しない
+	dedupReorgWindow uint64 = 12
+)
+
+// 重複排除セットのメトリクス（/debug/vars で公開）
+var (
+	dedupSizeMetric      = expvar.NewInt("event_dedup_size")
+	dedupEvictionsMetric = expvar.NewInt("event_dedup_evictions")
+)
+
+// eventDeduper は通知済みイベントを記録し、同じイベントの重複通知を防ぐ
+// エントリは TTL と最大サイズで古い順に削除されるが、リオルグ範囲内のエントリは保護される
+type eventDeduper struct {
+	mu          sync.Mutex
+	ttl         time.Duration
+	maxSize     int
+	reorgWindow uint64
+	entries     map[string]*list.Element
+	order       *list.List // 記録順（先頭が最も古い）
+	latestBlock uint64
+}
+
+type dedupEntry struct {
+	key    string
+	block  uint64
+	seenAt time.Time
+}
+
+func newEventDeduper(ttl time.Duration, maxSize int, reorgWindow uint64) *eventDeduper {
+	return &eventDeduper{
+		ttl:         ttl,
+		maxSize:     maxSize,
+		reorgWindow: reorgWindow,
+		entries:     make(map[string]*list.Element),
+		order:       list.New(),
+	}
+}
+
+// dedupKey はイベントを一意に識別するキーを返す
+// ブロックハッシュを含めることで、リオルグ後に別ブロックへ取り込まれたログは別イベントとして扱う
+func dedupKey(event *model.ContractEvent) string {
+	return fmt.Sprintf("%s:%s:%d", event.BlockHash, event.TxHash, event.LogIndex)
+}
+
+// Seen はイベントが通知済みかどうかを返す
+func (d *eventDeduper) Seen(event *model.ContractEvent) bool {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	_, ok := d.entries[dedupKey(event)]
+	return ok
+}
+
+// MarkSeen はイベントを通知済みとして記録し、必要に応じて古いエントリを削除する
+func (d *eventDeduper) MarkSeen(event *model.ContractEvent) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	key := dedupKey(event)
+	if _, ok := d.entries[key]; ok {
+		return
+	}
+
+	d.entries[key] = d.order.PushBack(&dedupEntry{key: key, block: event.BlockNo, seenAt: time.Now()})
+	if event.BlockNo > d.latestBlock {
+		d.latestBlock = event.BlockNo
+	}
+
+	d.evictLocked()
+	dedupSizeMetric.Set(int64(len(d.entries)))
+}
+
+// evictLocked は TTL 切れ・サイズ超過のエントリを古い順に削除する
+// リオルグ範囲内のエントリに達した時点で停止し、一時的なサイズ超過を許容する
+func (d *eventDeduper) evictLocked() {
+	now := time.Now()
+	for front := d.order.Front(); front != nil; front = d.order.Front() {
+		entry := front.Value.(*dedupEntry)
+
+		expired := now.Sub(entry.seenAt) > d.ttl
+		overflow := len(d.entries) > d.maxSize
+		if !expired && !overflow {
+			return
+		}
+		if entry.block+d.reorgWindow >= d.latestBlock {
+			if overflow {
+				log.Printf("WARNING: Event dedup set exceeds max size (%d > %d) within reorg window", len(d.entries), d.maxSize)
+			}
+			return
+		}
+
+		d.order.Remove(front)
+		delete(d.entries, entry.key)
+		dedupEvictionsMetric.Add(1)
+	}
+}
@@ -0,0 +1,94 @@
+package usecase
+
+import (
+	"fmt"
+	"log"
+	"os"
+	"strings"
+
+	"uttc-hack-back-onchain/model"
+)
+
+// knownEventTypes は ABI に定義されたイベントの種類（EventUnknown を除く）
+var knownEventTypes = []model.EventType{
+	model.EventItemListed,
+	model.EventItemPurchased,
+	model.EventItemUpdated,
+	model.EventItemCancelled,
+	model.EventReceiptConfirmed,
+	model.EventPriceReduced,
+}
+
+// notifyPathEnv はイベントの種類ごとに通知先パスを上書きする環境変数
+var notifyPathEnv = map[model.EventType]string{
+	model.EventItemListed:       "NOTIFY_PATH_ITEM_LISTED",
+	model.EventItemPurchased:    "NOTIFY_PATH_ITEM_PURCHASED",
+	model.EventItemUpdated:      "NOTIFY_PATH_ITEM_UPDATED",
+	model.EventItemCancelled:    "NOTIFY_PATH_ITEM_CANCELLED",
+	model.EventReceiptConfirmed: "NOTIFY_PATH_RECEIPT_CONFIRMED",
+	model.EventPriceReduced:     "NOTIFY_PATH_PRICE_REDUCED",
+}
+
+// NotifyEndpoints はバックエンドへの通知先パス（ベースURLからの相対パス）
+// バックエンドのルーティングに合わせて差し替えられるよう、handleEvent に直接書かずに注入する
+type NotifyEndpoints struct {
+	// Events はイベントの種類ごとの通知先
+	Events map[model.EventType]string
+	// UnknownEvent は ABI に無いイベントの転送先（DEBUG_FORWARD_UNKNOWN_EVENTS 有効時のみ）
+	UnknownEvent string
+	// ItemSynced は商品状態の再取得結果の送信先（ITEM_SYNC_EVENT_TYPES 設定時のみ）
+	ItemSynced string
+}
+
+// DefaultNotifyEndpoints は uttc-hackathon-backend の /api/v1/blockchain/* を返す
+func DefaultNotifyEndpoints() NotifyEndpoints {
+	return NotifyEndpoints{
+		Events: map[model.EventType]string{
+			model.EventItemListed:       "/api/v1/blockchain/item-listed",
+			model.EventItemPurchased:    "/api/v1/blockchain/item-purchased",
+			model.EventItemUpdated:      "/api/v1/blockchain/item-updated",
+			model.EventItemCancelled:    "/api/v1/blockchain/item-cancelled",
+			model.EventReceiptConfirmed: "/api/v1/blockchain/receipt-confirmed",
+			model.EventPriceReduced:     "/api/v1/blockchain/price-reduced",
+		},
+		UnknownEvent: "/api/v1/blockchain/unknown-event",
+		ItemSynced:   "/api/v1/blockchain/item-synced",
+	}
+}
+
+// NotifyEndpointsFromEnv はデフォルトの通知先を NOTIFY_PATH_* で個別に上書きしたものを返す
+func NotifyEndpointsFromEnv() NotifyEndpoints {
+	endpoints := DefaultNotifyEndpoints()
+	for eventType, key := range notifyPathEnv {
+		if v := os.Getenv(key); v != "" {
+			endpoints.Events[eventType] = v
+			log.Printf("Notify path for %s overridden: %s", eventType, v)
+		}
+	}
+	if v := os.Getenv("NOTIFY_PATH_UNKNOWN_EVENT"); v != "" {
+		endpoints.UnknownEvent = v
+	}
+	if v := os.Getenv("NOTIFY_PATH_ITEM_SYNCED"); v != "" {
+		endpoints.ItemSynced = v
+	}
+	return endpoints
+}
+
+// Validate はすべてのイベントの種類に通知先があり、各パスが "/" で始まることを確認する
+func (e NotifyEndpoints) Validate() error {
+	for _, eventType := range knownEventTypes {
+		path, ok := e.Events[eventType]
+		if !ok || path == "" {
+			return fmt.Errorf("no notify path for event type %s", eventType)
+		}
+		if !strings.HasPrefix(path, "/") {
+			return fmt.Errorf("notify path for %s must start with '/': %q", eventType, path)
+		}
+	}
+	for name, path := range map[string]string{"unknown event": e.UnknownEvent, "item synced": e.ItemSynced} {
+		if !strings.HasPrefix(path, "/") {
+			return fmt.Errorf("notify path for %s must start with '/': %q", name, path)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,104 @@
+package usecase
+
+import (
+	"sync"
+
+	"uttc-hack-back-onchain/model"
+)
+
+const (
+	// defaultEventWorkers はイベントを並行して通知するワーカー数のデフォルト
+	defaultEventWorkers = 4
+	// eventQueueSize はワーカーごとの待ち行列の長さ（満杯の場合は投入側が待つ）
+	eventQueueSize = 100
+)
+
+type eventTask struct {
+	event *model.ContractEvent
+	done  *sync.WaitGroup // nil 可。処理完了を待つ呼び出し元用
+}
+
+// eventWorkerPool はイベントを itemId ごとにワーカーへ振り分けて並行処理する
+// 同じ商品のイベントは常に同じワーカーが受け取るため、商品ごとの処理順は保たれる
+type eventWorkerPool struct {
+	shards  []chan eventTask
+	workers sync.WaitGroup
+
+	mu       sync.Mutex
+	inflight map[uint64]int // ブロック番号ごとの処理中イベント数
+}
+
+func newEventWorkerPool(size int) *eventWorkerPool {
+	shards := make([]chan eventTask, size)
+	for i := range shards {
+		shards[i] = make(chan eventTask, eventQueueSize)
+	}
+	return &eventWorkerPool{
+		shards:   shards,
+		inflight: make(map[uint64]int),
+	}
+}
+
+// start はワーカーを起動する。handle は1件ごとに、afterDone は処理中の記録を外した後に呼ばれる
+func (p *eventWorkerPool) start(handle func(event *model.ContractEvent), afterDone func()) {
+	for _, shard := range p.shards {
+		p.workers.Add(1)
+		go func(shard <-chan eventTask) {
+			defer p.workers.Done()
+			for task := range shard {
+				handle(task.event)
+				p.finish(task.event.BlockNo)
+				afterDone()
+				if task.done != nil {
+					task.done.Done()
+				}
+			}
+		}(shard)
+	}
+}
+
+// dispatch はイベントを担当ワーカーの待ち行列に入れる
+func (p *eventWorkerPool) dispatch(event *model.ContractEvent, done *sync.WaitGroup) {
+	p.mu.Lock()
+	p.inflight[event.BlockNo]++
+	p.mu.Unlock()
+
+	if done != nil {
+		done.Add(1)
+	}
+	p.shards[event.ItemId%uint64(len(p.shards))] <- eventTask{event: event, done: done}
+}
+
+func (p *eventWorkerPool) finish(block uint64) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	p.inflight[block]--
+	if p.inflight[block] <= 0 {
+		delete(p.inflight, block)
+	}
+}
+
+// lowestInflightBlock は処理中のイベントのうち最も古いブロック番号を返す
+func (p *eventWorkerPool) lowestInflightBlock() (uint64, bool) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	var lowest uint64
+	found := false
+	for block := range p.inflight {
+		if !found || block < lowest {
+			lowest, found = block, true
+		}
+	}
+	return lowest, found
+}
+
+// close は待ち行列を閉じ、残ったイベントを処理し終えるまで待つ
+// 呼び出し後に dispatch してはならない
+func (p *eventWorkerPool) close() {
+	for _, shard := range p.shards {
+		close(shard)
+	}
+	p.workers.Wait()
+}
@@ -0,0 +1,74 @@
+package usecase
+
+import (
+	"math/rand"
+	"sync"
+	"testing"
+	"time"
+
+	"uttc-hack-back-onchain/model"
+)
+
+func TestEventWorkerPoolKeepsPerItemOrder(t *testing.T) {
+	const (
+		items         = 7 // ワーカー数より多くして、同じワーカーに複数の商品が割り当たるようにする
+		eventsPerItem = 50
+	)
+
+	pool := newEventWorkerPool(3)
+	var mu sync.Mutex
+	handled := make(map[uint64][]uint)
+	pool.start(func(event *model.ContractEvent) {
+		// 処理時間をばらつかせて、ワーカー間の追い越しが起きやすくする
+		time.Sleep(time.Duration(rand.Intn(50)) * time.Microsecond)
+		mu.Lock()
+		handled[event.ItemId] = append(handled[event.ItemId], event.LogIndex)
+		mu.Unlock()
+	}, func() {})
+
+	// 商品ごとの連番（LogIndex）を、商品をまたいで交互に投入する
+	var done sync.WaitGroup
+	for seq := 0; seq < eventsPerItem; seq++ {
+		for item := uint64(1); item <= items; item++ {
+			pool.dispatch(&model.ContractEvent{ItemId: item, BlockNo: uint64(seq + 1), LogIndex: uint(seq)}, &done)
+		}
+	}
+	done.Wait()
+	pool.close()
+
+	for item := uint64(1); item <= items; item++ {
+		got := handled[item]
+		if len(got) != eventsPerItem {
+			t.Fatalf("item %d: handled %d events, want %d", item, len(got), eventsPerItem)
+		}
+		for i, seq := range got {
+			if seq != uint(i) {
+				t.Fatalf("item %d: handled order %v, want ascending", item, got)
+			}
+		}
+	}
+}
+
+func TestEventWorkerPoolTracksInflightBlocks(t *testing.T) {
+	pool := newEventWorkerPool(2)
+	release := make(chan struct{})
+	pool.start(func(event *model.ContractEvent) {
+		<-release
+	}, func() {})
+
+	var done sync.WaitGroup
+	pool.dispatch(&model.ContractEvent{ItemId: 1, BlockNo: 20}, &done)
+	pool.dispatch(&model.ContractEvent{ItemId: 2, BlockNo: 10}, &done)
+
+	if lowest, ok := pool.lowestInflightBlock(); !ok || lowest != 10 {
+		t.Errorf("lowestInflightBlock = %d, %v; want 10, true", lowest, ok)
+	}
+
+	close(release)
+	done.Wait()
+	pool.close()
+
+	if lowest, ok := pool.lowestInflightBlock(); ok {
+		t.Errorf("lowestInflightBlock = %d after all events finished, want none", lowest)
+	}
+}
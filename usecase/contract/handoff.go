@@ -0,0 +1,67 @@
+package usecase
+
+import (
+	"log"
+	"sync"
+
+	"uttc-hack-back-onchain/model"
+)
+
+// defaultScanHandoffBuffer は過去スキャン中に保留するリアルタイムイベント数の上限のデフォルト
+const defaultScanHandoffBuffer = 10000
+
+// scanHandoff は過去スキャン中に受信したリアルタイムイベントを保留し、スキャン完了後にまとめて流す
+// スキャン末尾と同じブロックのイベントがリアルタイム側から先に届くと、同じ商品のイベントが
+// ブロック順と逆に通知されることがあるため。スキャン済みのイベントは processEvent の重複排除で除かれる
+type scanHandoff struct {
+	mu         sync.Mutex
+	released   bool
+	maxPending int
+	pending    []*model.ContractEvent
+	// overflowed は上限超過の警告を出力済みか（スキャン1回につき1度だけ出す）
+	overflowed bool
+}
+
+func newScanHandoff(maxPending int) *scanHandoff {
+	return &scanHandoff{maxPending: maxPending}
+}
+
+// submit はスキャン完了前ならイベントを保留し、完了後ならそのまま dispatch に渡す
+// 保留数が上限に達した場合は、メモリを使い続けないよう保留せずに流す（順序は保証されないが重複排除は効く）
+func (h *scanHandoff) submit(event *model.ContractEvent, dispatch func(*model.ContractEvent)) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	if h.released {
+		dispatch(event)
+		return
+	}
+	if len(h.pending) >= h.maxPending {
+		if !h.overflowed {
+			h.overflowed = true
+			log.Printf("WARNING: Realtime events held during past scan exceed %d, dispatching without waiting", h.maxPending)
+		}
+		dispatch(event)
+		return
+	}
+	h.pending = append(h.pending, event)
+}
+
+// release は保留していたイベントを受信順に dispatch に渡し、以降のイベントを素通しにする
+// 保留分を流し終えるまで submit を待たせるため、新しいイベントが保留分を追い越すことはない
+func (h *scanHandoff) release(dispatch func(*model.ContractEvent)) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	if h.released {
+		return
+	}
+	if len(h.pending) > 0 {
+		log.Printf("Releasing %d realtime events held during past scan", len(h.pending))
+	}
+	for _, event := range h.pending {
+		dispatch(event)
+	}
+	h.pending = nil
+	h.released = true
+}
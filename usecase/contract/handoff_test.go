@@ -0,0 +1,189 @@
+package usecase
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"uttc-hack-back-onchain/gateway/contract"
+	"uttc-hack-back-onchain/internal/notifier"
+	"uttc-hack-back-onchain/logger"
+	"uttc-hack-back-onchain/model"
+)
+
+// overlapGateway は過去スキャンとリアルタイム受信のチャネルをテストから操作できるゲートウェイ
+// 使わないメソッドは埋め込んだ nil インターフェースに任せる（呼ばれたらパニックする）
+type overlapGateway struct {
+	contract.ContractGateway
+	scan     chan *model.ContractEvent
+	realtime chan *model.ContractEvent
+	// scanFrom は ScanPastEvents に渡された開始ブロック
+	scanFrom atomic.Uint64
+}
+
+func (g *overlapGateway) GetContractAddress() string {
+	return "0x00000000000000000000000000000000000000aa"
+}
+
+func (g *overlapGateway) ScanPastEvents(ctx context.Context, fromBlock uint64, toBlock *uint64, eventTypes []model.EventType) (<-chan *model.ContractEvent, error) {
+	g.scanFrom.Store(fromBlock)
+	return g.scan, nil
+}
+
+func (g *overlapGateway) SubscribeEvents(ctx context.Context) (<-chan *model.ContractEvent, error) {
+	out := make(chan *model.ContractEvent)
+	go func() {
+		defer close(out)
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case event := <-g.realtime:
+				select {
+				case out <- event:
+				case <-ctx.Done():
+					return
+				}
+			}
+		}
+	}()
+	return out, nil
+}
+
+// notifyCounter はバックエンドに届いた通知を tx_hash ごとに受信順で記録する
+type notifyCounter struct {
+	mu       sync.Mutex
+	received []string
+}
+
+func (c *notifyCounter) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	var payload struct {
+		TxHash string `json:"tx_hash"`
+	}
+	json.NewDecoder(r.Body).Decode(&payload)
+	c.mu.Lock()
+	c.received = append(c.received, payload.TxHash)
+	c.mu.Unlock()
+}
+
+func (c *notifyCounter) snapshot() []string {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return append([]string(nil), c.received...)
+}
+
+func cancelledEvent(block uint64, logIndex uint) *model.ContractEvent {
+	return &model.ContractEvent{
+		Type:      model.EventItemCancelled,
+		ItemId:    1,
+		Seller:    "0x00000000000000000000000000000000000000b1",
+		BlockNo:   block,
+		BlockHash: fmt.Sprintf("0xblock%d", block),
+		TxHash:    fmt.Sprintf("0xtx%d-%d", block, logIndex),
+		LogIndex:  logIndex,
+	}
+}
+
+// waitFor は cond が満たされるまで待つ
+func waitFor(t *testing.T, what string, cond func() bool) {
+	t.Helper()
+	deadline := time.Now().Add(5 * time.Second)
+	for !cond() {
+		if time.Now().After(deadline) {
+			t.Fatalf("timed out waiting for %s", what)
+		}
+		time.Sleep(time.Millisecond)
+	}
+}
+
+func TestScanRealtimeOverlapHandlesEachEventOnce(t *testing.T) {
+	backend := &notifyCounter{}
+	srv := httptest.NewServer(backend)
+	defer srv.Close()
+
+	gw := &overlapGateway{
+		scan:     make(chan *model.ContractEvent),
+		realtime: make(chan *model.ContractEvent),
+	}
+	n := notifier.NewBackendNotifier(srv.URL, time.Second, notifier.RetryConfig{MaxAttempts: 1}, "", nil, notifier.BreakerConfig{})
+	uc := NewContractUsecase(gw, n, NewMemoryCheckpoint(), nil, nil, DefaultNotifyEndpoints())
+
+	ctx, cancel := context.WithCancel(context.Background())
+	if err := uc.StartEventListener(ctx); err != nil {
+		t.Fatalf("StartEventListener: %v", err)
+	}
+
+	// スキャン末尾のブロック 100 のイベントが、スキャン中にリアルタイム側からも届く
+	overlap := cancelledEvent(100, 0)
+	newer := cancelledEvent(101, 0)
+	gw.realtime <- overlap
+	gw.realtime <- newer
+	waitFor(t, "realtime events to be held", func() bool {
+		uc.handoff.mu.Lock()
+		defer uc.handoff.mu.Unlock()
+		return len(uc.handoff.pending) == 2
+	})
+
+	gw.scan <- cancelledEvent(99, 0)
+	gw.scan <- overlap
+	close(gw.scan)
+
+	// 解放後に同じイベントがリアルタイム側からもう一度届いても通知しない
+	waitFor(t, "held events to be released", func() bool {
+		uc.handoff.mu.Lock()
+		defer uc.handoff.mu.Unlock()
+		return uc.handoff.released
+	})
+	gw.realtime <- overlap
+	// 同じ商品のイベントは同じワーカーが順に処理するため、後続のイベントの通知で重複分の処理済みがわかる
+	gw.realtime <- cancelledEvent(102, 0)
+	waitFor(t, "last event to be notified", func() bool {
+		got := backend.snapshot()
+		return len(got) > 0 && got[len(got)-1] == "0xtx102-0"
+	})
+
+	cancel()
+	shutdownCtx, stop := context.WithTimeout(context.Background(), 5*time.Second)
+	defer stop()
+	if err := uc.Shutdown(shutdownCtx); err != nil {
+		t.Fatalf("Shutdown: %v", err)
+	}
+
+	want := []string{"0xtx99-0", "0xtx100-0", "0xtx101-0", "0xtx102-0"}
+	got := backend.snapshot()
+	if fmt.Sprint(got) != fmt.Sprint(want) {
+		t.Errorf("notified %v, want %v (each event once, in block order)", got, want)
+	}
+}
+
+func TestScanHandoffOverflowDispatchesImmediately(t *testing.T) {
+	h := newScanHandoff(1)
+	var dispatched []string
+	dispatch := func(event *model.ContractEvent) { dispatched = append(dispatched, event.TxHash) }
+
+	logs, restore := logger.Capture()
+	defer restore()
+	for block := uint64(100); block < 103; block++ {
+		h.submit(cancelledEvent(block, 0), dispatch)
+	}
+
+	// 上限を超えた分は保留せずにすぐ流し、警告は1度だけ出す
+	if want := []string{"0xtx101-0", "0xtx102-0"}; fmt.Sprint(dispatched) != fmt.Sprint(want) {
+		t.Errorf("dispatched before release %v, want %v", dispatched, want)
+	}
+	if n := strings.Count(logs.String(), "dispatching without waiting"); n != 1 {
+		t.Errorf("overflow warning logged %d times, want 1: %s", n, logs.String())
+	}
+
+	h.release(dispatch)
+	if want := []string{"0xtx101-0", "0xtx102-0", "0xtx100-0"}; fmt.Sprint(dispatched) != fmt.Sprint(want) {
+		t.Errorf("dispatched %v, want %v", dispatched, want)
+	}
+}
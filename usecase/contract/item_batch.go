@@ -0,0 +1,93 @@
+package usecase
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+
+	"uttc-hack-back-onchain/model"
+)
+
+// defaultItemFetchConcurrency は GetItems が同時に発行する getItem 呼び出し数のデフォルト
+// Infura のレート制限に掛からない程度に抑える
+const defaultItemFetchConcurrency = 8
+
+// ItemsError は GetItems で取得に失敗した商品ごとのエラー
+// errors.Is で ErrCallTimeout などの個々のエラーを判別できる
+type ItemsError struct {
+	Errors map[uint64]error
+}
+
+func (e *ItemsError) Error() string {
+	ids := make([]uint64, 0, len(e.Errors))
+	for id := range e.Errors {
+		ids = append(ids, id)
+	}
+	sort.Slice(ids, func(i, j int) bool { return ids[i] < ids[j] })
+
+	msgs := make([]string, 0, len(ids))
+	for _, id := range ids {
+		msgs = append(msgs, fmt.Sprintf("item %d: %v", id, e.Errors[id]))
+	}
+	return fmt.Sprintf("failed to get %d items: %s", len(ids), strings.Join(msgs, "; "))
+}
+
+func (e *ItemsError) Unwrap() []error {
+	errs := make([]error, 0, len(e.Errors))
+	for _, err := range e.Errors {
+		errs = append(errs, err)
+	}
+	return errs
+}
+
+// GetItems は複数の商品を GetItem（キャッシュ経由）で並行して取得する
+// 結果は ids と同じ順で、取得に失敗した商品は nil になり、そのエラーを *ItemsError にまとめて返す
+func (uc *contractUsecase) GetItems(ctx context.Context, ids []uint64) ([]*model.ContractItem, error) {
+	items := make([]*model.ContractItem, len(ids))
+	if len(ids) == 0 {
+		return items, nil
+	}
+
+	var (
+		mu     sync.Mutex
+		failed map[uint64]error
+	)
+	concurrency := uc.itemFetchConcurrency
+	if concurrency > len(ids) {
+		concurrency = len(ids)
+	}
+
+	indexes := make(chan int)
+	var workers sync.WaitGroup
+	for range concurrency {
+		workers.Add(1)
+		go func() {
+			defer workers.Done()
+			for i := range indexes {
+				item, err := uc.GetItem(ctx, ids[i])
+				if err != nil {
+					mu.Lock()
+					if failed == nil {
+						failed = make(map[uint64]error)
+					}
+					failed[ids[i]] = err
+					mu.Unlock()
+					continue
+				}
+				items[i] = item
+			}
+		}()
+	}
+	for i := range ids {
+		indexes <- i
+	}
+	close(indexes)
+	workers.Wait()
+
+	if failed != nil {
+		return items, &ItemsError{Errors: failed}
+	}
+	return items, nil
+}
@@ -0,0 +1,79 @@
+package usecase
+
+import (
+	"context"
+	"strconv"
+	"sync"
+	"time"
+
+	"golang.org/x/sync/singleflight"
+
+	"uttc-hack-back-onchain/model"
+)
+
+// defaultItemCacheTTL は GetItem の結果をキャッシュする期間のデフォルト
+const defaultItemCacheTTL = 10 * time.Second
+
+type itemCacheEntry struct {
+	item      *model.ContractItem
+	expiresAt time.Time
+}
+
+// itemCache は GetItem の結果を itemId ごとに短時間キャッシュする
+// 同じ商品への同時リクエストは singleflight で1回の eth_call にまとめる。
+// キャッシュした *model.ContractItem は呼び出し元間で共有されるため変更しないこと
+type itemCache struct {
+	ttl   time.Duration
+	group singleflight.Group
+
+	mu      sync.Mutex
+	entries map[uint64]itemCacheEntry
+	// 無効化の回数。取得中に無効化された結果を保存しないために使う
+	versions map[uint64]uint64
+}
+
+func newItemCache(ttl time.Duration) *itemCache {
+	return &itemCache{
+		ttl:      ttl,
+		entries:  make(map[uint64]itemCacheEntry),
+		versions: make(map[uint64]uint64),
+	}
+}
+
+// get はキャッシュされた商品を返し、無い場合は fetch で取得してキャッシュする
+func (c *itemCache) get(ctx context.Context, itemId uint64, fetch func(ctx context.Context, itemId uint64) (*model.ContractItem, error)) (*model.ContractItem, error) {
+	c.mu.Lock()
+	if entry, ok := c.entries[itemId]; ok && time.Now().Before(entry.expiresAt) {
+		c.mu.Unlock()
+		return entry.item, nil
+	}
+	version := c.versions[itemId]
+	c.mu.Unlock()
+
+	v, err, _ := c.group.Do(strconv.FormatUint(itemId, 10), func() (interface{}, error) {
+		item, err := fetch(ctx, itemId)
+		if err != nil {
+			return nil, err
+		}
+
+		c.mu.Lock()
+		if c.versions[itemId] == version {
+			c.entries[itemId] = itemCacheEntry{item: item, expiresAt: time.Now().Add(c.ttl)}
+		}
+		c.mu.Unlock()
+		return item, nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return v.(*model.ContractItem), nil
+}
+
+// invalidate は商品のキャッシュを破棄する
+func (c *itemCache) invalidate(itemId uint64) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	delete(c.entries, itemId)
+	c.versions[itemId]++
+}
@@ -0,0 +1,75 @@
+package usecase
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"golang.org/x/sync/singleflight"
+
+	"uttc-hack-back-onchain/model"
+)
+
+// defaultItemSnapshotTTL は全商品のスナップショットを保持する期間のデフォルト
+// 商品に関するイベントを受信した時点で破棄されるため、TTL はイベントを取りこぼした場合の保険
+const defaultItemSnapshotTTL = time.Minute
+
+// itemSnapshot はコントラクト上の全商品を列挙した結果をキャッシュする
+// カテゴリはオンチェーンでインデックスされておらず線形スキャンが必要なため、
+// 検索のたびに全件の eth_call を発行しないよう結果を使い回す。
+// 同時に期限切れを迎えた場合のスキャンは singleflight で1回にまとめる
+type itemSnapshot struct {
+	ttl   time.Duration
+	group singleflight.Group
+
+	mu        sync.Mutex
+	items     []*model.ContractItem
+	expiresAt time.Time
+	// 無効化の回数。スキャン中に無効化された結果を保存しないために使う
+	version uint64
+}
+
+func newItemSnapshot(ttl time.Duration) *itemSnapshot {
+	return &itemSnapshot{ttl: ttl}
+}
+
+// get はキャッシュされた全商品を返し、無い場合は load で取得してキャッシュする
+// 返すスライスと要素は呼び出し元間で共有されるため変更しないこと
+func (s *itemSnapshot) get(ctx context.Context, load func(ctx context.Context) ([]*model.ContractItem, error)) ([]*model.ContractItem, error) {
+	s.mu.Lock()
+	if s.items != nil && time.Now().Before(s.expiresAt) {
+		items := s.items
+		s.mu.Unlock()
+		return items, nil
+	}
+	version := s.version
+	s.mu.Unlock()
+
+	v, err, _ := s.group.Do("items", func() (interface{}, error) {
+		items, err := load(ctx)
+		if err != nil {
+			return nil, err
+		}
+
+		s.mu.Lock()
+		if s.version == version {
+			s.items = items
+			s.expiresAt = time.Now().Add(s.ttl)
+		}
+		s.mu.Unlock()
+		return items, nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return v.([]*model.ContractItem), nil
+}
+
+// invalidate はスナップショットを破棄する
+func (s *itemSnapshot) invalidate() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.items = nil
+	s.version++
+}
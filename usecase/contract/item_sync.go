@@ -0,0 +1,99 @@
+package usecase
+
+import (
+	"context"
+	"log"
+	"strings"
+
+	"golang.org/x/time/rate"
+
+	"uttc-hack-back-onchain/internal/notifier"
+	"uttc-hack-back-onchain/logger"
+	"uttc-hack-back-onchain/model"
+)
+
+// defaultItemSyncRPS は同期のための getItem 呼び出しの1秒あたりの上限のデフォルト
+const defaultItemSyncRPS = 5
+
+// itemSyncer はイベント通知後にコントラクトから商品の最新状態を取得し直し、バックエンドに送る対象を管理する
+// イベントに含まれない項目（ItemCancelled の価格など）をバックエンドの商品ミラーに反映するために使う
+type itemSyncer struct {
+	eventTypes map[model.EventType]bool
+	limiter    *rate.Limiter
+}
+
+// newItemSyncerFromEnv は ITEM_SYNC_EVENT_TYPES（カンマ区切りのイベント名）が設定されている場合のみ同期を有効化する
+func newItemSyncerFromEnv(value string, rps int) *itemSyncer {
+	eventTypes := make(map[model.EventType]bool)
+	for _, name := range strings.Split(value, ",") {
+		eventType := model.EventType(strings.TrimSpace(name))
+		switch eventType {
+		case "":
+			continue
+		case model.EventItemListed, model.EventItemPurchased, model.EventItemUpdated,
+			model.EventItemCancelled, model.EventReceiptConfirmed, model.EventPriceReduced:
+			eventTypes[eventType] = true
+		default:
+			log.Printf("WARNING: Ignoring unknown event type in ITEM_SYNC_EVENT_TYPES: %s", eventType)
+		}
+	}
+	if len(eventTypes) == 0 {
+		return nil
+	}
+
+	return &itemSyncer{
+		eventTypes: eventTypes,
+		limiter:    rate.NewLimiter(rate.Limit(rps), 1),
+	}
+}
+
+// syncItem はイベント対象の商品をコントラクトから取得し、/api/v1/blockchain/item-synced に送る
+// イベント自体の通知は完了しているため、失敗してもログのみ
+func (uc *contractUsecase) syncItem(ctx context.Context, event *model.ContractEvent) {
+	if uc.itemSync == nil || !uc.itemSync.eventTypes[event.Type] {
+		return
+	}
+	eventLog := logger.ForEvent(event)
+
+	// getItem はメインのコントラクトにしか問い合わせられないため、旧コントラクトのイベントは対象外
+	if !strings.EqualFold(event.ContractAddress, uc.gateway.GetContractAddress()) {
+		return
+	}
+
+	if err := uc.itemSync.limiter.Wait(ctx); err != nil {
+		return
+	}
+	item, err := uc.GetItem(ctx, event.ItemId)
+	if err != nil {
+		eventLog.Error("Failed to fetch item for sync", "error", err)
+		return
+	}
+
+	payload := map[string]interface{}{
+		"chain_item_id":    item.ItemId,
+		"contract_address": event.ContractAddress,
+		"token_id":         item.TokenId,
+		"title":            item.Title,
+		"price_wei":        bigIntString(item.Price),
+		"explanation":      item.Explanation,
+		"image_url":        item.ImageUrl,
+		"uid":              item.Uid,
+		"category":         item.Category,
+		"seller":           item.Seller,
+		"buyer":            item.Buyer,
+		"buyer_uid":        item.BuyerUid,
+		"is_purchased":     item.IsPurchased,
+		"status":           item.Status,
+		"status_label":     item.StatusString(),
+		"created_at":       item.CreatedAt,
+		"updated_at":       item.UpdatedAt,
+		"event_type":       event.Type,
+		"tx_hash":          event.TxHash,
+		"block_number":     event.BlockNo,
+	}
+	if err := uc.notifier.Post(notifier.WithTraceID(ctx, event.TraceID), uc.endpoints.ItemSynced, payload); err != nil {
+		eventLog.Error("Failed to notify item sync", "error", err)
+		return
+	}
+	eventLog.Info("Item synced")
+}
@@ -0,0 +1,70 @@
+package usecase
+
+import (
+	"context"
+	"strings"
+	"sync"
+
+	"uttc-hack-back-onchain/logger"
+	"uttc-hack-back-onchain/model"
+)
+
+// listingUidCache は ItemListed イベントに uid が無かった商品について、コントラクトから補った uid を保持する
+// 出品者の uid は出品後に変わらないため期限は設けない（補えた対応のみ保持する）
+type listingUidCache struct {
+	mu   sync.Mutex
+	uids map[uint64]string
+}
+
+func newListingUidCache() *listingUidCache {
+	return &listingUidCache{uids: make(map[uint64]string)}
+}
+
+func (c *listingUidCache) get(itemId uint64) (string, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	uid, ok := c.uids[itemId]
+	return uid, ok
+}
+
+func (c *listingUidCache) set(itemId uint64, uid string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.uids[itemId] = uid
+}
+
+// resolveListingUid は ItemListed イベントの出品者 uid を返す
+// イベントに uid が無い場合はコントラクトに保存された商品の uid で補う。
+// 取得に失敗した場合や商品の uid も空の場合は通知を止めず、空の uid を送る
+func (uc *contractUsecase) resolveListingUid(ctx context.Context, event *model.ContractEvent) string {
+	if event.Uid != "" {
+		return event.Uid
+	}
+	eventLog := logger.ForEvent(event)
+
+	// 移行前のデプロイメントの商品は getItem で参照できない
+	if !strings.EqualFold(event.ContractAddress, uc.gateway.GetContractAddress()) {
+		eventLog.Warn("uid is empty in ItemListed event from a non-primary contract, sending empty uid")
+		return ""
+	}
+
+	if uid, ok := uc.listingUids.get(event.ItemId); ok {
+		event.Uid = uid
+		return uid
+	}
+
+	item, err := uc.GetItem(ctx, event.ItemId)
+	if err != nil {
+		eventLog.Warn("uid is empty in ItemListed event and item lookup failed, sending empty uid", "error", err)
+		return ""
+	}
+	if item.ItemId != event.ItemId || item.Uid == "" {
+		eventLog.Warn("uid is empty in ItemListed event and on the contract, sending empty uid")
+		return ""
+	}
+
+	eventLog.Info("uid is empty in ItemListed event, using uid stored on the contract", "uid", item.Uid)
+	uc.listingUids.set(event.ItemId, item.Uid)
+	event.Uid = item.Uid
+	return item.Uid
+}
@@ -0,0 +1,109 @@
+package usecase
+
+import (
+	"sync"
+	"time"
+
+	"uttc-hack-back-onchain/internal/httpclient"
+	"uttc-hack-back-onchain/model"
+)
+
+// RPCEndpointReporter は HTTP RPC のエンドポイントの状態を返す（httpclient.FailoverTransport が満たす）
+type RPCEndpointReporter interface {
+	ActiveEndpoint() string
+	Health() []httpclient.EndpointHealth
+}
+
+// listenerStats は起動後のイベント処理の集計（/api/v1/contract/status 用）
+type listenerStats struct {
+	startedAt time.Time
+
+	mu                sync.Mutex
+	eventCounts       map[model.EventType]uint64 // 通知に成功したイベント数（種類ごと）
+	notifyFailures    uint64
+	lastNotifyError   string
+	lastNotifyErrorAt time.Time
+}
+
+func newListenerStats() *listenerStats {
+	return &listenerStats{
+		startedAt:   time.Now(),
+		eventCounts: make(map[model.EventType]uint64),
+	}
+}
+
+func (s *listenerStats) recordProcessed(eventType model.EventType) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.eventCounts[eventType]++
+}
+
+func (s *listenerStats) recordNotifyError(err error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.notifyFailures++
+	s.lastNotifyError = err.Error()
+	s.lastNotifyErrorAt = time.Now()
+}
+
+// Status はイベントリスナーの現在の状態を返す（運用ダッシュボード用）
+// 集計はプロセス内のみで、再起動するとリセットされる
+func (uc *contractUsecase) Status() *model.ListenerStatus {
+	uc.mu.Lock()
+	status := &model.ListenerStatus{
+		LastProcessedBlock: uc.lastProcessedBlock,
+		CheckpointBlock:    uc.savedBlock,
+		PastScanDone:       uc.pastScanDone,
+	}
+	uc.mu.Unlock()
+
+	status.ContractAddress = uc.gateway.GetContractAddress()
+	status.EventSource = uc.gateway.EventSourceMode()
+	status.WSReconnects = uc.gateway.WSReconnectCount()
+	status.WSEndpoint = uc.gateway.ActiveWSEndpoint()
+	lastBlock, lastBlockAt := uc.gateway.LastBlockSeen()
+	status.LastBlockSeen = lastBlock
+	if !lastBlockAt.IsZero() {
+		status.LastBlockSeenAt = lastBlockAt.Unix()
+	}
+	if uc.rpcEndpoints != nil {
+		status.RPCEndpoint = uc.rpcEndpoints.ActiveEndpoint()
+		for _, h := range uc.rpcEndpoints.Health() {
+			endpoint := model.RPCEndpointHealth{
+				URL:                 h.URL,
+				Active:              h.Active,
+				ConsecutiveFailures: h.ConsecutiveFailures,
+				LastError:           h.LastError,
+			}
+			if !h.LastFailureAt.IsZero() {
+				endpoint.LastFailureAt = h.LastFailureAt.Unix()
+			}
+			status.RPCEndpoints = append(status.RPCEndpoints, endpoint)
+		}
+	}
+
+	breaker := uc.notifier.BreakerStats()
+	status.NotifierBreaker = breaker.State
+	status.NotifierRejected = breaker.Rejected
+	if !breaker.OpenedAt.IsZero() {
+		status.NotifierBreakerOpenedAt = breaker.OpenedAt.Unix()
+	}
+
+	s := uc.stats
+	status.StartedAt = s.startedAt.Unix()
+	status.UptimeSeconds = int64(time.Since(s.startedAt).Seconds())
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	status.EventsProcessed = make(map[string]uint64, len(s.eventCounts))
+	for eventType, count := range s.eventCounts {
+		status.EventsProcessed[string(eventType)] = count
+		status.TotalEventsProcessed += count
+	}
+	status.NotifyFailures = s.notifyFailures
+	if s.lastNotifyError != "" {
+		status.LastNotifyError = s.lastNotifyError
+		status.LastNotifyErrorAt = s.lastNotifyErrorAt.Unix()
+	}
+	return status
+}
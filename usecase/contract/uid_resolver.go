@@ -0,0 +1,92 @@
+package usecase
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+)
+
+const (
+	// defaultBuyerUidCacheTTL はウォレット→uid の対応をキャッシュする期間のデフォルト
+	defaultBuyerUidCacheTTL = 10 * time.Minute
+	// buyerUidLookupTimeout はバックエンドへの uid 問い合わせのタイムアウト
+	// 通知処理を止めないよう短めにする
+	buyerUidLookupTimeout = 3 * time.Second
+)
+
+type buyerUidEntry struct {
+	uid       string
+	expiresAt time.Time
+}
+
+// buyerUidResolver は購入者のウォレットアドレスをバックエンドに問い合わせてアプリのユーザーID（uid）に変換する
+// 解決できた対応のみキャッシュする（未登録のウォレットは後から紐付けられる可能性があるため）
+type buyerUidResolver struct {
+	baseURL string
+	client  *http.Client
+	ttl     time.Duration
+
+	mu      sync.Mutex
+	entries map[string]buyerUidEntry
+}
+
+func newBuyerUidResolver(baseURL string, transport http.RoundTripper, ttl time.Duration) *buyerUidResolver {
+	return &buyerUidResolver{
+		baseURL: baseURL,
+		client:  &http.Client{Timeout: buyerUidLookupTimeout, Transport: transport},
+		ttl:     ttl,
+		entries: make(map[string]buyerUidEntry),
+	}
+}
+
+// resolve はウォレットアドレスに紐付く uid を返す（未登録の場合は空文字）
+func (r *buyerUidResolver) resolve(ctx context.Context, wallet string) (string, error) {
+	key := strings.ToLower(wallet)
+
+	r.mu.Lock()
+	if entry, ok := r.entries[key]; ok && time.Now().Before(entry.expiresAt) {
+		r.mu.Unlock()
+		return entry.uid, nil
+	}
+	r.mu.Unlock()
+
+	reqURL := fmt.Sprintf("%s/api/v1/users/by-wallet/%s", r.baseURL, url.PathEscape(wallet))
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, reqURL, nil)
+	if err != nil {
+		return "", fmt.Errorf("failed to create request: %w", err)
+	}
+
+	resp, err := r.client.Do(req)
+	if err != nil {
+		return "", fmt.Errorf("failed to look up buyer uid: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode == http.StatusNotFound {
+		return "", nil
+	}
+	if resp.StatusCode != http.StatusOK {
+		body, _ := io.ReadAll(io.LimitReader(resp.Body, 1024))
+		return "", fmt.Errorf("backend returned status %d: %s", resp.StatusCode, string(body))
+	}
+
+	var user struct {
+		Uid string `json:"uid"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&user); err != nil {
+		return "", fmt.Errorf("failed to decode user response: %w", err)
+	}
+
+	if user.Uid != "" {
+		r.mu.Lock()
+		r.entries[key] = buyerUidEntry{uid: user.Uid, expiresAt: time.Now().Add(r.ttl)}
+		r.mu.Unlock()
+	}
+	return user.Uid, nil
+}
@@ -1,70 +1,278 @@
 package usecase
 
 import (
-	"bytes"
 	"context"
-	"encoding/json"
+	"errors"
 	"fmt"
 	"log"
-	"net/http"
+	"math/big"
 	"os"
+	"slices"
 	"strconv"
+	"strings"
+	"sync"
 	"time"
+	"unicode/utf8"
+
+	"github.com/ethereum/go-ethereum/common"
 
 	"uttc-hack-back-onchain/gateway/contract"
+	"uttc-hack-back-onchain/gateway/signer"
+	"uttc-hack-back-onchain/internal/notifier"
+	"uttc-hack-back-onchain/logger"
 	"uttc-hack-back-onchain/model"
 )
 
+var (
+	// ErrRelayerDisabled はリレイヤーが有効化されておらず代理送信できない
+	ErrRelayerDisabled = errors.New("relayer is disabled")
+	// ErrItemNotForSale は商品が出品中でなく購入できない
+	ErrItemNotForSale = errors.New("item is not for sale")
+	// ErrInvalidAddress はウォレットアドレスの形式が不正
+	ErrInvalidAddress = errors.New("invalid address")
+	// ErrInvalidListing は出品内容（価格・文字列の長さ）が不正
+	ErrInvalidListing = errors.New("invalid listing")
+	// ErrItemNotFound は商品がコントラクトに存在しない
+	ErrItemNotFound = errors.New("item not found")
+	// ErrInvalidBlockRange は再通知するブロック範囲が不正、または上限を超えている
+	ErrInvalidBlockRange = errors.New("invalid block range")
+)
+
+// defaultReplayMaxBlocks は1回の再通知で指定できるブロック数の上限のデフォルト
+const defaultReplayMaxBlocks = 10000
+
+// defaultResyncTimeout は1回の ResyncItemEvents・ReplayEvents にかける最大時間のデフォルト
+const defaultResyncTimeout = 5 * time.Minute
+
+// defaultRelayWaitTimeout は RelayListItem がマイニングを待つ最大時間のデフォルト
+const defaultRelayWaitTimeout = 90 * time.Second
+
 // ContractUsecase はスマートコントラクト関連のビジネスロジック
 type ContractUsecase interface {
 	// StartEventListener はイベントリスナーを開始
 	StartEventListener(ctx context.Context) error
 
+	// Shutdown はイベントリスナーの終了（受信済みイベントの通知完了）を待つ
+	// StartEventListener に渡した ctx をキャンセルしてから呼び出すこと
+	Shutdown(ctx context.Context) error
+
 	// GetItem はコントラクトから商品情報を取得
 	GetItem(ctx context.Context, itemId uint64) (*model.ContractItem, error)
 
+	// ListItems は商品を itemId 順にページングして取得し、総件数とともに返す
+	ListItems(ctx context.Context, offset, limit uint64) ([]*model.ContractItem, uint64, error)
+
+	// GetItemsBySeller は seller が出品し、現在も seller の商品を itemId 順にページングして取得し、総件数とともに返す
+	// seller の形式が不正な場合は ErrInvalidAddress を返す
+	GetItemsBySeller(ctx context.Context, seller string, offset, limit uint64) ([]*model.ContractItem, uint64, error)
+
+	// GetItemsByCategory はカテゴリ（大文字小文字を区別しない）が一致する商品を itemId 順にページングして取得し、総件数とともに返す
+	GetItemsByCategory(ctx context.Context, category string, offset, limit uint64) ([]*model.ContractItem, uint64, error)
+
+	// GetCompletedItems は取引が成立した（購入済み・受取完了の）商品を itemId 順にページングして取得し、
+	// 総件数と全件の価格の合計（GMV、Wei）とともに返す
+	GetCompletedItems(ctx context.Context, offset, limit uint64) ([]*model.ContractItem, uint64, *big.Int, error)
+
 	// VerifyTransaction はトランザクションを検証
 	VerifyTransaction(ctx context.Context, txHash string) (*model.TxVerification, error)
+
+	// VerifyTransactionAndWait は pending の間 wait（サーバー側の上限あり）まで待ってから検証結果を返す
+	VerifyTransactionAndWait(ctx context.Context, txHash string, wait time.Duration) (*model.TxVerification, error)
+
+	// DecodeTransactionLogs はトランザクションのログをイベントとしてデコードする（調査用）
+	DecodeTransactionLogs(ctx context.Context, txHash string) (*model.DecodedTxLogs, error)
+
+	// GetContractInfo はコントラクトアドレス・チェーンID・最新ブロック・NFTコントラクトのアドレスを返す
+	GetContractInfo(ctx context.Context) (*model.ContractInfo, error)
+
+	// EventSignatures はABIのイベント名ごとのシグネチャハッシュ（topic0）を返す
+	EventSignatures() map[string]string
+
+	// Status はイベントリスナーの受信方式・処理済みブロック・起動後のイベント数・直近の通知エラーを返す
+	Status() *model.ListenerStatus
+
+	// GetItemCount はコントラクトに登録された商品数（itemIdCounter）を返す
+	GetItemCount(ctx context.Context) (uint64, error)
+
+	// GetItems は複数の商品を並行して取得する（結果は ids と同じ順。失敗した商品は nil で、エラーは *ItemsError）
+	GetItems(ctx context.Context, ids []uint64) ([]*model.ContractItem, error)
+
+	// GetItemMetadata は商品のNFTのメタデータを取得する
+	GetItemMetadata(ctx context.Context, itemId uint64) (*model.NFTMetadata, error)
+
+	// GetItemHistory は商品のオンチェーンイベント（出品→購入→受取確認など）をブロック順に取得
+	GetItemHistory(ctx context.Context, itemId uint64) ([]*model.ContractEvent, error)
+
+	// ResyncItemEvents は商品のオンチェーンイベントをすべて順番にバックエンドへ再通知する
+	ResyncItemEvents(ctx context.Context, itemId uint64) ([]model.EventResyncResult, error)
+
+	// ReplayEvents は fromBlock〜toBlock のイベントをすべてバックエンドへ再通知し、種類ごとの件数を返す
+	ReplayEvents(ctx context.Context, fromBlock, toBlock uint64) (*model.EventReplaySummary, error)
+
+	// InjectTestEvent は合成したイベントを実際のイベントと同じ経路でバックエンドへ通知する（結合テスト用）
+	InjectTestEvent(ctx context.Context, event *model.ContractEvent) error
+
+	// SubscribeEvents は処理済みイベントのストリームを購読する
+	// afterBlock が0より大きい場合、保持している直近イベントのうちそれより後のブロックのものを先に再送する
+	// チャネルは購読解除・低速による切断・リスナー停止時に閉じられる
+	SubscribeEvents(afterBlock uint64) (<-chan *model.ContractEvent, func())
+
+	// RelayBuyItem はリレイヤーウォレットから出品価格で buyItem を代理送信する
+	RelayBuyItem(ctx context.Context, itemId uint64, buyerUid string) (*model.RelayedBuyItem, error)
+
+	// RelayListItem はリレイヤーウォレットから listItem を代理送信し、マイニング後の itemId を返す
+	RelayListItem(ctx context.Context, params signer.ListItemParams) (*model.RelayedListItem, error)
 }
 
 type contractUsecase struct {
-	gateway        contract.ContractGateway
-	backendBaseURL string
+	gateway     contract.ContractGateway
+	notifier    *notifier.BackendNotifier
+	checkpoint  Checkpoint
+	dedup       *eventDeduper
+	broadcaster *eventBroadcaster
+	itemCache   *itemCache
+	snapshot    *itemSnapshot
+	relayer     signer.MarketplaceRelayer // nil の場合は代理購入を無効化
+	buyerUids   *buyerUidResolver         // nil の場合は購入者 uid をバックエンドに問い合わせない
+	itemSync    *itemSyncer               // nil の場合は通知後の商品状態の再取得を行わない
+	pool        *eventWorkerPool
+	stats       *listenerStats
+	handoff     *scanHandoff
+	listingUids *listingUidCache
+	// nil の場合はステータスに RPC エンドポイントを含めない
+	rpcEndpoints RPCEndpointReporter
+	// イベントの種類ごとのバックエンドの通知先パス
+	endpoints NotifyEndpoints
+	// ABIに無いイベントをデバッグ用エンドポイントに転送する（false の場合は破棄）
+	forwardUnknownEvents bool
+	// コントラクト呼び出しの gasUsed がこれを下回ると SuspiciousLowGas を立てる（0 の場合は判定しない）
+	minContractCallGas uint64
+	// GetItems が同時に発行する getItem 呼び出し数
+	itemFetchConcurrency int
+	// VerifyTransactionAndWait の待機時間の上限
+	maxVerifyWait time.Duration
+	// RelayListItem がマイニングを待つ時間の上限
+	relayWaitTimeout time.Duration
+	// ResyncItemEvents・ReplayEvents 全体にかける時間の上限
+	resyncTimeout time.Duration
+
+	// イベントリスナーのゴルーチン（終了待ち用）
+	listeners sync.WaitGroup
+
+	// 処理済みブロックの状態（過去スキャンとリアルタイム受信の両方から更新される）
+	mu                 sync.Mutex
+	lastProcessedBlock uint64
+	pastScanDone       bool
+	savedBlock         uint64 // 最後に永続化したブロック番号
 }
 
-func NewContractUsecase(gw contract.ContractGateway, backendBaseURL string) *contractUsecase {
+// endpoints は事前に Validate 済みであること（すべてのイベントの種類に通知先が必要）
+func NewContractUsecase(gw contract.ContractGateway, backendNotifier *notifier.BackendNotifier, checkpoint Checkpoint, relayer signer.MarketplaceRelayer, rpcEndpoints RPCEndpointReporter, endpoints NotifyEndpoints) *contractUsecase {
+	// 購入者ウォレット→uid の問い合わせは、バックエンドが /api/v1/users/by-wallet を提供している場合のみ有効化する
+	var buyerUids *buyerUidResolver
+	if os.Getenv("BUYER_UID_LOOKUP") == "true" {
+		buyerUids = newBuyerUidResolver(backendNotifier.BaseURL(), backendNotifier.Transport(), getDurationFromEnv("BUYER_UID_CACHE_TTL", defaultBuyerUidCacheTTL))
+	}
+
 	return &contractUsecase{
-		gateway:        gw,
-		backendBaseURL: backendBaseURL,
+		gateway:     gw,
+		notifier:    backendNotifier,
+		checkpoint:  checkpoint,
+		broadcaster: newEventBroadcaster(),
+		itemCache:   newItemCache(getDurationFromEnv("ITEM_CACHE_TTL", defaultItemCacheTTL)),
+		snapshot:    newItemSnapshot(getDurationFromEnv("ITEM_SNAPSHOT_TTL", defaultItemSnapshotTTL)),
+		pool:        newEventWorkerPool(getIntFromEnv("EVENT_WORKERS", defaultEventWorkers)),
+		stats:       newListenerStats(),
+		handoff:     newScanHandoff(getIntFromEnv("SCAN_HANDOFF_BUFFER", defaultScanHandoffBuffer)),
+		listingUids: newListingUidCache(),
+		relayer:     relayer,
+		buyerUids:   buyerUids,
+		itemSync:    newItemSyncerFromEnv(os.Getenv("ITEM_SYNC_EVENT_TYPES"), getIntFromEnv("ITEM_SYNC_RPS", defaultItemSyncRPS)),
+		// ABIの不一致をコード変更なしに調査できるよう、環境変数で転送を有効化する
+		forwardUnknownEvents: os.Getenv("DEBUG_FORWARD_UNKNOWN_EVENTS") == "true",
+		minContractCallGas:   uint64(getIntFromEnv("MIN_CONTRACT_CALL_GAS", 0)),
+		itemFetchConcurrency: getIntFromEnv("ITEM_FETCH_CONCURRENCY", defaultItemFetchConcurrency),
+		maxVerifyWait:        getDurationFromEnv("VERIFY_TX_MAX_WAIT", defaultMaxVerifyWait),
+		relayWaitTimeout:     getDurationFromEnv("RELAY_WAIT_TIMEOUT", defaultRelayWaitTimeout),
+		resyncTimeout:        getDurationFromEnv("RESYNC_TIMEOUT", defaultResyncTimeout),
+		rpcEndpoints:         rpcEndpoints,
+		endpoints:            endpoints,
+		dedup: newEventDeduper(
+			getDurationFromEnv("EVENT_DEDUP_TTL", defaultDedupTTL),
+			getIntFromEnv("EVENT_DEDUP_MAX_SIZE", defaultDedupMaxSize),
+			dedupReorgWindowFromEnv(),
+		),
 	}
 }
 
 // StartEventListener はイベントリスナーを開始し、イベントをメインバックエンドに通知
 func (uc *contractUsecase) StartEventListener(ctx context.Context) error {
-	log.Printf("Starting event listener (backend: %s, contract: %s)", uc.backendBaseURL, uc.gateway.GetContractAddress())
+	log.Printf("Starting event listener (backend: %s, contract: %s)", uc.notifier.BaseURL(), uc.gateway.GetContractAddress())
+
+	// 停止時にストリーム購読者を切断する（HTTPサーバーのシャットダウンを妨げないため）
+	go func() {
+		<-ctx.Done()
+		uc.broadcaster.close()
+	}()
+
+	// イベントはワーカーで並行して通知する（同じ商品のイベントは順番通り）
+	uc.pool.start(func(event *model.ContractEvent) {
+		uc.processEvent(ctx, event)
+	}, uc.saveCheckpoint)
+
+	// 両方の受信ゴルーチンが終わったら、待ち行列に残ったイベントを処理してワーカーを止める
+	var producers sync.WaitGroup
+	producers.Add(2)
+	uc.listeners.Add(1)
+	go func() {
+		defer uc.listeners.Done()
+		producers.Wait()
+		uc.pool.close()
+	}()
 
 	// リアルタイムリスニングを即座に開始（過去イベントスキャンと並行実行）
-	go uc.startRealtimeListener(ctx)
+	// スキャン中に受信したイベントは保留し、スキャン完了後に流す（同じ商品のイベントの順序を保つため）
+	go func() {
+		defer producers.Done()
+		uc.startRealtimeListener(ctx)
+	}()
 
 	// 過去のイベントをスキャン（バックグラウンドで実行）
 	go func() {
-		deployBlock := getDeployBlockFromEnv()
-		if deployBlock > 0 {
-			log.Printf("Using deploy block from environment: %d", deployBlock)
+		defer producers.Done()
+		// スキャンが失敗・中断した場合も保留中のリアルタイムイベントを流す
+		defer uc.handoff.release(uc.dispatchRealtime)
+
+		fromBlock := getDeployBlockFromEnv()
+		if fromBlock > 0 {
+			log.Printf("Using deploy block from environment: %d", fromBlock)
 		} else {
-			log.Println("No deploy block specified, scanning from last 10000 blocks")
+			log.Println("No deploy block specified, scanning recent blocks (SCAN_LOOKBACK_BLOCKS)")
 		}
-		
-		pastEvents, err := uc.gateway.ScanPastEvents(ctx, deployBlock, nil)
+
+		// チェックポイントがあれば、その次のブロックから再開する（再起動時の重複通知を防ぐ）
+		lastBlock, err := uc.checkpoint.LoadLastBlock()
+		if err != nil {
+			log.Printf("WARNING: Failed to load checkpoint, falling back to default scan range: %v", err)
+		} else if lastBlock > 0 {
+			fromBlock = lastBlock + 1
+			log.Printf("Resuming past event scan from checkpoint: block %d", fromBlock)
+		}
+
+		pastEvents, err := uc.gateway.ScanPastEvents(ctx, fromBlock, nil, nil)
 		if err != nil {
 			log.Printf("ERROR: Failed to scan past events: %v", err)
 			return
 		}
-		
+
+		// スキャンしたイベントの処理がすべて終わってから完了を記録する
+		var pending sync.WaitGroup
 		for event := range pastEvents {
-			uc.handleEvent(event)
+			uc.pool.dispatch(event, &pending)
 		}
-		
+		pending.Wait()
+
+		uc.completePastScan()
 		log.Printf("Past events scan completed")
 	}()
 
@@ -72,6 +280,24 @@ func (uc *contractUsecase) StartEventListener(ctx context.Context) error {
 	return nil
 }
 
+// Shutdown はリスナーがチャネルに残ったイベントを処理し終えるまで待つ
+// ctx の期限までに終わらない場合は未処理のイベントを残して戻る
+func (uc *contractUsecase) Shutdown(ctx context.Context) error {
+	done := make(chan struct{})
+	go func() {
+		uc.listeners.Wait()
+		close(done)
+	}()
+
+	select {
+	case <-done:
+		log.Println("Event listener stopped")
+		return nil
+	case <-ctx.Done():
+		return fmt.Errorf("event listener did not stop in time: %w", ctx.Err())
+	}
+}
+
 // getDeployBlockFromEnv は環境変数からデプロイブロックを取得
 func getDeployBlockFromEnv() uint64 {
 	// 環境変数 CONTRACT_DEPLOY_BLOCK が設定されている場合はそれを使用
@@ -80,16 +306,162 @@ func getDeployBlockFromEnv() uint64 {
 	if deployBlockStr == "" {
 		return 0
 	}
-	
+
 	deployBlock, err := strconv.ParseUint(deployBlockStr, 10, 64)
 	if err != nil {
 		log.Printf("WARNING: Invalid CONTRACT_DEPLOY_BLOCK value: %s, using 0 (auto)", deployBlockStr)
 		return 0
 	}
-	
+
 	return deployBlock
 }
 
+// getDurationFromEnv は環境変数から time.Duration を読み取る（未設定・不正値ならデフォルト）
+func getDurationFromEnv(key string, defaultValue time.Duration) time.Duration {
+	value := os.Getenv(key)
+	if value == "" {
+		return defaultValue
+	}
+
+	d, err := time.ParseDuration(value)
+	if err != nil || d <= 0 {
+		log.Printf("WARNING: Invalid %s value: %s, using default %v", key, value, defaultValue)
+		return defaultValue
+	}
+	return d
+}
+
+// getIntFromEnv は環境変数から正の整数を読み取る（未設定・不正値ならデフォルト）
+func getIntFromEnv(key string, defaultValue int) int {
+	value := os.Getenv(key)
+	if value == "" {
+		return defaultValue
+	}
+
+	n, err := strconv.Atoi(value)
+	if err != nil || n <= 0 {
+		log.Printf("WARNING: Invalid %s value: %s, using default %d", key, value, defaultValue)
+		return defaultValue
+	}
+	return n
+}
+
+// dedupReorgWindowFromEnv は重複排除で保護するブロック数を返す
+// ポーリングが再取得する REORG_DEPTH より短いと、再送されたログを重複と判定できなくなるため
+func dedupReorgWindowFromEnv() uint64 {
+	window := uint64(getIntFromEnv("REORG_DEPTH", int(dedupReorgWindow)))
+	if window < dedupReorgWindow {
+		window = dedupReorgWindow
+	}
+	return window
+}
+
+// processEvent は重複を除外したうえでイベントを通知し、成功したら処理済みとして記録する
+func (uc *contractUsecase) processEvent(ctx context.Context, event *model.ContractEvent) {
+	// 不正なイベント1件のパニックでワーカーが止まり、以降のイベントが処理されなくなるのを防ぐ
+	defer func() {
+		if r := recover(); r != nil {
+			logger.ForEvent(event).Error("Panic while processing event", "panic", r)
+		}
+	}()
+
+	// ABIに無いイベントは通常の処理（重複排除・チェックポイント・ストリーム配信）の対象にしない
+	if event.Type == model.EventUnknown {
+		if uc.forwardUnknownEvents && !event.Removed {
+			uc.forwardUnknownEvent(ctx, event)
+		}
+		return
+	}
+
+	// 商品の状態が変わるイベントではキャッシュを破棄する（リオルグでの取り消しも含む）
+	// 全商品のスナップショットは新規出品でも変わるため、すべてのイベントで破棄する
+	uc.snapshot.invalidate()
+	switch event.Type {
+	case model.EventItemUpdated, model.EventItemPurchased, model.EventItemCancelled,
+		model.EventReceiptConfirmed, model.EventPriceReduced:
+		uc.itemCache.invalidate(event.ItemId)
+	}
+
+	// リオルグで置き換えられたイベントは通知しない（カノニカルなログは別途再送される）
+	if event.Removed {
+		logger.ForEvent(event).Warn("Event superseded by reorg", "block_number", event.BlockNo)
+		// ストリーム購読者には取り消しとして配信する
+		uc.broadcaster.publish(event)
+		return
+	}
+
+	if uc.dedup.Seen(event) {
+		logger.ForEvent(event).Info("Skipping duplicate event")
+		return
+	}
+
+	if err := uc.handleEvent(ctx, event); err != nil {
+		return
+	}
+
+	uc.dedup.MarkSeen(event)
+	uc.recordProcessedBlock(event.BlockNo)
+	uc.stats.recordProcessed(event.Type)
+	uc.broadcaster.publish(event)
+}
+
+// SubscribeEvents はバックエンドへの通知に成功したイベントのストリームを購読する
+func (uc *contractUsecase) SubscribeEvents(afterBlock uint64) (<-chan *model.ContractEvent, func()) {
+	return uc.broadcaster.subscribe(afterBlock)
+}
+
+// recordProcessedBlock は通知に成功したイベントのブロック番号をメモリ上に記録する
+// 永続化はワーカーがイベントを処理し終えた後に saveCheckpoint で行う
+func (uc *contractUsecase) recordProcessedBlock(block uint64) {
+	uc.mu.Lock()
+	defer uc.mu.Unlock()
+
+	if block > uc.lastProcessedBlock {
+		uc.lastProcessedBlock = block
+	}
+}
+
+// saveCheckpoint は処理済みブロックを永続化する
+// 過去スキャン完了前にチェックポイントを進めると、未スキャンの範囲が再起動時に失われるため
+// スキャン中は何もせず、完了後に completePastScan から保存する
+func (uc *contractUsecase) saveCheckpoint() {
+	uc.mu.Lock()
+	defer uc.mu.Unlock()
+
+	if uc.pastScanDone {
+		uc.saveCheckpointLocked()
+	}
+}
+
+// completePastScan は過去スキャンの完了を記録し、その時点の処理済みブロックを永続化する
+func (uc *contractUsecase) completePastScan() {
+	uc.mu.Lock()
+	defer uc.mu.Unlock()
+
+	uc.pastScanDone = true
+	if uc.lastProcessedBlock > 0 {
+		uc.saveCheckpointLocked()
+	}
+}
+
+// saveCheckpointLocked は処理中のイベントより前のブロックまでを保存する
+// 並行処理中に再起動しても、まだ通知していないイベントのブロックを飛ばさないため
+func (uc *contractUsecase) saveCheckpointLocked() {
+	block := uc.lastProcessedBlock
+	if lowest, ok := uc.pool.lowestInflightBlock(); ok && lowest > 0 && lowest <= block {
+		block = lowest - 1
+	}
+	if block <= uc.savedBlock {
+		return
+	}
+
+	if err := uc.checkpoint.SaveLastBlock(block); err != nil {
+		log.Printf("WARNING: Failed to save checkpoint (block %d): %v", block, err)
+		return
+	}
+	uc.savedBlock = block
+}
+
 // startRealtimeListener はリアルタイムイベントリスニングを開始（自動再起動）
 func (uc *contractUsecase) startRealtimeListener(ctx context.Context) {
 	retryDelay := 5 * time.Second
@@ -116,9 +488,10 @@ func (uc *contractUsecase) startRealtimeListener(ctx context.Context) {
 			log.Println("Event listener connected (realtime)")
 
 			for event := range eventChan {
-				uc.handleEvent(event)
+				uc.handoff.submit(event, uc.dispatchRealtime)
 			}
 
+			// WebSocket の再購読はゲートウェイ内で行うため、ここに来るのはポーリングが停止した場合
 			log.Printf("Event channel closed, reconnecting in %v...", retryDelay)
 			select {
 			case <-ctx.Done():
@@ -130,6 +503,11 @@ func (uc *contractUsecase) startRealtimeListener(ctx context.Context) {
 	}
 }
 
+// dispatchRealtime はリアルタイムで受信したイベントをワーカーに渡す
+func (uc *contractUsecase) dispatchRealtime(event *model.ContractEvent) {
+	uc.pool.dispatch(event, nil)
+}
+
 func min(a, b time.Duration) time.Duration {
 	if a < b {
 		return a
@@ -138,130 +516,556 @@ func min(a, b time.Duration) time.Duration {
 }
 
 // handleEvent はイベントを処理してメインバックエンドに通知
-func (uc *contractUsecase) handleEvent(event *model.ContractEvent) {
-	var endpoint string
+func (uc *contractUsecase) handleEvent(ctx context.Context, event *model.ContractEvent) error {
+	eventLog := logger.ForEvent(event)
 	var payload interface{}
 
+	if err := validateEvent(event); err != nil {
+		eventLog.Error("Dropping invalid event", "error", err)
+		invalidEventsMetric.Add(string(event.Type), 1)
+		return err
+	}
+
 	switch event.Type {
 	case model.EventItemListed:
-		endpoint = "/api/v1/blockchain/item-listed"
-		if event.Uid == "" {
-			log.Printf("WARNING: uid is empty in ItemListed event for item %d", event.ItemId)
-		}
+		uid := uc.resolveListingUid(ctx, event)
 		payload = map[string]interface{}{
-			"chain_item_id": event.ItemId,
-			"token_id":      event.TokenId,
-			"title":         event.Title,
-			"price_wei":     event.Price.String(),
-			"explanation":   event.Explanation,
-			"image_url":     event.ImageUrl,
-			"uid":           event.Uid,
-			"category":      event.Category,
-			"seller":        event.Seller,
-			"created_at":    event.CreatedAt,
-			"tx_hash":       event.TxHash,
+			"chain_item_id":    event.ItemId,
+			"contract_address": event.ContractAddress,
+			"token_id":         event.TokenId,
+			"title":            event.Title,
+			"price_wei":        bigIntString(event.Price),
+			"price_eth":        model.WeiToEthString(event.Price),
+			"explanation":      event.Explanation,
+			"image_url":        event.ImageUrl,
+			"uid":              uid,
+			"category":         event.Category,
+			"seller":           event.Seller,
+			"created_at":       event.CreatedAt,
+			"tx_hash":          event.TxHash,
+			"block_time":       event.BlockTime,
 		}
 
 	case model.EventItemPurchased:
-		endpoint = "/api/v1/blockchain/item-purchased"
 		payload = map[string]interface{}{
-			"chain_item_id": event.ItemId,
-			"buyer":         event.Buyer,
-			"price_wei":     event.Price.String(),
-			"token_id":      event.TokenId,
-			"tx_hash":       event.TxHash,
+			"chain_item_id":    event.ItemId,
+			"contract_address": event.ContractAddress,
+			"buyer":            event.Buyer,
+			"buyer_uid":        uc.resolveBuyerUid(ctx, event),
+			"price_wei":        bigIntString(event.Price),
+			"token_id":         event.TokenId,
+			"tx_hash":          event.TxHash,
+			"block_time":       event.BlockTime,
 		}
-		log.Printf("Processing ItemPurchased event: itemId=%d, buyer=%s, txHash=%s", event.ItemId, event.Buyer, event.TxHash)
+		eventLog.Info("Processing ItemPurchased event", "buyer", event.Buyer)
 
 	case model.EventItemUpdated:
-		endpoint = "/api/v1/blockchain/item-updated"
 		payload = map[string]interface{}{
-			"chain_item_id": event.ItemId,
-			"title":         event.Title,
-			"price_wei":     event.Price.String(),
-			"explanation":   event.Explanation,
-			"image_url":     event.ImageUrl,
-			"category":      event.Category,
-			"updated_at":    event.UpdatedAt,
-			"tx_hash":       event.TxHash,
+			"chain_item_id":    event.ItemId,
+			"contract_address": event.ContractAddress,
+			"title":            event.Title,
+			"price_wei":        bigIntString(event.Price),
+			"explanation":      event.Explanation,
+			"image_url":        event.ImageUrl,
+			"category":         event.Category,
+			"updated_at":       event.UpdatedAt,
+			"tx_hash":          event.TxHash,
+			"block_time":       event.BlockTime,
 		}
 
 	case model.EventItemCancelled:
-		endpoint = "/api/v1/blockchain/item-cancelled"
 		payload = map[string]interface{}{
-			"chain_item_id": event.ItemId,
-			"seller":        event.Seller,
-			"tx_hash":       event.TxHash,
+			"chain_item_id":    event.ItemId,
+			"contract_address": event.ContractAddress,
+			"seller":           event.Seller,
+			"tx_hash":          event.TxHash,
+			"block_time":       event.BlockTime,
 		}
 
 	case model.EventReceiptConfirmed:
-		endpoint = "/api/v1/blockchain/receipt-confirmed"
 		payload = map[string]interface{}{
-			"chain_item_id": event.ItemId,
-			"buyer":         event.Buyer,
-			"seller":        event.Seller,
-			"price_wei":     event.Price.String(),
-			"tx_hash":       event.TxHash,
+			"chain_item_id":    event.ItemId,
+			"contract_address": event.ContractAddress,
+			"buyer":            event.Buyer,
+			"buyer_uid":        uc.resolveBuyerUid(ctx, event),
+			"seller":           event.Seller,
+			"price_wei":        bigIntString(event.Price),
+			"tx_hash":          event.TxHash,
+			"block_time":       event.BlockTime,
+		}
+
+	case model.EventPriceReduced:
+		payload = map[string]interface{}{
+			"chain_item_id":    event.ItemId,
+			"contract_address": event.ContractAddress,
+			"old_price_wei":    bigIntString(event.OldPrice),
+			"new_price_wei":    bigIntString(event.Price),
+			"tx_hash":          event.TxHash,
+			"block_time":       event.BlockTime,
 		}
 
 	default:
-		log.Printf("ERROR: Unknown event type: %s", event.Type)
+		eventLog.Error("Unknown event type")
+		return fmt.Errorf("unknown event type: %s", event.Type)
+	}
+
+	endpoint := uc.endpoints.Events[event.Type]
+	if err := uc.notifier.Post(notifier.WithTraceID(ctx, event.TraceID), endpoint, payload); err != nil {
+		eventLog.Error("Failed to notify backend", "endpoint", endpoint, "error", err)
+		uc.stats.recordNotifyError(err)
+		return err
+	}
+	eventLog.Info("Backend notified", "endpoint", endpoint)
+
+	uc.syncItem(ctx, event)
+	return nil
+}
+
+// forwardUnknownEvent はABIに無いイベントの生のログをデバッグ用エンドポイントに転送する
+// 調査用のため、失敗してもログのみ
+func (uc *contractUsecase) forwardUnknownEvent(ctx context.Context, event *model.ContractEvent) {
+	eventLog := logger.ForEvent(event)
+	payload := map[string]interface{}{
+		"contract_address": event.ContractAddress,
+		"tx_hash":          event.TxHash,
+		"block_number":     event.BlockNo,
+		"log_index":        event.LogIndex,
+		"topics":           event.RawTopics,
+		"data":             event.RawData,
+	}
+
+	if err := uc.notifier.Post(notifier.WithTraceID(ctx, event.TraceID), uc.endpoints.UnknownEvent, payload); err != nil {
+		eventLog.Error("Failed to forward unknown event", "error", err)
 		return
 	}
+	eventLog.Info("Unknown event forwarded")
+}
 
-	if err := uc.notifyBackend(endpoint, payload); err != nil {
-		log.Printf("ERROR: Failed to notify backend for event %s (item %d): %v", event.Type, event.ItemId, err)
+// resolveBuyerUid は購入者のアプリ上のユーザーIDを返す
+// イベントに記録されている uid を優先し、無ければウォレットアドレスからバックエンドに問い合わせる。
+// 問い合わせに失敗しても通知は止めず、空の uid を送る
+func (uc *contractUsecase) resolveBuyerUid(ctx context.Context, event *model.ContractEvent) string {
+	if event.BuyerUid != "" || uc.buyerUids == nil || event.Buyer == "" {
+		return event.BuyerUid
 	}
+
+	uid, err := uc.buyerUids.resolve(ctx, event.Buyer)
+	if err != nil {
+		logger.ForEvent(event).Warn("Failed to resolve buyer uid, sending empty uid", "buyer", event.Buyer, "error", err)
+		return ""
+	}
+	if uid == "" {
+		logger.ForEvent(event).Warn("No user registered for buyer wallet", "buyer", event.Buyer)
+	}
+	event.BuyerUid = uid
+	return uid
+}
+
+// bigIntString は nil を許容して10進数文字列に変換する
+// デコード失敗時も受け側の数値パースが失敗しないよう "0" を返す
+func bigIntString(v *big.Int) string {
+	if v == nil {
+		return "0"
+	}
+	return v.String()
 }
 
-// notifyBackend はメインバックエンドにイベントを通知
-func (uc *contractUsecase) notifyBackend(endpoint string, payload interface{}) error {
-	url := uc.backendBaseURL + endpoint
-	jsonData, err := json.Marshal(payload)
+// GetItem はコントラクトから商品情報を取得（短時間キャッシュする）
+func (uc *contractUsecase) GetItem(ctx context.Context, itemId uint64) (*model.ContractItem, error) {
+	return uc.itemCache.get(ctx, itemId, uc.gateway.GetItem)
+}
+
+// GetItemMetadata は商品の tokenId から NFT のメタデータを取得する
+// 存在しない itemId の getItem はゼロ値を返すため、itemId が一致しない場合は ErrItemNotFound
+func (uc *contractUsecase) GetItemMetadata(ctx context.Context, itemId uint64) (*model.NFTMetadata, error) {
+	item, err := uc.GetItem(ctx, itemId)
+	if err != nil {
+		return nil, err
+	}
+	if item.ItemId != itemId {
+		return nil, ErrItemNotFound
+	}
+
+	metadata, err := uc.gateway.GetTokenMetadata(ctx, item.TokenId)
+	if err != nil {
+		return nil, err
+	}
+	metadata.ItemId = itemId
+	return metadata, nil
+}
+
+// ListItems はitemIdCounterを元に商品を列挙する
+// itemIdは1始まりの連番のため、offset番目以降の最大limit件を GetItems でまとめて取得する
+func (uc *contractUsecase) ListItems(ctx context.Context, offset, limit uint64) ([]*model.ContractItem, uint64, error) {
+	total, err := uc.gateway.GetItemCount(ctx)
+	if err != nil {
+		return nil, 0, fmt.Errorf("failed to get item count: %w", err)
+	}
+
+	if offset >= total {
+		return []*model.ContractItem{}, total, nil
+	}
+	start, end := pageRange(offset, limit, total)
+	ids := make([]uint64, 0, end-start)
+	for itemId := start + 1; itemId <= end; itemId++ {
+		ids = append(ids, itemId)
+	}
+	items, err := uc.GetItems(ctx, ids)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	return items, total, nil
+}
+
+// pageRange は total 件のうち offset 番目から最大 limit 件の範囲 [start, end) を返す
+// limit にはクエリの値がそのまま入るため、offset+limit のオーバーフローを避けて total で切り詰める
+func pageRange(offset, limit, total uint64) (uint64, uint64) {
+	if offset >= total {
+		return total, total
+	}
+	if limit > total-offset {
+		return offset, total
+	}
+	return offset, offset + limit
+}
+
+// VerifyTransaction はトランザクションを検証
+// 成功したコントラクト呼び出しの gasUsed が下限未満なら SuspiciousLowGas を立てる（目安のみで失敗にはしない）
+func (uc *contractUsecase) VerifyTransaction(ctx context.Context, txHash string) (*model.TxVerification, error) {
+	verification, err := uc.gateway.VerifyTransaction(ctx, txHash)
 	if err != nil {
-		return fmt.Errorf("failed to marshal payload: %w", err)
+		return nil, err
 	}
 
-	client := &http.Client{Timeout: 10 * time.Second}
-	maxRetries := 3
-	var lastErr error
+	if uc.minContractCallGas > 0 && verification.IsContractCall && verification.Status == "success" {
+		verification.SuspiciousLowGas = verification.GasUsed < uc.minContractCallGas
+	}
+	return verification, nil
+}
+
+// DecodeTransactionLogs はトランザクションのログをイベントとしてデコードする
+func (uc *contractUsecase) DecodeTransactionLogs(ctx context.Context, txHash string) (*model.DecodedTxLogs, error) {
+	return uc.gateway.DecodeTransactionLogs(ctx, txHash)
+}
+
+// GetItemsByCategory はカテゴリが一致する商品を返す
+// カテゴリはオンチェーンでインデックスされていないため全商品の線形スキャンになる。
+// スキャン結果はスナップショットとしてキャッシュし、イベント受信時に破棄する
+func (uc *contractUsecase) GetItemsByCategory(ctx context.Context, category string, offset, limit uint64) ([]*model.ContractItem, uint64, error) {
+	all, err := uc.snapshot.get(ctx, uc.scanAllItems)
+	if err != nil {
+		return nil, 0, err
+	}
 
-	for i := 0; i < maxRetries; i++ {
-		if i > 0 {
-			time.Sleep(time.Duration(i) * time.Second)
+	matched := make([]*model.ContractItem, 0)
+	for _, item := range all {
+		if strings.EqualFold(item.Category, category) {
+			matched = append(matched, item)
 		}
+	}
 
-		resp, err := client.Post(url, "application/json", bytes.NewBuffer(jsonData))
-		if err != nil {
-			lastErr = fmt.Errorf("failed to send request: %w", err)
-			continue
+	total := uint64(len(matched))
+	if offset >= total {
+		return []*model.ContractItem{}, total, nil
+	}
+	start, end := pageRange(offset, limit, total)
+	return matched[start:end], total, nil
+}
+
+// GetCompletedItems は取引が成立した商品を返す
+// GetItemsByCategory と同じくスナップショット（全商品の線形スキャン）から絞り込む
+func (uc *contractUsecase) GetCompletedItems(ctx context.Context, offset, limit uint64) ([]*model.ContractItem, uint64, *big.Int, error) {
+	all, err := uc.snapshot.get(ctx, uc.scanAllItems)
+	if err != nil {
+		return nil, 0, nil, err
+	}
+
+	matched := make([]*model.ContractItem, 0)
+	gmv := new(big.Int)
+	for _, item := range all {
+		if item.Status == model.ItemStatusCompleted || item.IsPurchased {
+			matched = append(matched, item)
+			if item.Price != nil {
+				gmv.Add(gmv, item.Price)
+			}
+		}
+	}
+
+	total := uint64(len(matched))
+	if offset >= total {
+		return []*model.ContractItem{}, total, gmv, nil
+	}
+	start, end := pageRange(offset, limit, total)
+	return matched[start:end], total, gmv, nil
+}
+
+// scanAllItems は itemIdCounter までの全商品を GetItem（キャッシュ経由）で取得する
+func (uc *contractUsecase) scanAllItems(ctx context.Context) ([]*model.ContractItem, error) {
+	total, err := uc.gateway.GetItemCount(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get item count: %w", err)
+	}
+
+	ids := make([]uint64, 0, total)
+	for itemId := uint64(1); itemId <= total; itemId++ {
+		ids = append(ids, itemId)
+	}
+	return uc.GetItems(ctx, ids)
+}
+
+// GetItemsBySeller は ItemListed イベントから seller の出品を探し、GetItem で現在の状態を確認する
+// 総件数を正しく返すため、ページングの前にすべての候補の状態を取得する（GetItem のキャッシュを利用）
+func (uc *contractUsecase) GetItemsBySeller(ctx context.Context, seller string, offset, limit uint64) ([]*model.ContractItem, uint64, error) {
+	if !common.IsHexAddress(seller) {
+		return nil, 0, ErrInvalidAddress
+	}
+	sellerAddr := common.HexToAddress(seller)
+
+	itemIds, err := uc.gateway.GetListedItemIds(ctx, sellerAddr, getDeployBlockFromEnv())
+	if err != nil {
+		return nil, 0, err
+	}
+
+	candidates, err := uc.GetItems(ctx, itemIds)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	items := make([]*model.ContractItem, 0, len(candidates))
+	for _, item := range candidates {
+		if common.HexToAddress(item.Seller) == sellerAddr {
+			items = append(items, item)
 		}
-		defer resp.Body.Close()
+	}
+
+	total := uint64(len(items))
+	if offset >= total {
+		return []*model.ContractItem{}, total, nil
+	}
+	start, end := pageRange(offset, limit, total)
+	return items[start:end], total, nil
+}
+
+// GetItemCount は商品を列挙せずに出品数を表示するための商品数を返す
+func (uc *contractUsecase) GetItemCount(ctx context.Context) (uint64, error) {
+	return uc.gateway.GetItemCount(ctx)
+}
 
-		bodyBytes := make([]byte, 1024)
-		n, _ := resp.Body.Read(bodyBytes)
-		bodyStr := string(bodyBytes[:n])
+// GetContractInfo はフロントエンドが接続先デプロイメントを確認するための情報を返す
+func (uc *contractUsecase) GetContractInfo(ctx context.Context) (*model.ContractInfo, error) {
+	chainID, err := uc.gateway.GetChainID(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get chain id: %w", err)
+	}
+
+	latestBlock, err := uc.gateway.GetLatestBlock(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get latest block: %w", err)
+	}
+
+	nftContract, err := uc.gateway.GetNFTContractAddress(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get nft contract: %w", err)
+	}
+
+	return &model.ContractInfo{
+		ContractAddress: uc.gateway.GetContractAddress(),
+		ChainID:         chainID,
+		LatestBlock:     latestBlock,
+		NFTContract:     nftContract,
+	}, nil
+}
+
+// EventSignatures は取りこぼしたイベントの調査用に、オンチェーンのログの topic0 と照合するためのハッシュを返す
+func (uc *contractUsecase) EventSignatures() map[string]string {
+	return uc.gateway.EventSignatures()
+}
+
+// GetItemHistory はデプロイブロック（未設定なら0）以降の商品のイベント履歴を取得
+func (uc *contractUsecase) GetItemHistory(ctx context.Context, itemId uint64) ([]*model.ContractEvent, error) {
+	return uc.gateway.GetItemHistory(ctx, itemId, getDeployBlockFromEnv())
+}
+
+// ResyncItemEvents はバックエンドが商品の履歴を失った場合の復旧用に、全イベントを再通知する
+// 重複排除は通さず、各イベントの通知結果を返す。
+// 全体の所要時間は RESYNC_TIMEOUT で打ち切り、期限後のイベントはエラーとして結果に含める
+func (uc *contractUsecase) ResyncItemEvents(ctx context.Context, itemId uint64) ([]model.EventResyncResult, error) {
+	ctx, cancel := context.WithTimeout(ctx, uc.resyncTimeout)
+	defer cancel()
+
+	events, err := uc.gateway.GetItemHistory(ctx, itemId, getDeployBlockFromEnv())
+	if err != nil {
+		return nil, err
+	}
 
-		if resp.StatusCode >= 200 && resp.StatusCode < 300 {
-			return nil
+	results := make([]model.EventResyncResult, 0, len(events))
+	for _, event := range events {
+		result := model.EventResyncResult{
+			Type:     event.Type,
+			TxHash:   event.TxHash,
+			BlockNo:  event.BlockNo,
+			LogIndex: event.LogIndex,
+		}
+		if err := ctx.Err(); err != nil {
+			result.Error = err.Error()
+		} else if err := uc.handleEvent(ctx, event); err != nil {
+			result.Error = err.Error()
+		} else {
+			result.Notified = true
 		}
+		results = append(results, result)
+	}
 
-		lastErr = fmt.Errorf("backend returned status %d: %s", resp.StatusCode, bodyStr)
-		// 4xxエラーはリトライしない
-		if resp.StatusCode >= 400 && resp.StatusCode < 500 {
-			return lastErr
+	if ctx.Err() != nil {
+		log.Printf("WARNING: Resync for item %d stopped before all events were notified: %v", itemId, ctx.Err())
+	}
+	log.Printf("Resynced %d events for item %d", len(results), itemId)
+	return results, nil
+}
+
+// ReplayEvents はバックエンドのデータが失われた場合の復旧用に、ブロック範囲のイベントを再通知する
+// 通知済みかどうか（重複排除）に関わらず送信し、チェックポイントも更新しない。
+// eth_getLogs の負荷を抑えるため、範囲は REPLAY_MAX_BLOCKS ブロックまでに制限し、所要時間は RESYNC_TIMEOUT で打ち切る
+func (uc *contractUsecase) ReplayEvents(ctx context.Context, fromBlock, toBlock uint64) (*model.EventReplaySummary, error) {
+	if fromBlock == 0 || toBlock < fromBlock {
+		return nil, fmt.Errorf("%w: from_block must be positive and not after to_block", ErrInvalidBlockRange)
+	}
+	maxBlocks := uint64(getIntFromEnv("REPLAY_MAX_BLOCKS", defaultReplayMaxBlocks))
+	if toBlock-fromBlock+1 > maxBlocks {
+		return nil, fmt.Errorf("%w: at most %d blocks can be replayed at once", ErrInvalidBlockRange, maxBlocks)
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, uc.resyncTimeout)
+	defer cancel()
+
+	events, err := uc.gateway.ScanPastEvents(ctx, fromBlock, &toBlock, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	summary := &model.EventReplaySummary{
+		FromBlock: fromBlock,
+		ToBlock:   toBlock,
+		Notified:  make(map[model.EventType]int),
+		Failed:    make(map[model.EventType]int),
+	}
+	for event := range events {
+		if event.Type == model.EventUnknown || event.Removed {
+			continue
+		}
+		if err := uc.handleEvent(ctx, event); err != nil {
+			summary.Failed[event.Type]++
+			continue
 		}
+		summary.Notified[event.Type]++
+	}
+	if err := ctx.Err(); err != nil {
+		return nil, err
 	}
 
-	return fmt.Errorf("failed after %d retries: %w", maxRetries, lastErr)
+	log.Printf("Replayed events in blocks %d-%d: notified=%v failed=%v", fromBlock, toBlock, summary.Notified, summary.Failed)
+	return summary, nil
 }
 
-// GetItem はコントラクトから商品情報を取得
-func (uc *contractUsecase) GetItem(ctx context.Context, itemId uint64) (*model.ContractItem, error) {
-	return uc.gateway.GetItem(ctx, itemId)
+// InjectTestEvent はコントラクトを使わずにバックエンドの通知処理を試すため、合成したイベントを handleEvent に渡す
+// ABI に定義されたイベント以外は ErrInvalidEvent を返す。チェックポイントや重複排除の記録には影響しない
+func (uc *contractUsecase) InjectTestEvent(ctx context.Context, event *model.ContractEvent) error {
+	if !slices.Contains(knownEventTypes, event.Type) {
+		return fmt.Errorf("%w: unknown event type %q", ErrInvalidEvent, event.Type)
+	}
+	if event.TraceID == "" {
+		event.TraceID = logger.NewTraceID()
+	}
+	// リクエストで指定されたアドレスは実際のイベントと同じチェックサム形式にそろえる
+	event.NormalizeAddresses()
+
+	logger.ForEvent(event).Warn("Injecting synthetic test event")
+	return uc.handleEvent(ctx, event)
 }
 
-// VerifyTransaction はトランザクションを検証
-func (uc *contractUsecase) VerifyTransaction(ctx context.Context, txHash string) (*model.TxVerification, error) {
-	return uc.gateway.VerifyTransaction(ctx, txHash)
+// RelayBuyItem はデモ用に、リレイヤーウォレットが購入者の代わりに buyItem を送信する
+// 送金額はコントラクト上の出品価格を使用する
+func (uc *contractUsecase) RelayBuyItem(ctx context.Context, itemId uint64, buyerUid string) (*model.RelayedBuyItem, error) {
+	if uc.relayer == nil {
+		return nil, ErrRelayerDisabled
+	}
+
+	item, err := uc.gateway.GetItem(ctx, itemId)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get item %d: %w", itemId, err)
+	}
+	// 0: Listed 以外は購入できない
+	if item.Status != model.ItemStatusListed || item.IsPurchased {
+		return nil, ErrItemNotForSale
+	}
+
+	txHash, err := uc.relayer.SubmitBuyItem(ctx, itemId, item.Price, buyerUid)
+	if err != nil {
+		return nil, fmt.Errorf("failed to submit buyItem: %w", err)
+	}
+
+	return &model.RelayedBuyItem{
+		ItemId:   itemId,
+		TxHash:   txHash,
+		From:     uc.relayer.Address(),
+		ValueWei: item.Price.String(),
+		BuyerUid: buyerUid,
+	}, nil
+}
+
+// 出品時の文字列の最大長（文字数）。コントラクトに保存されるため、ガス代が膨らまないよう制限する
+var listItemMaxLengths = []struct {
+	field  string
+	value  func(p signer.ListItemParams) string
+	maxLen int
+}{
+	{"title", func(p signer.ListItemParams) string { return p.Title }, 100},
+	{"explanation", func(p signer.ListItemParams) string { return p.Explanation }, 2000},
+	{"image_url", func(p signer.ListItemParams) string { return p.ImageUrl }, 2048},
+	{"uid", func(p signer.ListItemParams) string { return p.Uid }, 128},
+	{"category", func(p signer.ListItemParams) string { return p.Category }, 64},
+	{"token_uri", func(p signer.ListItemParams) string { return p.TokenURI }, 2048},
+}
+
+// RelayListItem はデモ用に、リレイヤーウォレットが出品者の代わりに listItem を送信する
+// 送信前に価格と文字列の長さを検証し、リバートが確実な出品でガス代を失わないようにする
+func (uc *contractUsecase) RelayListItem(ctx context.Context, params signer.ListItemParams) (*model.RelayedListItem, error) {
+	if uc.relayer == nil {
+		return nil, ErrRelayerDisabled
+	}
+
+	if params.Price == nil || params.Price.Sign() <= 0 {
+		return nil, fmt.Errorf("%w: price must be positive", ErrInvalidListing)
+	}
+	if params.Title == "" {
+		return nil, fmt.Errorf("%w: title is required", ErrInvalidListing)
+	}
+	for _, l := range listItemMaxLengths {
+		if utf8.RuneCountInString(l.value(params)) > l.maxLen {
+			return nil, fmt.Errorf("%w: %s must be at most %d characters", ErrInvalidListing, l.field, l.maxLen)
+		}
+	}
+
+	// マイニングの待機がリクエストを無制限に占有しないよう上限を設ける
+	waitCtx, cancel := context.WithTimeout(ctx, uc.relayWaitTimeout)
+	defer cancel()
+
+	relayed := &model.RelayedListItem{
+		From:     uc.relayer.Address(),
+		PriceWei: params.Price.String(),
+	}
+	txHash, itemId, err := uc.relayer.SubmitListItem(waitCtx, params)
+	if errors.Is(err, signer.ErrNotMinedYet) && ctx.Err() == nil {
+		// 送信済みのトランザクションは有効なため、失敗ではなく未確定として返す（itemId はイベントで通知される）
+		log.Printf("listItem not mined within %v: %s", uc.relayWaitTimeout, txHash)
+		relayed.TxHash, relayed.Pending = txHash, true
+		return relayed, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to submit listItem: %w", err)
+	}
+
+	relayed.ItemId, relayed.TxHash = itemId, txHash
+	return relayed, nil
 }
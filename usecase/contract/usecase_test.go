@@ -0,0 +1,190 @@
+package usecase
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"math"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"uttc-hack-back-onchain/internal/notifier"
+	"uttc-hack-back-onchain/logger"
+	"uttc-hack-back-onchain/model"
+)
+
+// payloadRecorder はバックエンドに届いた通知のボディを記録する
+type payloadRecorder struct {
+	mu       sync.Mutex
+	payloads []map[string]interface{}
+}
+
+func (p *payloadRecorder) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	var payload map[string]interface{}
+	json.NewDecoder(r.Body).Decode(&payload)
+	p.mu.Lock()
+	p.payloads = append(p.payloads, payload)
+	p.mu.Unlock()
+}
+
+func (p *payloadRecorder) last(t *testing.T) map[string]interface{} {
+	t.Helper()
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if len(p.payloads) == 0 {
+		t.Fatal("no notification was sent")
+	}
+	return p.payloads[len(p.payloads)-1]
+}
+
+func newTestUsecase(t *testing.T, backend http.Handler) *contractUsecase {
+	t.Helper()
+	srv := httptest.NewServer(backend)
+	t.Cleanup(srv.Close)
+	n := notifier.NewBackendNotifier(srv.URL, time.Second, notifier.RetryConfig{MaxAttempts: 1}, "", nil, notifier.BreakerConfig{})
+	return NewContractUsecase(&overlapGateway{}, n, NewMemoryCheckpoint(), nil, nil, DefaultNotifyEndpoints())
+}
+
+func TestProcessEventToleratesNilPrice(t *testing.T) {
+	const buyer = "0x00000000000000000000000000000000000000b2"
+	const seller = "0x00000000000000000000000000000000000000b1"
+
+	for _, event := range []*model.ContractEvent{
+		{Type: model.EventItemPurchased, Buyer: buyer},
+		{Type: model.EventItemUpdated},
+		{Type: model.EventReceiptConfirmed, Buyer: buyer, Seller: seller},
+	} {
+		t.Run(string(event.Type), func(t *testing.T) {
+			backend := &payloadRecorder{}
+			uc := newTestUsecase(t, backend)
+			event.ItemId = 1
+			event.TxHash = "0xtx"
+
+			uc.processEvent(context.Background(), event)
+
+			if got := backend.last(t)["price_wei"]; got != "0" {
+				t.Errorf("price_wei = %v, want 0", got)
+			}
+			if !uc.dedup.Seen(event) {
+				t.Error("event was not recorded as processed")
+			}
+		})
+	}
+}
+
+func TestProcessEventDropsListingWithoutPrice(t *testing.T) {
+	backend := &payloadRecorder{}
+	uc := newTestUsecase(t, backend)
+	event := &model.ContractEvent{Type: model.EventItemListed, ItemId: 1, Seller: "0x00000000000000000000000000000000000000b1"}
+
+	uc.processEvent(context.Background(), event)
+
+	if len(backend.payloads) != 0 {
+		t.Errorf("listing without price was notified: %v", backend.payloads)
+	}
+	if uc.dedup.Seen(event) {
+		t.Error("dropped event was recorded as processed")
+	}
+}
+
+func TestProcessEventRecoversFromPanic(t *testing.T) {
+	uc := newTestUsecase(t, &payloadRecorder{})
+	// 通知先が無い状態で通知するとパニックする
+	uc.notifier = nil
+	event := &model.ContractEvent{Type: model.EventItemCancelled, ItemId: 1, Seller: "0x00000000000000000000000000000000000000b1"}
+
+	logs, restore := logger.Capture()
+	defer restore()
+	uc.processEvent(context.Background(), event)
+
+	if !strings.Contains(logs.String(), "Panic while processing event") {
+		t.Errorf("panic was not logged: %s", logs.String())
+	}
+	if uc.dedup.Seen(event) {
+		t.Error("event that panicked was recorded as processed")
+	}
+}
+
+// itemGateway は itemId 1〜count の商品を返すゲートウェイ
+type itemGateway struct {
+	overlapGateway
+	count uint64
+}
+
+func (g *itemGateway) GetItemCount(ctx context.Context) (uint64, error) { return g.count, nil }
+
+func (g *itemGateway) GetItem(ctx context.Context, itemId uint64) (*model.ContractItem, error) {
+	return &model.ContractItem{ItemId: itemId, Category: "book", Status: model.ItemStatusCompleted}, nil
+}
+
+func TestPageRange(t *testing.T) {
+	tests := []struct {
+		offset, limit, total uint64
+		start, end           uint64
+	}{
+		{0, 10, 3, 0, 3},
+		{1, 1, 3, 1, 2},
+		{3, 10, 3, 3, 3},
+		{5, 10, 3, 3, 3},
+		{1, math.MaxUint64, 3, 1, 3},
+		{math.MaxUint64, math.MaxUint64, 3, 3, 3},
+		{2, math.MaxUint64 - 1, math.MaxUint64, 2, math.MaxUint64},
+	}
+	for _, tt := range tests {
+		start, end := pageRange(tt.offset, tt.limit, tt.total)
+		if start != tt.start || end != tt.end {
+			t.Errorf("pageRange(%d, %d, %d) = [%d, %d), want [%d, %d)", tt.offset, tt.limit, tt.total, start, end, tt.start, tt.end)
+		}
+	}
+}
+
+func TestPagingWithOverflowingLimit(t *testing.T) {
+	uc := newTestUsecase(t, &payloadRecorder{})
+	uc.gateway = &itemGateway{count: 3}
+	ctx := context.Background()
+
+	ids := func(items []*model.ContractItem) []uint64 {
+		out := make([]uint64, 0, len(items))
+		for _, item := range items {
+			out = append(out, item.ItemId)
+		}
+		return out
+	}
+	pages := map[string]func(offset, limit uint64) ([]*model.ContractItem, error){
+		"ListItems": func(offset, limit uint64) ([]*model.ContractItem, error) {
+			items, _, err := uc.ListItems(ctx, offset, limit)
+			return items, err
+		},
+		"GetItemsByCategory": func(offset, limit uint64) ([]*model.ContractItem, error) {
+			items, _, err := uc.GetItemsByCategory(ctx, "book", offset, limit)
+			return items, err
+		},
+		"GetCompletedItems": func(offset, limit uint64) ([]*model.ContractItem, error) {
+			items, _, _, err := uc.GetCompletedItems(ctx, offset, limit)
+			return items, err
+		},
+	}
+	for name, page := range pages {
+		t.Run(name, func(t *testing.T) {
+			items, err := page(1, math.MaxUint64)
+			if err != nil {
+				t.Fatalf("offset 1: %v", err)
+			}
+			if got := fmt.Sprint(ids(items)); got != "[2 3]" {
+				t.Errorf("offset 1 = %s, want [2 3]", got)
+			}
+
+			items, err = page(math.MaxUint64, math.MaxUint64)
+			if err != nil {
+				t.Fatalf("offset max: %v", err)
+			}
+			if len(items) != 0 {
+				t.Errorf("offset max = %v, want empty", ids(items))
+			}
+		})
+	}
+}
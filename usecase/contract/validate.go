@@ -0,0 +1,64 @@
+package usecase
+
+import (
+	"errors"
+	"expvar"
+	"fmt"
+	"math/big"
+
+	"github.com/ethereum/go-ethereum/common"
+
+	"uttc-hack-back-onchain/model"
+)
+
+// ErrInvalidEvent はイベントが種類ごとの前提（itemId・アドレス・価格）を満たしていない
+var ErrInvalidEvent = errors.New("event failed validation")
+
+// invalidEventsMetric は検証に失敗して破棄したイベント数（種類ごと、/debug/vars で公開）
+var invalidEventsMetric = expvar.NewMap("events_rejected")
+
+// validateEvent はバックエンドに通知する前にイベントの内容を検証する
+// ABIの食い違いなどでデコード結果が壊れたイベントをバックエンドに送らないため
+func validateEvent(event *model.ContractEvent) error {
+	// itemId は1から採番される
+	if event.ItemId == 0 {
+		return fmt.Errorf("%w: item id is zero", ErrInvalidEvent)
+	}
+
+	switch event.Type {
+	case model.EventItemListed:
+		if err := requireAddress("seller", event.Seller); err != nil {
+			return err
+		}
+		return requirePrice("price", event.Price)
+	case model.EventItemPurchased:
+		return requireAddress("buyer", event.Buyer)
+	case model.EventItemCancelled:
+		return requireAddress("seller", event.Seller)
+	case model.EventReceiptConfirmed:
+		if err := requireAddress("buyer", event.Buyer); err != nil {
+			return err
+		}
+		return requireAddress("seller", event.Seller)
+	case model.EventPriceReduced:
+		if err := requirePrice("old price", event.OldPrice); err != nil {
+			return err
+		}
+		return requirePrice("new price", event.Price)
+	}
+	return nil
+}
+
+func requireAddress(field, value string) error {
+	if !common.IsHexAddress(value) || common.HexToAddress(value) == (common.Address{}) {
+		return fmt.Errorf("%w: %s address %q is missing or zero", ErrInvalidEvent, field, value)
+	}
+	return nil
+}
+
+func requirePrice(field string, value *big.Int) error {
+	if value == nil {
+		return fmt.Errorf("%w: %s is missing", ErrInvalidEvent, field)
+	}
+	return nil
+}
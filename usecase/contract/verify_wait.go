@@ -0,0 +1,34 @@
+package usecase
+
+import (
+	"context"
+	"time"
+
+	"uttc-hack-back-onchain/model"
+)
+
+// defaultMaxVerifyWait は VerifyTransactionAndWait が待つ最大時間のデフォルト
+const defaultMaxVerifyWait = 30 * time.Second
+
+// VerifyTransactionAndWait はトランザクションがマイニングされるか wait が経過するまで待ってから検証結果を返す
+// wait は VERIFY_TX_MAX_WAIT で上限を設ける。期限内にマイニングされなかった場合は pending の結果を返し、
+// マイニングされないまま破棄・置き換えられた場合は contract.ErrTxDropped を返す
+func (uc *contractUsecase) VerifyTransactionAndWait(ctx context.Context, txHash string, wait time.Duration) (*model.TxVerification, error) {
+	wait = min(wait, uc.maxVerifyWait)
+	if wait <= 0 {
+		return uc.VerifyTransaction(ctx, txHash)
+	}
+
+	// マイニングされるまで待ち、期限切れの場合はその時点の状態（pending）を返す
+	waitCtx, cancel := context.WithTimeout(ctx, wait)
+	defer cancel()
+	if err := uc.gateway.WaitForReceipt(waitCtx, txHash, 1); err != nil {
+		if ctx.Err() != nil {
+			return nil, ctx.Err()
+		}
+		if waitCtx.Err() == nil {
+			return nil, err
+		}
+	}
+	return uc.VerifyTransaction(ctx, txHash)
+}
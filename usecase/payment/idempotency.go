@@ -0,0 +1,79 @@
+package usecase
+
+import (
+	"sync"
+	"time"
+
+	"uttc-hack-back-onchain/model"
+)
+
+// DefaultIdempotencyTTL は Idempotency-Key で作成済み注文を再利用する期間
+// フロントエンドのネットワーク再送をカバーできれば十分なため短めにしている
+const DefaultIdempotencyTTL = 24 * time.Hour
+
+// IdempotencyStore は Idempotency-Key と作成済み注文の対応を保持するストア
+type IdempotencyStore interface {
+	// Get はキーに対応する注文を返す（存在しない・期限切れの場合は false）
+	Get(key string) (*model.PaymentOrder, bool)
+
+	// SaveIfAbsent はキーに注文を紐付ける。既に有効な注文が紐付いている場合はそれを返し、
+	// 新たに保存した場合は渡した注文を返す（同じキーの同時リクエストで結果を揃えるため）
+	SaveIfAbsent(key string, order *model.PaymentOrder) *model.PaymentOrder
+}
+
+type idempotencyEntry struct {
+	order     model.PaymentOrder
+	expiresAt time.Time
+}
+
+// MemoryIdempotencyStore はメモリ上にキーを保持するデフォルト実装
+// 期限切れのエントリは保存時にまとめて削除する。プロセス再起動で内容は失われる
+type MemoryIdempotencyStore struct {
+	mu      sync.Mutex
+	ttl     time.Duration
+	entries map[string]idempotencyEntry
+}
+
+// NewMemoryIdempotencyStore はメモリ上の冪等性ストアを作成（ttl が0以下なら DefaultIdempotencyTTL）
+func NewMemoryIdempotencyStore(ttl time.Duration) *MemoryIdempotencyStore {
+	if ttl <= 0 {
+		ttl = DefaultIdempotencyTTL
+	}
+	return &MemoryIdempotencyStore{
+		ttl:     ttl,
+		entries: make(map[string]idempotencyEntry),
+	}
+}
+
+func (s *MemoryIdempotencyStore) Get(key string) (*model.PaymentOrder, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	entry, ok := s.entries[key]
+	if !ok || time.Now().After(entry.expiresAt) {
+		return nil, false
+	}
+	order := entry.order
+	return &order, true
+}
+
+func (s *MemoryIdempotencyStore) SaveIfAbsent(key string, order *model.PaymentOrder) *model.PaymentOrder {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	now := time.Now()
+	for k, entry := range s.entries {
+		if now.After(entry.expiresAt) {
+			delete(s.entries, k)
+		}
+	}
+
+	if entry, ok := s.entries[key]; ok {
+		existing := entry.order
+		return &existing
+	}
+
+	// 呼び出し側での変更がストアに影響しないようコピーを保持する
+	s.entries[key] = idempotencyEntry{order: *order, expiresAt: now.Add(s.ttl)}
+	return order
+}
@@ -0,0 +1,137 @@
+package usecase
+
+import (
+	"errors"
+	"math/big"
+	"sort"
+	"strings"
+	"sync"
+
+	"uttc-hack-back-onchain/model"
+)
+
+// ErrOrderNotFound は指定した注文がストアに存在しない
+var ErrOrderNotFound = errors.New("order not found")
+
+// OrderStore は作成した注文を保持するストア
+type OrderStore interface {
+	// Save は注文を保存する（同じOrderIDの場合は上書き）
+	Save(order *model.PaymentOrder) error
+
+	// Get はOrderIDから注文を取得する（存在しない場合は ErrOrderNotFound）
+	Get(orderID string) (*model.PaymentOrder, error)
+
+	// List は条件に一致する注文を作成日時の新しい順に offset 件目から最大 limit 件返し、一致した総件数とともに返す
+	List(filter OrderFilter, offset, limit int) ([]*model.PaymentOrder, int, error)
+
+	// GetCredit は購入者ウォレットの過払いクレジット残高（Wei）を返す（無い場合は0）
+	GetCredit(wallet string) (*big.Int, error)
+
+	// SetCredit は購入者ウォレットの過払いクレジット残高（Wei）を設定する
+	SetCredit(wallet string, amount *big.Int) error
+}
+
+// OrderFilter は注文一覧の絞り込み条件（空のフィールドは条件にしない）
+type OrderFilter struct {
+	Status      model.OrderStatus
+	ProductID   string
+	BuyerWallet string // 大文字小文字を区別しない
+}
+
+// matches は注文が条件に一致するかを返す
+func (f OrderFilter) matches(order *model.PaymentOrder) bool {
+	if f.Status != "" && order.Status != f.Status {
+		return false
+	}
+	if f.ProductID != "" && order.ProductID != f.ProductID {
+		return false
+	}
+	if f.BuyerWallet != "" && !strings.EqualFold(order.BuyerWallet, f.BuyerWallet) {
+		return false
+	}
+	return true
+}
+
+// MemoryOrderStore はメモリ上に注文を保持するデフォルト実装
+// プロセス再起動で内容は失われる
+type MemoryOrderStore struct {
+	mu      sync.RWMutex
+	orders  map[string]model.PaymentOrder
+	credits map[string]*big.Int // キーは小文字化したウォレットアドレス
+}
+
+// NewMemoryOrderStore はメモリ上の注文ストアを作成
+func NewMemoryOrderStore() *MemoryOrderStore {
+	return &MemoryOrderStore{
+		orders:  make(map[string]model.PaymentOrder),
+		credits: make(map[string]*big.Int),
+	}
+}
+
+func (s *MemoryOrderStore) Save(order *model.PaymentOrder) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	// 呼び出し側での変更がストアに影響しないようコピーを保持する
+	s.orders[order.OrderID] = *order
+	return nil
+}
+
+func (s *MemoryOrderStore) Get(orderID string) (*model.PaymentOrder, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	order, ok := s.orders[orderID]
+	if !ok {
+		return nil, ErrOrderNotFound
+	}
+	return &order, nil
+}
+
+func (s *MemoryOrderStore) List(filter OrderFilter, offset, limit int) ([]*model.PaymentOrder, int, error) {
+	s.mu.RLock()
+	matched := make([]*model.PaymentOrder, 0)
+	for _, order := range s.orders {
+		if filter.matches(&order) {
+			matched = append(matched, &order)
+		}
+	}
+	s.mu.RUnlock()
+
+	sort.Slice(matched, func(i, j int) bool {
+		if !matched[i].CreatedAt.Equal(matched[j].CreatedAt) {
+			return matched[i].CreatedAt.After(matched[j].CreatedAt)
+		}
+		return matched[i].OrderID < matched[j].OrderID
+	})
+
+	total := len(matched)
+	if offset >= total {
+		return []*model.PaymentOrder{}, total, nil
+	}
+	return matched[offset:min(offset+limit, total)], total, nil
+}
+
+func (s *MemoryOrderStore) GetCredit(wallet string) (*big.Int, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	credit, ok := s.credits[strings.ToLower(wallet)]
+	if !ok {
+		return new(big.Int), nil
+	}
+	return new(big.Int).Set(credit), nil
+}
+
+func (s *MemoryOrderStore) SetCredit(wallet string, amount *big.Int) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	key := strings.ToLower(wallet)
+	if amount.Sign() == 0 {
+		delete(s.credits, key)
+		return nil
+	}
+	s.credits[key] = new(big.Int).Set(amount)
+	return nil
+}
@@ -2,88 +2,710 @@ package usecase
 
 import (
 	"context"
+	"encoding/hex"
 	"errors"
+	"fmt"
+	"log"
+	"math/big"
+	"strings"
+	"sync"
 	"time"
 
+	"github.com/ethereum/go-ethereum/common"
+
 	"uttc-hack-back-onchain/gateway/payment"
+	"uttc-hack-back-onchain/gateway/signer"
+	"uttc-hack-back-onchain/internal/notifier"
 	"uttc-hack-back-onchain/model"
 )
 
+// selfTestAmountWei はセルフテストで送金する金額 (1 Gwei)
+var selfTestAmountWei = big.NewInt(1e9)
+
+// testnetChainIDs はセルフテストを許可するテストネットのチェーンID
+var testnetChainIDs = map[int64]string{
+	11155111: "Sepolia",
+	17000:    "Holesky",
+	1337:     "Local",
+	31337:    "Local",
+}
+
+var (
+	// ErrSelfTestUnavailable は署名鍵が設定されておらずセルフテストを実行できない
+	ErrSelfTestUnavailable = errors.New("self-test is unavailable: signer is not configured")
+	// ErrNotTestnet はテストネット以外でセルフテストが要求された
+	ErrNotTestnet = errors.New("self-test is only allowed on testnets")
+	// ErrInvalidBuyerWallet は購入者ウォレットアドレスの形式が不正
+	ErrInvalidBuyerWallet = errors.New("buyer_wallet is not a valid Ethereum address")
+	// ErrOrderStoreDisabled は注文ストアが設定されておらず注文を参照できない
+	ErrOrderStoreDisabled = errors.New("order store is not configured")
+	// ErrOrderExpired は有効期限を過ぎた注文の支払い確定が要求された
+	ErrOrderExpired = errors.New("order has expired")
+	// ErrOrderAlreadyPaid は確定済みの注文に別のトランザクションで支払い確定が要求された
+	ErrOrderAlreadyPaid = errors.New("order has already been paid")
+)
+
 // PaymentUsecase は決済処理のビジネスロジックを定義
 type PaymentUsecase interface {
 	// CreatePaymentOrder は支払い情報を初期化し、フロントエンドに返すべき情報を生成する
-	CreatePaymentOrder(ctx context.Context, productID string, buyerWallet string) (*model.PaymentOrder, error)
+	// variant が空の場合は商品本体の価格で見積もる
+	// idempotencyKey が空でなく、TTL内に同じキーで作成済みの注文があればそれを変更せずに返す
+	CreatePaymentOrder(ctx context.Context, productID string, variant string, buyerWallet string, idempotencyKey string) (*model.PaymentOrder, error)
+
+	// GetQuote は注文を作成せずに商品の価格と支払い金額を返す（注文ID・注文ストアへの保存は無し）
+	GetQuote(ctx context.Context, productID string, variant string) (*model.PaymentQuote, error)
 
 	// ConfirmPayment はトランザクションハッシュを受け取り、支払い完了を検証・確定する
-	ConfirmPayment(ctx context.Context, orderID string, productID string, txHash string) (*model.PaymentOrder, error)
+	// variant と buyerWallet は注文ストアに注文が無い場合のフォールバックとして使用する
+	ConfirmPayment(ctx context.Context, orderID string, productID string, variant string, txHash string, buyerWallet string) (*model.PaymentOrder, error)
+
+	// ConfirmPaymentAndWait はトランザクションが MinConfirmations 件の確認を得るか ConfirmWaitTimeout が経過するまで待ってから ConfirmPayment を実行する
+	// ctx がキャンセルされた場合（クライアントの切断など）は待機を中断する
+	ConfirmPaymentAndWait(ctx context.Context, orderID string, productID string, variant string, txHash string, buyerWallet string) (*model.PaymentOrder, error)
+
+	// GetOrder は作成済みの注文を現在のステータスとともに返す（存在しない場合は ErrOrderNotFound）
+	GetOrder(ctx context.Context, orderID string) (*model.PaymentOrder, error)
+
+	// ListOrders は条件に一致する注文を作成日時の新しい順にページングして取得し、総件数とともに返す
+	ListOrders(ctx context.Context, filter OrderFilter, offset, limit int) ([]*model.PaymentOrder, int, error)
+
+	// DryRunConfirmPayment は ConfirmPayment と同じ検証を行い、各チェックの結果をレポートとして返す
+	// 注文の確定・クレジットの更新などの副作用は一切起こさない（QA用）
+	DryRunConfirmPayment(ctx context.Context, orderID string, productID string, variant string, txHash string, buyerWallet string) (*model.PaymentReport, error)
+
+	// GetCollectedBalance は集金用ウォレットの残高を返す（集金額の確認用）
+	GetCollectedBalance(ctx context.Context) (*model.CollectedBalance, error)
+
+	// RunSelfTest はバックエンドの署名鍵から集金アドレスへ少額送金し、検証パイプラインを通しで実行する
+	RunSelfTest(ctx context.Context) (*model.SelfTestResult, error)
 }
 
+// Options は決済ユースケースの任意設定
+type Options struct {
+	// OverpaymentCredit を有効にすると、支払い額が注文金額を超えた分を購入者ウォレットの
+	// クレジットとして注文ストアに記録し、同じ購入者の次回以降の支払い確定時に充当する。
+	// 事前に多めに送金しておくパワーユーザー向けの機能で、デフォルトは無効（超過分は記録しない）。
+	// 注文ストアが必要。充当後も確定に使うトランザクションは正の金額を送金している必要がある。
+	OverpaymentCredit bool
+
+	// Idempotency を設定すると、Idempotency-Key 付きの注文作成リクエストの再送に対して
+	// 最初に作成した注文（CreatedAt も含めて同一）を返す。nil の場合はキーを無視する。
+	Idempotency IdempotencyStore
+
+	// MinConfirmations は ConfirmPaymentAndWait が待つ確認数（0 以下の場合は DefaultMinConfirmations）
+	MinConfirmations uint64
+	// ConfirmWaitTimeout は ConfirmPaymentAndWait の最大待機時間（0 以下の場合は DefaultConfirmWaitTimeout）
+	ConfirmWaitTimeout time.Duration
+
+	// AmountToleranceBps は注文金額に対する不足の許容幅（ベーシスポイント、100 = 1%）
+	// 注文金額 * (1 - AmountToleranceBps/10000) 以上の支払いを有効とする。0（デフォルト）の場合は注文金額以上が必要。
+	// PAYMENT_AMOUNT_TOLERANCE_BPS を設定した場合のみ不足を許容する
+	// 過払いクレジットモードではクレジット残高の計算を合わせるため適用しない
+	AmountToleranceBps uint64
+	// DemoMode を有効にすると、支払い確定でトランザクションハッシュの形式だけを確認し、
+	// チェーン上の検証をせずに PAID にする（実際の送金をしないライブデモ専用）。
+	// テストネット以外では有効にできない。確定した注文には DemoMode が立つ
+	DemoMode bool
+
+	// LargeOverpaymentBps を超えて過払いした支払いは確定したうえで LargeOverpayment を立てる（0 の場合は判定しない）
+	LargeOverpaymentBps uint64
+
+	// OrderTTL は注文作成から支払いを確定できるまでの期間（0 以下の場合は DefaultOrderTTL）
+	// 数日前の注文の金額で支払いを確定されないよう、期限切れの注文の確定は ErrOrderExpired で拒否する
+	OrderTTL time.Duration
+}
+
+const (
+	// DefaultMinConfirmations は同期確定で待つ確認数のデフォルト
+	DefaultMinConfirmations uint64 = 1
+	// DefaultConfirmWaitTimeout は同期確定の最大待機時間のデフォルト
+	DefaultConfirmWaitTimeout = 60 * time.Second
+
+	// DefaultAmountToleranceBps は支払い額の不足の許容幅のデフォルト（0 = 許容しない）
+	// 支払い額は商品ごとに固定の Wei で、注文作成から支払いまでの間にレートで変動しないため、不足はオプトインでのみ許容する
+	DefaultAmountToleranceBps uint64 = 0
+	// DefaultLargeOverpaymentBps は大きな過払いとして記録する超過幅のデフォルト（10%）
+	DefaultLargeOverpaymentBps uint64 = 1000
+	// bpsDenominator はベーシスポイントの分母
+	bpsDenominator = 10000
+
+	// DefaultOrderTTL は注文の有効期限のデフォルト
+	DefaultOrderTTL = 30 * time.Minute
+)
+
 type paymentUsecase struct {
-	bcGateway gateway.BlockchainGateway
+	bcGateway  gateway.BlockchainGateway
+	signer     signer.SignerGateway // nil の場合セルフテストは無効
+	orderStore OrderStore           // nil の場合は注文を保持しない
+	opts       Options
+
+	notifier *notifier.BackendNotifier // nil の場合は支払い確定 Webhook を送信しない
+
+	// クレジット残高の読み書きを直列化する
+	creditMu sync.Mutex
+	// クレジット計上済みのトランザクション（同じTxで二重にクレジットを得るのを防ぐ）
+	creditedTxs map[string]bool
 }
 
-func NewPaymentUsecase(bc gateway.BlockchainGateway) *paymentUsecase {
+// backendNotifier が nil の場合、支払い確定時のバックエンドへの Webhook は送信しない
+func NewPaymentUsecase(bc gateway.BlockchainGateway, sg signer.SignerGateway, store OrderStore, backendNotifier *notifier.BackendNotifier, opts Options) *paymentUsecase {
+	if opts.OverpaymentCredit {
+		if store == nil {
+			log.Println("WARNING: Overpayment credit requires an order store. Disabling.")
+			opts.OverpaymentCredit = false
+		} else {
+			log.Println("Overpayment credit mode enabled")
+		}
+	}
+
+	if opts.DemoMode {
+		if _, ok := testnetChainIDs[int64(bc.ChainID())]; !ok {
+			log.Println("WARNING: Payment demo mode is only allowed on testnets. Disabling.")
+			opts.DemoMode = false
+		} else {
+			log.Println("WARNING: ==== PAYMENT DEMO MODE IS ACTIVE: payments are confirmed WITHOUT on-chain verification ====")
+		}
+	}
+
+	if backendNotifier == nil {
+		log.Println("WARNING: Backend URL not set. Payment confirmed webhook disabled.")
+	}
+
 	return &paymentUsecase{
-		bcGateway: bc,
+		bcGateway:   bc,
+		signer:      sg,
+		orderStore:  store,
+		opts:        opts,
+		notifier:    backendNotifier,
+		creditedTxs: make(map[string]bool),
 	}
 }
 
-func (uc *paymentUsecase) CreatePaymentOrder(ctx context.Context, productID string, buyerWallet string) (*model.PaymentOrder, error) {
+func (uc *paymentUsecase) CreatePaymentOrder(ctx context.Context, productID string, variant string, buyerWallet string, idempotencyKey string) (*model.PaymentOrder, error) {
+	// 同じ Idempotency-Key で作成済みの注文があれば再作成せずに返す
+	useIdempotency := uc.opts.Idempotency != nil && idempotencyKey != ""
+	if useIdempotency {
+		if cached, ok := uc.opts.Idempotency.Get(idempotencyKey); ok {
+			log.Printf("Returning cached order %s for idempotency key", cached.OrderID)
+			return cached, nil
+		}
+	}
+
+	// 0. 購入者ウォレットを検証し、EIP-55チェックサム形式に正規化（デモ用に未指定は許可）
+	if buyerWallet != "" {
+		if !common.IsHexAddress(buyerWallet) {
+			return nil, ErrInvalidBuyerWallet
+		}
+		buyerWallet = model.ChecksumAddress(buyerWallet)
+	}
+
 	// 1. バックエンドから商品価格（円）を取得
-	priceYen, err := uc.bcGateway.GetProductPrice(productID)
+	priceYen, err := uc.bcGateway.GetProductPrice(ctx, productID, variant)
 	if err != nil {
 		return nil, err
 	}
 
-	// 2. 支払い金額を取得 (デモ用: 0.001 ETH固定)
-	amountWei := uc.bcGateway.GetRequiredAmount()
+	// 2. 商品ごとの支払い金額を取得
+	amountWei, err := uc.bcGateway.RequiredAmountFor(productID, variant)
+	if err != nil {
+		return nil, err
+	}
 	paymentAddr := uc.bcGateway.GetPaymentAddress()
 
 	// 3. 注文モデルを作成
+	createdAt := time.Now()
+	expiresAt := createdAt.Add(uc.orderTTL())
 	newOrder := &model.PaymentOrder{
 		OrderID:     "ORDER-" + productID + "-" + time.Now().Format("20060102150405"),
 		ProductID:   productID,
+		Variant:     variant,
 		PriceYen:    priceYen,
-		AmountETH:   "0.001",
+		AmountETH:   model.WeiToEthString(amountWei),
 		AmountWei:   amountWei.String(),
 		PaymentAddr: paymentAddr,
+		PaymentURI:  model.EthPaymentURI(paymentAddr, amountWei.String()),
 		BuyerWallet: buyerWallet,
 		Status:      model.StatusPending,
-		CreatedAt:   time.Now(),
+		CreatedAt:   createdAt,
+		ExpiresAt:   &expiresAt,
+		// UIの案内用に、実際の接続先と確認数の設定値を含める
+		Network:               model.NetworkName(uc.bcGateway.ChainID()),
+		RequiredConfirmations: int(uc.minConfirmations()),
+	}
+
+	// 4. 注文ストアがあれば保存（支払い確定時に購入者ウォレットを参照するため）
+	if uc.orderStore != nil {
+		if err := uc.orderStore.Save(newOrder); err != nil {
+			log.Printf("WARNING: Failed to save order %s: %v", newOrder.OrderID, err)
+		}
+	}
+
+	// 5. Idempotency-Key に紐付ける（同時リクエストで先に保存された注文があればそちらを返す）
+	if useIdempotency {
+		return uc.opts.Idempotency.SaveIfAbsent(idempotencyKey, newOrder), nil
 	}
 
 	return newOrder, nil
 }
 
-func (uc *paymentUsecase) ConfirmPayment(ctx context.Context, orderID string, productID string, txHash string) (*model.PaymentOrder, error) {
-	// 1. 商品価格を再取得（商品が存在するか確認）
-	priceYen, err := uc.bcGateway.GetProductPrice(productID)
+// GetQuote は CreatePaymentOrder と同じ価格・支払い金額を返す
+// 購入前の価格表示で放棄されたカートの注文が溜まらないよう、注文は作成しない
+func (uc *paymentUsecase) GetQuote(ctx context.Context, productID string, variant string) (*model.PaymentQuote, error) {
+	priceYen, err := uc.bcGateway.GetProductPrice(ctx, productID, variant)
 	if err != nil {
-		return nil, errors.New("failed to get product: " + err.Error())
+		return nil, err
 	}
 
-	// 2. 支払い金額を取得 (デモ用: 0.001 ETH固定)
-	expectedAmount := uc.bcGateway.GetRequiredAmount()
-	paymentAddr := uc.bcGateway.GetPaymentAddress()
+	amountWei, err := uc.bcGateway.RequiredAmountFor(productID, variant)
+	if err != nil {
+		return nil, err
+	}
 
-	// 3. 注文情報を構築
-	order := &model.PaymentOrder{
-		OrderID:     orderID,
+	return &model.PaymentQuote{
 		ProductID:   productID,
+		Variant:     variant,
 		PriceYen:    priceYen,
-		AmountETH:   "0.001",
-		AmountWei:   expectedAmount.String(),
-		PaymentAddr: paymentAddr,
-		TxHash:      txHash,
+		AmountETH:   model.WeiToEthString(amountWei),
+		AmountWei:   amountWei.String(),
+		PaymentAddr: uc.bcGateway.GetPaymentAddress(),
+		Network:     model.NetworkName(uc.bcGateway.ChainID()),
+	}, nil
+}
+
+func (uc *paymentUsecase) ConfirmPayment(ctx context.Context, orderID string, productID string, variant string, txHash string, buyerWallet string) (*model.PaymentOrder, error) {
+	// 注文作成時に記録した情報があればリクエストの値より優先する
+	stored := uc.loadStoredOrder(orderID)
+	if stored != nil {
+		// 確定済みの注文に同じトランザクションが再送された場合は、再検証・再通知せずにそのまま返す
+		// 別のトランザクションでの再確定は、上書き・二重通知になるため拒否する
+		if stored.Status == model.StatusPaid {
+			if stored.TxHash == txHash {
+				return stored, nil
+			}
+			log.Printf("Rejected confirmation of paid order %s with another tx %s (paid with %s)", orderID, txHash, stored.TxHash)
+			return nil, ErrOrderAlreadyPaid
+		}
+		if isExpired(stored, time.Now()) {
+			log.Printf("Rejected confirmation of expired order %s (expired at %s)", orderID, stored.ExpiresAt.Format(time.RFC3339))
+			return nil, ErrOrderExpired
+		}
+		productID = storedProductID(stored, productID)
+		// 支払い額はバリエーションごとに異なるため、作成時にバリエーションが無かった注文も作成時の値を使う
+		variant = stored.Variant
+		if stored.BuyerWallet != "" {
+			buyerWallet = stored.BuyerWallet
+		}
+	}
+
+	// 1. 商品価格を再取得（商品が存在するか確認）
+	priceYen, err := uc.bcGateway.GetProductPrice(ctx, productID, variant)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get product: %w", err)
+	}
+
+	// 2. 注文作成時と同じ商品ごとの支払い金額を取得
+	expectedAmount, err := uc.bcGateway.RequiredAmountFor(productID, variant)
+	if err != nil {
+		return nil, errors.New("failed to get required amount: " + err.Error())
+	}
+	paymentAddr := uc.bcGateway.GetPaymentAddress()
+
+	// 3. 注文情報を構築（保存済みの注文があればそれを更新する）
+	order := &model.PaymentOrder{OrderID: orderID, CreatedAt: time.Now()}
+	if stored != nil {
+		order = stored
+	}
+	order.ProductID = productID
+	order.Variant = variant
+	order.PriceYen = priceYen
+	order.AmountETH = model.WeiToEthString(expectedAmount)
+	order.AmountWei = expectedAmount.String()
+	order.PaymentAddr = paymentAddr
+	order.BuyerWallet = buyerWallet
+	order.TxHash = txHash
+	// 保存・通知する前に、リクエストで指定されたアドレスの表記をそろえる
+	order.NormalizeAddresses()
+
+	// デモモードではチェーン上の検証をせずに確定する
+	if uc.opts.DemoMode {
+		if !isWellFormedTxHash(txHash) {
+			return nil, fmt.Errorf("payment verification failed: %w", gateway.ErrInvalidTxHash)
+		}
+		log.Printf("WARNING: DEMO MODE: confirming order %s without on-chain verification (tx %s)", orderID, txHash)
+		order.Status = model.StatusPaid
+		order.DemoMode = true
+		uc.saveOrder(order)
+		uc.notifyPaymentConfirmed(ctx, order)
+		return order, nil
+	}
+
+	// 過払いクレジットモードでは購入者のクレジットを充当して確定する
+	if uc.opts.OverpaymentCredit && buyerWallet != "" {
+		order, err := uc.confirmWithCredit(ctx, order, expectedAmount)
+		if err != nil {
+			return nil, err
+		}
+		uc.saveOrder(order)
+		uc.notifyPaymentConfirmed(ctx, order)
+		return order, nil
+	}
+
+	// 4. ブロックチェーン上でトランザクションを検証（購入者ウォレットが無い注文は送信者を検証しない）
+	check, err := uc.bcGateway.CheckPaymentStatus(ctx, txHash, paymentAddr, buyerWallet, uc.minAcceptedAmount(expectedAmount))
+	if err != nil {
+		// 検証失敗のステータスも記録するが、確定済みの注文を別のトランザクションの失敗で上書きしない
+		if check != nil && stored != nil && stored.Status != model.StatusPaid {
+			order.Status = check.Status
+			uc.saveOrder(order)
+		}
+		return nil, fmt.Errorf("payment verification failed: %w", err)
+	}
+
+	order.Status = check.Status
+	order.PaidAsset = check.Asset
+	uc.recordPaidAmount(order, check.PaidWei, expectedAmount)
+	uc.saveOrder(order)
+	uc.notifyPaymentConfirmed(ctx, order)
+	return order, nil
+}
+
+func (uc *paymentUsecase) ConfirmPaymentAndWait(ctx context.Context, orderID string, productID string, variant string, txHash string, buyerWallet string) (*model.PaymentOrder, error) {
+	// デモモードのトランザクションはチェーン上に無いため待たない
+	if uc.opts.DemoMode {
+		return uc.ConfirmPayment(ctx, orderID, productID, variant, txHash, buyerWallet)
+	}
+
+	minConfirmations := uc.minConfirmations()
+	timeout := uc.confirmWaitTimeout()
+
+	// 確認数が揃うまでレシートをポーリングする。タイムアウトした場合はその時点の状態で確定を試み、
+	// まだ未マイニングなら ConfirmPayment が ErrTxPending を返す
+	waitCtx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+	if err := uc.bcGateway.WaitForReceipt(waitCtx, txHash, minConfirmations); err != nil {
+		switch {
+		case ctx.Err() != nil:
+			return nil, ctx.Err()
+		case waitCtx.Err() != nil:
+			log.Printf("Confirmation wait timed out: tx=%s required=%d", txHash, minConfirmations)
+		case errors.Is(err, gateway.ErrTxDropped), errors.Is(err, gateway.ErrInvalidTxHash):
+			return nil, err
+		default:
+			return nil, fmt.Errorf("payment verification failed: %w", err)
+		}
+	}
+
+	return uc.ConfirmPayment(ctx, orderID, productID, variant, txHash, buyerWallet)
+}
+
+// minAcceptedAmount は許容幅を差し引いた、支払いとして受け付ける最低額を返す（最低1 Wei）
+func (uc *paymentUsecase) minAcceptedAmount(expected *big.Int) *big.Int {
+	// 0以下の支払い額（設定ミス）は最低1 Weiに補わず、そのまま CheckPaymentStatus に拒否させる
+	if uc.opts.AmountToleranceBps == 0 || expected.Sign() <= 0 {
+		return expected
+	}
+	accepted := new(big.Int).Mul(expected, new(big.Int).SetUint64(bpsDenominator-min(uc.opts.AmountToleranceBps, bpsDenominator)))
+	accepted.Div(accepted, big.NewInt(bpsDenominator))
+	if accepted.Sign() <= 0 {
+		accepted.SetInt64(1)
+	}
+	return accepted
+}
+
+// recordPaidAmount は実際の支払い額を注文に記録し、許容幅内の不足や大きな過払いをログに残す
+func (uc *paymentUsecase) recordPaidAmount(order *model.PaymentOrder, paid *big.Int, expected *big.Int) {
+	if paid == nil {
+		return
+	}
+	order.PaidWei = paid.String()
+
+	if paid.Cmp(expected) < 0 {
+		log.Printf("Payment accepted within tolerance: order=%s paid=%s expected=%s Wei", order.OrderID, paid.String(), expected.String())
+		return
+	}
+	if uc.opts.LargeOverpaymentBps == 0 {
+		return
+	}
+	limit := new(big.Int).Mul(expected, new(big.Int).SetUint64(bpsDenominator+uc.opts.LargeOverpaymentBps))
+	limit.Div(limit, big.NewInt(bpsDenominator))
+	if paid.Cmp(limit) > 0 {
+		order.LargeOverpayment = true
+		log.Printf("WARNING: Large overpayment: order=%s paid=%s expected=%s Wei", order.OrderID, paid.String(), expected.String())
+	}
+}
+
+// orderTTL は注文の有効期限を返す（未設定の場合は DefaultOrderTTL）
+func (uc *paymentUsecase) orderTTL() time.Duration {
+	if uc.opts.OrderTTL <= 0 {
+		return DefaultOrderTTL
+	}
+	return uc.opts.OrderTTL
+}
+
+// isExpired は注文の有効期限が now より前かを返す（期限の無い注文は期限切れにならない）
+func isExpired(order *model.PaymentOrder, now time.Time) bool {
+	return order.ExpiresAt != nil && now.After(*order.ExpiresAt)
+}
+
+// minConfirmations は支払い確定で待つ確認数を返す（未設定の場合は DefaultMinConfirmations）
+func (uc *paymentUsecase) minConfirmations() uint64 {
+	if uc.opts.MinConfirmations == 0 {
+		return DefaultMinConfirmations
+	}
+	return uc.opts.MinConfirmations
+}
+
+// confirmWaitTimeout はマイニング待ちの最大時間を返す（未設定の場合は DefaultConfirmWaitTimeout）
+func (uc *paymentUsecase) confirmWaitTimeout() time.Duration {
+	if uc.opts.ConfirmWaitTimeout <= 0 {
+		return DefaultConfirmWaitTimeout
+	}
+	return uc.opts.ConfirmWaitTimeout
+}
+
+func (uc *paymentUsecase) GetOrder(ctx context.Context, orderID string) (*model.PaymentOrder, error) {
+	if uc.orderStore == nil {
+		return nil, ErrOrderStoreDisabled
+	}
+	return uc.orderStore.Get(orderID)
+}
+
+func (uc *paymentUsecase) ListOrders(ctx context.Context, filter OrderFilter, offset, limit int) ([]*model.PaymentOrder, int, error) {
+	if uc.orderStore == nil {
+		return nil, 0, ErrOrderStoreDisabled
+	}
+	if filter.BuyerWallet != "" && !common.IsHexAddress(filter.BuyerWallet) {
+		return nil, 0, ErrInvalidBuyerWallet
+	}
+	return uc.orderStore.List(filter, offset, limit)
+}
+
+func (uc *paymentUsecase) GetCollectedBalance(ctx context.Context) (*model.CollectedBalance, error) {
+	balance, err := uc.bcGateway.GetCollectedBalance(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return &model.CollectedBalance{
+		Address:    uc.bcGateway.GetPaymentAddress(),
+		BalanceWei: balance.String(),
+		BalanceEth: model.WeiToEthString(balance),
+		Network:    model.NetworkName(uc.bcGateway.ChainID()),
+	}, nil
+}
+
+// isWellFormedTxHash は 0x 始まりの32バイトの16進数かどうかを返す
+func isWellFormedTxHash(txHash string) bool {
+	hexPart, ok := strings.CutPrefix(txHash, "0x")
+	if !ok || len(hexPart) != 64 {
+		return false
+	}
+	_, err := hex.DecodeString(hexPart)
+	return err == nil
+}
+
+// saveOrder は確定処理後の注文を注文ストアに保存する（ストアが無い場合は何もしない）
+// 支払いの検証結果は返したいため、保存の失敗はログのみ
+func (uc *paymentUsecase) saveOrder(order *model.PaymentOrder) {
+	if uc.orderStore == nil {
+		return
+	}
+	if err := uc.orderStore.Save(order); err != nil {
+		log.Printf("WARNING: Failed to save order %s: %v", order.OrderID, err)
+	}
+}
+
+// notifyPaymentConfirmed は支払いが確定した注文をメインバックエンドに通知する
+// 支払い自体は検証済みのため、通知に失敗しても確定結果は返す（ログのみ）。
+// リクエストのキャンセルで通知が中断されないよう ctx のキャンセルは引き継がない
+func (uc *paymentUsecase) notifyPaymentConfirmed(ctx context.Context, order *model.PaymentOrder) {
+	if uc.notifier == nil || order.Status != model.StatusPaid {
+		return
 	}
 
-	// 4. ブロックチェーン上でトランザクションを検証
-	newStatus, err := uc.bcGateway.CheckPaymentStatus(ctx, txHash, paymentAddr, expectedAmount)
+	payload := map[string]interface{}{
+		"order_id":     order.OrderID,
+		"product_id":   order.ProductID,
+		"tx_hash":      order.TxHash,
+		"amount_wei":   order.AmountWei,
+		"buyer_wallet": order.BuyerWallet,
+	}
+	if order.DemoMode {
+		payload["demo_mode"] = true
+	}
+	if order.PaidAsset != "" {
+		payload["paid_asset"] = order.PaidAsset
+	}
+	if err := uc.notifier.Post(context.WithoutCancel(ctx), "/api/v1/payment/confirmed", payload); err != nil {
+		log.Printf("ERROR: Failed to notify payment confirmed (order %s): %v", order.OrderID, err)
+		return
+	}
+	log.Printf("Backend notified: payment confirmed (order %s)", order.OrderID)
+}
+
+func (uc *paymentUsecase) DryRunConfirmPayment(ctx context.Context, orderID string, productID string, variant string, txHash string, buyerWallet string) (*model.PaymentReport, error) {
+	// ConfirmPayment と同じく注文作成時の情報を優先する（ストアは読み取りのみ）
+	if stored := uc.loadStoredOrder(orderID); stored != nil {
+		productID = storedProductID(stored, productID)
+		variant = stored.Variant
+		if stored.BuyerWallet != "" {
+			buyerWallet = stored.BuyerWallet
+		}
+	}
+
+	if _, err := uc.bcGateway.GetProductPrice(ctx, productID, variant); err != nil {
+		return nil, fmt.Errorf("failed to get product: %w", err)
+	}
+
+	expectedAmount, err := uc.bcGateway.RequiredAmountFor(productID, variant)
 	if err != nil {
-		order.Status = model.StatusError
-		return nil, errors.New("payment verification failed: " + err.Error())
+		return nil, errors.New("failed to get required amount: " + err.Error())
 	}
+	paymentAddr := uc.bcGateway.GetPaymentAddress()
 
-	order.Status = newStatus
+	// 過払いクレジットモードでは、実際の確定時と同じくクレジット充当後の金額で検証する
+	if uc.opts.OverpaymentCredit && buyerWallet != "" {
+		credit, err := uc.orderStore.GetCredit(buyerWallet)
+		if err != nil {
+			return nil, errors.New("failed to load credit: " + err.Error())
+		}
+		if credit.Cmp(expectedAmount) >= 0 {
+			expectedAmount = big.NewInt(1)
+		} else {
+			expectedAmount = new(big.Int).Sub(expectedAmount, credit)
+		}
+	}
+
+	// 過払いクレジットモード以外では、実際の確定時と同じく許容幅を差し引いた金額で検証する
+	if !uc.opts.OverpaymentCredit || buyerWallet == "" {
+		expectedAmount = uc.minAcceptedAmount(expectedAmount)
+	}
+
+	report, err := uc.bcGateway.InspectPayment(ctx, txHash, paymentAddr, buyerWallet, expectedAmount)
+	if err != nil {
+		return nil, fmt.Errorf("payment inspection failed: %w", err)
+	}
+	report.OrderID = orderID
+
+	log.Printf("Dry-run payment confirmation: order=%s tx=%s would_be=%s", orderID, txHash, report.WouldBeStatus)
+	return report, nil
+}
+
+// storedProductID は注文作成時の商品IDを返す（保存済みの注文に無い場合はリクエストの値）
+// 高い商品の注文を安い商品の product_id と支払い額で確定されないよう、リクエストの値より優先する
+func storedProductID(stored *model.PaymentOrder, requested string) string {
+	if stored.ProductID == "" {
+		return requested
+	}
+	if requested != "" && requested != stored.ProductID {
+		log.Printf("WARNING: product_id %q in confirmation differs from order %s (%q), using the order's product", requested, stored.OrderID, stored.ProductID)
+	}
+	return stored.ProductID
+}
+
+// confirmWithCredit はクレジット残高を充当して支払いを検証し、超過分を新たなクレジットとして記録する
+// 確定後の残高 = 既存クレジット + 今回の支払い額 - 注文金額
+func (uc *paymentUsecase) confirmWithCredit(ctx context.Context, order *model.PaymentOrder, expectedAmount *big.Int) (*model.PaymentOrder, error) {
+	uc.creditMu.Lock()
+	defer uc.creditMu.Unlock()
+
+	if uc.creditedTxs[order.TxHash] {
+		return nil, errors.New("payment verification failed: transaction already used for credit")
+	}
+
+	credit, err := uc.orderStore.GetCredit(order.BuyerWallet)
+	if err != nil {
+		return nil, errors.New("failed to load credit: " + err.Error())
+	}
+
+	// 1. クレジットを差し引いた必要額（トランザクション自体は最低1 Wei送金している必要がある）
+	applied := new(big.Int).Set(credit)
+	if applied.Cmp(expectedAmount) > 0 {
+		applied.Set(expectedAmount)
+	}
+	required := new(big.Int).Sub(expectedAmount, applied)
+	// 支払い額自体が0以下（設定ミス）の場合は 1 Wei に補わず、CheckPaymentStatus に拒否させる
+	if required.Sign() == 0 && expectedAmount.Sign() > 0 {
+		required.SetInt64(1)
+	}
+
+	// 2. ブロックチェーン上でトランザクションを検証
+	check, err := uc.bcGateway.CheckPaymentStatus(ctx, order.TxHash, order.PaymentAddr, order.BuyerWallet, required)
+	if err != nil {
+		return nil, fmt.Errorf("payment verification failed: %w", err)
+	}
+
+	// 3. 残高を更新
+	balance := new(big.Int).Add(credit, check.PaidWei)
+	balance.Sub(balance, expectedAmount)
+	if err := uc.orderStore.SetCredit(order.BuyerWallet, balance); err != nil {
+		return nil, errors.New("failed to save credit: " + err.Error())
+	}
+	uc.creditedTxs[order.TxHash] = true
+
+	log.Printf("Credit updated for %s: %s -> %s Wei (order %s)", order.BuyerWallet, credit.String(), balance.String(), order.OrderID)
+	order.Status = check.Status
+	order.PaidAsset = check.Asset
+	order.CreditAppliedWei = applied.String()
+	order.CreditBalanceWei = balance.String()
 	return order, nil
 }
+
+// loadStoredOrder は注文作成時に保存した注文を返す
+// 注文ストアが無い、または注文が見つからない場合は nil を返す
+func (uc *paymentUsecase) loadStoredOrder(orderID string) *model.PaymentOrder {
+	if uc.orderStore == nil {
+		return nil
+	}
+
+	stored, err := uc.orderStore.Get(orderID)
+	if err != nil {
+		if !errors.Is(err, ErrOrderNotFound) {
+			log.Printf("WARNING: Failed to load order %s: %v", orderID, err)
+		}
+		return nil
+	}
+	return stored
+}
+
+func (uc *paymentUsecase) RunSelfTest(ctx context.Context) (*model.SelfTestResult, error) {
+	if uc.signer == nil {
+		return nil, ErrSelfTestUnavailable
+	}
+
+	// 1. テストネットであることを確認（メインネットで実資金を動かさない）
+	chainID := uc.signer.ChainID()
+	network, ok := testnetChainIDs[chainID.Int64()]
+	if !ok {
+		return nil, ErrNotTestnet
+	}
+
+	paymentAddr := uc.bcGateway.GetPaymentAddress()
+	result := &model.SelfTestResult{
+		From:      uc.signer.Address(),
+		To:        paymentAddr,
+		AmountWei: selfTestAmountWei.String(),
+		ChainID:   chainID.String(),
+		Status:    model.StatusError,
+	}
+	log.Printf("Running payment self-test on %s: %s -> %s", network, result.From, result.To)
+
+	// 2. 集金アドレスへ少額送金し、ブロックに取り込まれるまで待つ（ConfirmWaitTimeout で打ち切る）
+	sendCtx, cancel := context.WithTimeout(ctx, uc.confirmWaitTimeout())
+	defer cancel()
+	txHash, err := uc.signer.SendValue(sendCtx, paymentAddr, selfTestAmountWei)
+	result.TxHash = txHash
+	if err != nil {
+		result.Error = "send failed: " + err.Error()
+		return result, nil
+	}
+
+	// 3. 通常の支払い確定と同じ検証を実行
+	check, err := uc.bcGateway.CheckPaymentStatus(ctx, txHash, paymentAddr, result.From, selfTestAmountWei)
+	result.Status = check.Status
+	if err != nil {
+		result.Error = "verification failed: " + err.Error()
+	}
+
+	log.Printf("Payment self-test finished: tx=%s status=%s", txHash, result.Status)
+	return result, nil
+}